@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ephemeralfiles/eph/pkg/batch"
+	"github.com/ephemeralfiles/eph/pkg/cmdutil"
+	"github.com/ephemeralfiles/eph/pkg/dto"
+	"github.com/ephemeralfiles/eph/pkg/ephcli"
+	"github.com/ephemeralfiles/eph/pkg/units"
+	"github.com/spf13/cobra"
+)
+
+var (
+	batchDryRun      bool
+	batchConcurrency int
+	batchResults     string
+)
+
+// batchCmd represents the batch command.
+var batchCmd = &cobra.Command{
+	Use:   "batch",
+	Short: "upload files declared in a manifest",
+}
+
+// batchApplyCmd represents the batch apply command.
+var batchApplyCmd = &cobra.Command{
+	Use:   "apply MANIFEST",
+	Short: "upload the files declared in a YAML or JSON manifest",
+	Long: `upload the files declared in a YAML or JSON manifest.
+
+The manifest declares the files (paths or glob patterns, relative to the
+manifest) with their organization, tags, expiration and encryption mode:
+
+  organization: eph1
+  tags: [release]
+  expires: 30d
+  concurrency: 4
+  files:
+    - path: dist/*.tar.gz
+      tags: [archive]
+    - path: dist/checksums.txt
+      encryption: clear
+      expires: 7d
+
+Files are uploaded in parallel (--concurrency, 4 by default). The file ID and
+checksum of each file are written to a results file, MANIFEST.results.json by
+default. Use --dry-run to list the uploads without running them.
+`,
+	Example: `  eph batch apply manifest.yml --dry-run
+  eph batch apply manifest.yml --results release.json`,
+	Args: cobra.ExactArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		manifestPath := args[0]
+		manifest, err := batch.Load(manifestPath)
+		if err != nil {
+			cmdutil.HandleError("Error loading manifest", err)
+		}
+		items, err := batch.Plan(manifest, filepath.Dir(manifestPath), time.Now())
+		if err != nil {
+			cmdutil.HandleError("Error reading manifest", err)
+		}
+
+		InitClient()
+		configureUploadOptions()
+		resolveBatchOrganizations(items)
+
+		if batchDryRun {
+			printBatchPlan(items)
+			return
+		}
+
+		concurrency := manifest.Concurrency
+		if batchConcurrency > 0 {
+			concurrency = batchConcurrency
+		}
+		results := batch.Run(items, concurrency, batchUpload, func(r batch.Result) {
+			if r.Err != nil {
+				fmt.Fprintf(os.Stderr, "Error uploading %s: %s\n", r.Source, r.Err)
+				return
+			}
+			fmt.Printf("%s -> %s\n", r.Source, r.FileID)
+		})
+
+		resultsPath := batchResults
+		if resultsPath == "" {
+			resultsPath = strings.TrimSuffix(manifestPath, filepath.Ext(manifestPath)) + ".results.json"
+		}
+		if err := batch.WriteResults(resultsPath, results); err != nil {
+			cmdutil.HandleError("Error writing results", err)
+		}
+
+		failed := batch.Failed(results)
+		fmt.Printf("%d file(s) uploaded, %d failed, results written to %s\n",
+			len(results)-failed, failed, resultsPath)
+		if failed > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+// resolveBatchOrganizations sets the organization ID of items, resolving each
+// organization of the manifest once.
+func resolveBatchOrganizations(items []batch.Item) {
+	orgCtx := ephcli.NewOrgContext(c, cfg)
+	ids := make(map[string]string)
+	for _, org := range batch.Organizations(items) {
+		id, err := orgCtx.ResolveOrganizationID(org)
+		if err != nil {
+			cmdutil.HandleError("Error resolving organization", err)
+		}
+		ids[org] = id
+	}
+	for i := range items {
+		items[i].OrganizationID = ids[items[i].Organization]
+	}
+}
+
+// batchUpload uploads an item with its own copy of the client, so that uploads
+// can run in parallel. Progress bars are disabled: results are printed instead.
+func batchUpload(item batch.Item) (*ephcli.UploadResult, error) {
+	client := c.Clone()
+	client.DisableProgressBar()
+	opts := ephcli.UploadOptions{
+		OrganizationID: item.OrganizationID,
+		Tags:           item.Tags,
+		ExpiresAt:      item.ExpiresAt,
+		Name:           item.Name,
+	}
+	upload := client.UploadE2EWithOptions
+	if item.Encryption == dto.EncryptionModeClear {
+		upload = client.UploadWithOptions
+	}
+	return upload(item.Path, opts)
+}
+
+// printBatchPlan prints the uploads a batch would run.
+func printBatchPlan(items []batch.Item) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0) //nolint:mnd // column padding
+	fmt.Fprintln(w, "SOURCE\tNAME\tSIZE\tORGANIZATION\tTAGS\tEXPIRES\tENCRYPTION")
+	var total int64
+	for _, item := range items {
+		expires := "default"
+		if !item.ExpiresAt.IsZero() {
+			expires = item.ExpiresAt.Local().Format(time.DateTime)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", item.Source, item.Name, units.FormatSize(item.Size),
+			firstNonEmpty(item.Organization, "-"), firstNonEmpty(strings.Join(item.Tags, ","), "-"),
+			expires, item.Encryption)
+		total += item.Size
+	}
+	_ = w.Flush()
+	fmt.Printf("%d file(s), %s (dry run, nothing uploaded)\n", len(items), units.FormatSize(total))
+}
+
+func init() {
+	batchApplyCmd.Flags().BoolVar(&batchDryRun, "dry-run", false, "list the uploads without running them")
+	batchApplyCmd.Flags().IntVar(&batchConcurrency, "concurrency", 0,
+		"number of parallel uploads (overrides the manifest)")
+	batchApplyCmd.Flags().StringVar(&batchResults, "results", "",
+		"results file, JSON or YAML by extension (default: MANIFEST.results.json)")
+	batchCmd.AddCommand(batchApplyCmd)
+}
@@ -1,7 +1,11 @@
 package cmd
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/ephemeralfiles/eph/pkg/cmdutil"
+	"github.com/ephemeralfiles/eph/pkg/ephcli"
 	"github.com/spf13/cobra"
 )
 
@@ -13,20 +17,70 @@ var downloadCmd = &cobra.Command{
 
 By default, files are downloaded with end-to-end encryption.
 Use --clear to download without encryption.
+
+The name sent by the server is sanitized and the file is written to the
+current directory, or to --output-dir. When the file already exists, it is
+overwritten unless --skip or --rename is set.
+
+With --resume, an interrupted download keeps its partial file and the next
+run with --resume only fetches the missing parts.
+
+With --last, the most recent upload recorded in the history (see eph history)
+is downloaded, in the transfer mode it was uploaded with.
+
+Without --input on a terminal, the file is picked from a list of your files
+with fuzzy search.
 `,
 	Run: func(cmd *cobra.Command, _ []string) {
 		InitClient()
-		cmdutil.ValidateRequired(uuidFile, "uuid", cmd)
+		if !downloadLast && uuidFile == "" && cmdutil.IsInteractive() {
+			uuidFile = pickFiles("Select the file to download", false)[0].FileID
+		}
+		if !downloadLast {
+			cmdutil.ValidateRequired(uuidFile, "uuid", cmd)
+		}
+		configureDownloadOptions()
 
 		// Use encrypted download by default, unless --clear flag is set
 		var err error
-		if clearTransfer {
+		switch {
+		case downloadLast:
+			err = downloadLastUpload()
+		case clearTransfer:
 			err = c.Download(uuidFile, outputFile)
-		} else {
+		default:
 			err = c.DownloadE2E(uuidFile, outputFile)
 		}
+		if errors.Is(err, ephcli.ErrDownloadSkipped) {
+			fmt.Println(err)
+			return
+		}
 		if err != nil {
 			cmdutil.HandleError("Error downloading file", err)
 		}
 	},
 }
+
+// addDownloadOptionFlags registers the output directory, existing file and resume flags.
+func addDownloadOptionFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&outputDir, "output-dir", "", "directory where downloaded files are written")
+	cmd.Flags().BoolVar(&overwriteExisting, "overwrite", false, "overwrite the file if it exists (default)")
+	cmd.Flags().BoolVar(&skipExisting, "skip", false, "skip the download if the file exists")
+	cmd.Flags().BoolVar(&renameExisting, "rename", false, "download to a new name if the file exists")
+	cmd.MarkFlagsMutuallyExclusive("overwrite", "skip", "rename")
+	cmd.Flags().BoolVar(&resumeDownload, "resume", false, "keep partial downloads and continue them on the next run")
+}
+
+// configureDownloadOptions applies the output directory, existing file and resume flags to the client.
+func configureDownloadOptions() {
+	c.SetOutputDir(outputDir)
+	c.SetResume(resumeDownload)
+	switch {
+	case skipExisting:
+		c.SetExistPolicy(ephcli.ExistSkip)
+	case renameExisting:
+		c.SetExistPolicy(ephcli.ExistRename)
+	default:
+		c.SetExistPolicy(ephcli.ExistOverwrite)
+	}
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ephemeralfiles/eph/pkg/cmdutil"
+	"github.com/ephemeralfiles/eph/pkg/ephcli"
+	"github.com/ephemeralfiles/eph/pkg/units"
+	"github.com/spf13/cobra"
+)
+
+// expireCmd represents the expire command.
+var expireCmd = &cobra.Command{
+	Use:   "expire",
+	Short: "change the expiration of a file",
+	Long: `change the expiration of a file, to extend or shorten its retention.
+The uuid is required, with either --in (a duration from now, e.g. 24h or 7d)
+or --at (a date, e.g. 2026-12-31, or an RFC 3339 timestamp).
+`,
+	Example: `  eph expire -i FILE_ID --in 24h
+  eph expire -i FILE_ID --at 2026-12-31`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		if uuidFile == "" || (expireIn == "" && expireAt == "") {
+			fmt.Fprintf(os.Stderr, "uuid and --in or --at are required\n")
+			_ = cmd.Usage()
+			os.Exit(1)
+		}
+		expiresAt, err := expireDate(time.Now())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			os.Exit(1)
+		}
+
+		InitClient()
+		file, err := c.UpdateFileExpiration(uuidFile, expiresAt)
+		if err != nil {
+			cmdutil.HandleError("Error changing expiration", err)
+		}
+		fmt.Printf("%s (%s) expires on %s\n", file.FileID, file.FileName,
+			file.ExpirationDate.Local().Format(time.DateTime))
+	},
+}
+
+// expireDate returns the expiration requested by the --in or --at flag.
+func expireDate(now time.Time) (time.Time, error) {
+	if expireIn != "" {
+		d, err := units.ParseDuration(expireIn)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid --in: %w", err)
+		}
+		if d <= 0 {
+			return time.Time{}, ephcli.ErrExpirationInPast
+		}
+		return now.Add(d), nil
+	}
+	return ephcli.ParseExpiration(expireAt, now)
+}
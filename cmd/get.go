@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ephemeralfiles/eph/pkg/cmdutil"
+	"github.com/ephemeralfiles/eph/pkg/ephcli"
+	"github.com/spf13/cobra"
+)
+
+var (
+	getOutputFile string
+	getPassword   string
+)
+
+// getCmd represents the get command.
+var getCmd = &cobra.Command{
+	Use:   "get SHARE_URL",
+	Short: "download a shared link",
+	Long: `download a file shared with 'eph share'. No account is needed.
+
+The file is downloaded with end-to-end encryption. When the link is protected
+by a password, it is prompted for unless --password is set.
+The output flags are the same as for 'eph dl'.
+`,
+	Example: `  eph get https://api.ephemeralfiles.com/s/6b0f607456254f73bcc18ee653dab19c
+  eph get SHARE_URL -o report.pdf --password secret`,
+	Args: cobra.ExactArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		endpoint, token, err := ephcli.ParseShareURL(args[0])
+		if err != nil {
+			cmdutil.HandleError("Error", err)
+		}
+		InitAnonymousClient(endpoint)
+		configureDownloadOptions()
+
+		sharedFile, err := c.GetSharedFile(token)
+		if err != nil {
+			cmdutil.HandleError("Error reading shared link", err)
+		}
+		password := getPassword
+		if sharedFile.PasswordRequired && password == "" {
+			password, err = cmdutil.ReadPassword("Link password: ")
+			if err != nil {
+				cmdutil.HandleError("Error", err)
+			}
+		}
+
+		err = c.DownloadShared(token, sharedFile, password, getOutputFile)
+		if errors.Is(err, ephcli.ErrDownloadSkipped) {
+			fmt.Println(err)
+			return
+		}
+		if err != nil {
+			cmdutil.HandleError("Error downloading file", err)
+		}
+	},
+}
+
+func init() {
+	getCmd.Flags().StringVarP(&getOutputFile, "output", "o", "", "output file path (optional)")
+	getCmd.Flags().StringVar(&getPassword, "password", "", "password of the link (prompted for when needed)")
+	getCmd.Flags().BoolVarP(&noProgressBar, "no-progress-bar", "n", false, "disable progress bar")
+	addDownloadOptionFlags(getCmd)
+}
@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ephemeralfiles/eph/pkg/config"
+	"github.com/ephemeralfiles/eph/pkg/dto"
+	"github.com/ephemeralfiles/eph/pkg/ephcli"
+	"github.com/ephemeralfiles/eph/pkg/history"
+	"github.com/ephemeralfiles/eph/pkg/render"
+	"github.com/ephemeralfiles/eph/pkg/units"
+	"github.com/spf13/cobra"
+)
+
+// defaultHistoryLimit is the number of transfers listed by default.
+const defaultHistoryLimit = 20
+
+var (
+	historyOutput      outputFlags
+	historyType        string
+	historySearch      string
+	historySince       string
+	historyFailed      bool
+	historyAllProfiles bool
+	historyLimit       int
+	historyClear       bool
+
+	// historyStore is the history transfers are recorded to.
+	historyStore = history.NewStore(history.DefaultPath())
+)
+
+// historyCmd represents the history command.
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "list the past uploads and downloads",
+	Long: `list the past uploads and downloads, most recent first.
+
+Every upload and download is recorded in ~/.config/eph/history.jsonl with its
+time, configuration profile, organization, local path, file ID, size, checksum,
+duration and result. Set disable_history: true in the configuration to stop
+recording transfers.
+
+Only the transfers of the current profile (-c) are listed, unless
+--all-profiles is set. --search matches the path, source URL, file ID and error
+of the transfers.
+`,
+	Example: `  eph history --type upload --since 7d
+  eph history --search report --failed
+  eph history --limit 0 -o csv > transfers.csv`,
+	Args: cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		if historyClear {
+			if err := historyStore.Clear(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+				os.Exit(1)
+			}
+			fmt.Println("History cleared")
+			return
+		}
+
+		filter := history.Filter{Direction: historyType, Search: historySearch, Limit: historyLimit}
+		switch historyType {
+		case "", ephcli.TransferUpload, ephcli.TransferDownload:
+		default:
+			fmt.Fprintf(os.Stderr, "Error: invalid type %q, expected upload or download\n", historyType)
+			os.Exit(1)
+		}
+		if !historyAllProfiles {
+			filter.Profile = currentProfile()
+		}
+		if historyFailed {
+			filter.Result = history.ResultError
+		}
+		if historySince != "" {
+			since, err := history.ParseSince(historySince, time.Now())
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+				os.Exit(1)
+			}
+			filter.Since = since
+		}
+
+		entries, err := historyStore.Entries()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			os.Exit(1)
+		}
+		columns := historyColumns
+		if historyAllProfiles {
+			columns = slices.Concat([]render.Column[history.Entry]{historyProfileColumn}, historyColumns)
+		}
+		printList(&historyOutput, filter.Apply(entries), columns, "No transfers recorded")
+	},
+}
+
+func init() {
+	addOutputFlags(historyCmd, &historyOutput, "format")
+	historyCmd.Flags().StringVar(&historyType, "type", "", "only list uploads or downloads (upload, download)")
+	historyCmd.Flags().StringVar(&historySearch, "search", "", "only list transfers matching this text")
+	historyCmd.Flags().StringVar(&historySince, "since", "",
+		"only list transfers since this duration ago or date, e.g. 24h, 7d or 2026-12-31")
+	historyCmd.Flags().BoolVar(&historyFailed, "failed", false, "only list failed transfers")
+	historyCmd.Flags().BoolVar(&historyAllProfiles, "all-profiles", false,
+		"list the transfers of every configuration profile")
+	historyCmd.Flags().IntVar(&historyLimit, "limit", defaultHistoryLimit, "maximum number of transfers listed, 0 for all")
+	historyCmd.Flags().BoolVar(&historyClear, "clear", false, "delete the history")
+}
+
+// historyColumns are the columns of the transfers of the history.
+var historyColumns = []render.Column[history.Entry]{
+	{Name: "Time", Value: func(e history.Entry) string { return e.Time.Local().Format(time.DateTime) }},
+	{Name: "Type", Value: func(e history.Entry) string { return e.Direction }},
+	{Name: "File ID", Value: func(e history.Entry) string { return e.FileID }},
+	{Name: "Size", Value: func(e history.Entry) string { return units.FormatSize(e.Size) }},
+	{Name: "Duration", Value: func(e history.Entry) string { return e.Duration().Round(time.Millisecond).String() }},
+	{Name: "Path", Value: func(e history.Entry) string { return firstNonEmpty(e.Path, e.Source) }},
+	{Name: "Result", Value: func(e history.Entry) string {
+		if e.Failed() {
+			return "failed: " + e.Error
+		}
+		return e.Result
+	}},
+	{Name: "Organization", Value: func(e history.Entry) string { return e.OrganizationID }, Extra: true},
+	{Name: "Encryption", Value: func(e history.Entry) string { return e.Encryption }, Extra: true},
+	{Name: "Checksum", Value: func(e history.Entry) string { return e.Checksum }, Extra: true},
+}
+
+// historyProfileColumn is the column of the profile of the transfers, listed
+// first with --all-profiles.
+var historyProfileColumn = render.Column[history.Entry]{
+	Name: "Profile", Value: func(e history.Entry) string { return e.Profile },
+}
+
+// currentProfile returns the name of the configuration profile in use: the
+// name of its file without extension.
+func currentProfile() string {
+	path := config.ResolveConfigPath(configurationFile)
+	return strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+}
+
+// configureHistory records the transfers of the client in the history, unless
+// disabled by the configuration.
+func configureHistory() {
+	if cfg.DisableHistory {
+		return
+	}
+	profile := currentProfile()
+	c.SetTransferRecorder(func(t ephcli.Transfer) {
+		if err := historyStore.Append(history.NewEntry(t, profile)); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: transfer not recorded in history: %s\n", err)
+		}
+	})
+}
+
+// lastUpload returns the most recent successful upload of the current profile.
+func lastUpload() (history.Entry, error) {
+	entries, err := historyStore.Entries()
+	if err != nil {
+		return history.Entry{}, err
+	}
+	filter := history.Filter{Direction: ephcli.TransferUpload, Profile: currentProfile(), Result: history.ResultOK}
+	for _, e := range filter.Apply(entries) {
+		if e.FileID != "" {
+			return e, nil
+		}
+	}
+	return history.Entry{}, fmt.Errorf("%w for profile %s", history.ErrNoUpload, currentProfile())
+}
+
+// downloadLastUpload downloads the most recent upload of the history, in the
+// transfer mode it was uploaded with.
+func downloadLastUpload() error {
+	entry, err := lastUpload()
+	if err != nil {
+		return err
+	}
+	name := entry.Source
+	if entry.Path != "" {
+		name = filepath.Base(entry.Path)
+	}
+	fmt.Fprintf(os.Stderr, "Downloading %s (%s)\n", name, entry.FileID)
+	switch {
+	case entry.Encryption != dto.EncryptionModeClear:
+		return c.DownloadE2E(entry.FileID, outputFile)
+	case entry.OrganizationID != "":
+		return c.DownloadOrganizationFile(entry.FileID, outputFile)
+	default:
+		return c.Download(entry.FileID, outputFile)
+	}
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ephemeralfiles/eph/pkg/cmdutil"
+	"github.com/ephemeralfiles/eph/pkg/dto"
+	"github.com/ephemeralfiles/eph/pkg/render"
+	"github.com/ephemeralfiles/eph/pkg/units"
+	"github.com/spf13/cobra"
+)
+
+var (
+	infoInput  string
+	infoOutput outputFlags
+)
+
+// infoCmd represents the info command.
+var infoCmd = &cobra.Command{
+	Use:   "info",
+	Short: "show the metadata of a file",
+	Long: `show the metadata of a file: name, size, parts, owner, tags, upload and
+expiration dates, encryption mode and checksum (when the server provides them).
+`,
+	Example: `  eph info -i FILE_ID
+  eph info -i FILE_ID -o json`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		cmdutil.ValidateRequired(infoInput, "uuid", cmd)
+		InitClient()
+
+		info, err := c.GetFileInfo(infoInput)
+		if err != nil {
+			cmdutil.HandleError("Error getting file info", err)
+		}
+		printItem(&infoOutput, info, fileInfoColumns)
+	},
+}
+
+// orgInfoFileCmd represents the organization info-file command.
+var orgInfoFileCmd = &cobra.Command{
+	Use:     "info-file",
+	Short:   "Show the metadata of an organization file",
+	Long:    `Display the metadata of a file of the organization.`,
+	Example: `  eph org info-file -i FILE_ID --org eph1`,
+	Args:    cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		if infoInput == "" {
+			fmt.Fprintf(os.Stderr, "Error: --input flag is required\n")
+			os.Exit(1)
+		}
+		InitClient()
+		organizationID := resolveOrganizationID()
+
+		info, err := c.GetFileInfo(infoInput)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting file info: %s\n", err)
+			os.Exit(1)
+		}
+		if info.OrganizationID != "" && info.OrganizationID != organizationID {
+			fmt.Fprintf(os.Stderr, "Error: file not found in organization: %s\n", infoInput)
+			os.Exit(1)
+		}
+		printItem(&infoOutput, info, fileInfoColumns)
+	},
+}
+
+// fileInfoColumns are the fields of the metadata of a file.
+var fileInfoColumns = []render.Column[*dto.InfoFile]{
+	{Name: "ID", Value: func(i *dto.InfoFile) string { return i.FileID }},
+	{Name: "Name", Value: func(i *dto.InfoFile) string { return i.Filename }},
+	{Name: "Size", Value: func(i *dto.InfoFile) string {
+		return fmt.Sprintf("%s (%d bytes)", units.FormatSize(i.Size), i.Size)
+	}},
+	{Name: "Parts", Value: func(i *dto.InfoFile) string { return strconv.Itoa(i.NbParts) }},
+	{Name: "Owner", Value: func(i *dto.InfoFile) string { return firstNonEmpty(i.OwnerEmail, i.OwnerID) }},
+	{Name: "Organization", Value: func(i *dto.InfoFile) string { return i.OrganizationID }},
+	{Name: "Tags", Value: func(i *dto.InfoFile) string { return strings.Join(i.Tags, ", ") }},
+	{Name: "Uploaded", Value: func(i *dto.InfoFile) string { return formatInfoDate(i.UploadDate) }},
+	{Name: "Expires", Value: func(i *dto.InfoFile) string { return formatInfoDate(i.ExpirationDate) }},
+	{Name: "Encryption", Value: func(i *dto.InfoFile) string { return encryptionModeLabel(i.EncryptionMode) }},
+	{Name: "Checksum", Value: func(i *dto.InfoFile) string { return i.Checksum }},
+}
+
+// formatInfoDate formats a date in local time, or returns an empty string for the zero time.
+func formatInfoDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Local().Format(time.DateTime)
+}
+
+// encryptionModeLabel describes an encryption mode.
+func encryptionModeLabel(mode string) string {
+	switch mode {
+	case dto.EncryptionModeE2E:
+		return "end-to-end"
+	case dto.EncryptionModeClear:
+		return "none"
+	default:
+		return mode
+	}
+}
+
+// firstNonEmpty returns the first non-empty value.
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{infoCmd, orgInfoFileCmd} {
+		cmd.Flags().StringVarP(&infoInput, "input", "i", "", "uuid of the file (required)")
+		addOutputFlags(cmd, &infoOutput, "format")
+	}
+}
@@ -3,58 +3,69 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"time"
 
-	"github.com/ephemeralfiles/eph/pkg/ephcli"
+	"github.com/ephemeralfiles/eph/pkg/dto"
+	"github.com/ephemeralfiles/eph/pkg/listing"
+	"github.com/ephemeralfiles/eph/pkg/render"
 	"github.com/spf13/cobra"
 )
 
-const (
-	renderFormatTable = "table"
-	renderFormatJSON  = "json"
-	renderFormatCSV   = "csv"
-	renderFormatYAML  = "yaml"
+var (
+	listOutput  outputFlags
+	listListing listingFlags
 )
 
+// fileColumns returns the columns of the files of the ls command, with sizes
+// formatted by formatSize.
+func fileColumns(formatSize func(size int64) string) []render.Column[dto.File] {
+	return []render.Column[dto.File]{
+		{Name: "ID", Value: func(f dto.File) string { return f.FileID }},
+		{Name: "Filename", Value: func(f dto.File) string { return f.FileName }},
+		{Name: "Size", Value: func(f dto.File) string { return formatSize(f.Size) }},
+		{Name: "Expiration date", Value: func(f dto.File) string { return f.ExpirationDate.Format(time.DateTime) }},
+		{Name: "Owner", Value: func(f dto.File) string { return f.OwnerID }, Extra: true},
+		{Name: "Uploaded", Value: func(f dto.File) string { return formatInfoDate(f.UpdateDateEnd) }, Extra: true},
+	}
+}
+
+// formatBytes formats a size in bytes as a number, the raw size of ls.
+func formatBytes(size int64) string {
+	return strconv.FormatInt(size, 10)
+}
+
+// fileListing returns the view of a file filtered and sorted by the ls command.
+func fileListing(f dto.File) listing.File {
+	return listing.File{Name: f.FileName, Size: f.Size, ExpiresAt: f.ExpirationDate}
+}
+
 // listCmd represents the get command.
 var listCmd = &cobra.Command{
 	Use:   "ls",
 	Short: "list files",
-	Long: `list files. The rendering type is optional.
+	Long: `list files. The output format is optional.
+
+Files are listed in the order of the server, unless sorted with --sort (name,
+size or expires); --reverse reverses the order. They can be filtered by name
+(--name-glob '*.pdf'), size (--larger-than 1G) and expiration
+(--expires-within 24h). --human prints sizes in human-readable units.
 `,
+	Example: `  eph ls
+  eph ls -o json
+  eph ls -o csv --columns id,filename,owner
+  eph ls --sort size --reverse --human
+  eph ls --name-glob '*.pdf' --expires-within 24h`,
 	Run: func(_ *cobra.Command, _ []string) {
+		opts := listListing.options()
 		InitClient()
-		// check rendering type
-		if renderingType != renderFormatTable &&
-			renderingType != renderFormatJSON &&
-			renderingType != renderFormatCSV &&
-			renderingType != renderFormatYAML {
-			fmt.Fprintf(os.Stderr, "Invalid rendering type\n")
-			os.Exit(1)
-		}
 
 		files, err := c.Fetch()
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error fetching files: %s\n", err)
 			os.Exit(1)
 		}
-		if files == nil {
-			os.Exit(0)
-		}
-
-		switch renderingType {
-		case renderFormatJSON:
-			err = ephcli.PrintJSON(&files)
-		case renderFormatCSV:
-			err = ephcli.PrintCSV(&files)
-		case renderFormatYAML:
-			err = ephcli.PrintYAML(&files)
-		default:
-			err = ephcli.Print(&files)
-		}
-
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error rendering files: %s\n", err)
-			os.Exit(1)
-		}
+		files = listing.Apply(files, fileListing, opts, time.Now())
+		printList(&listOutput, files, fileColumns(listListing.sizeFormatter(formatBytes)), "No files found")
 	},
 }
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"github.com/ephemeralfiles/eph/pkg/cmdutil"
+	"github.com/ephemeralfiles/eph/pkg/listing"
+	"github.com/ephemeralfiles/eph/pkg/units"
+	"github.com/spf13/cobra"
+)
+
+// listingFlags are the flags filtering and sorting the files listed by a command.
+type listingFlags struct {
+	sort          string
+	reverse       bool
+	nameGlob      string
+	largerThan    string
+	expiresWithin string
+	human         bool
+}
+
+// addListingFlags registers the filtering, sorting and size formatting flags of cmd.
+func addListingFlags(cmd *cobra.Command, l *listingFlags) {
+	cmd.Flags().StringVar(&l.sort, "sort", "", "sort the files by name, size or expires")
+	cmd.Flags().BoolVar(&l.reverse, "reverse", false, "reverse the order of the files")
+	cmd.Flags().StringVar(&l.nameGlob, "name-glob", "", "only list the files whose name matches this pattern, e.g. '*.pdf'")
+	cmd.Flags().StringVar(&l.largerThan, "larger-than", "", "only list the files larger than this size, e.g. 500K or 1G")
+	cmd.Flags().StringVar(&l.expiresWithin, "expires-within", "",
+		"only list the files expiring within this duration, e.g. 24h or 7d")
+	cmd.Flags().BoolVar(&l.human, "human", false, "print sizes in human-readable units, e.g. 1.2 MB")
+}
+
+// options returns the listing options of the flags, exiting on invalid values.
+func (l *listingFlags) options() listing.Options {
+	opts := listing.Options{NameGlob: l.nameGlob, Sort: l.sort, Reverse: l.reverse}
+	if l.largerThan != "" {
+		size, err := units.ParseSize(l.largerThan)
+		if err != nil {
+			cmdutil.HandleError("Error: invalid --larger-than", err)
+		}
+		opts.LargerThan = size
+	}
+	if l.expiresWithin != "" {
+		d, err := units.ParseDuration(l.expiresWithin)
+		if err != nil {
+			cmdutil.HandleError("Error: invalid --expires-within", err)
+		}
+		opts.ExpiresWithin = d
+	}
+	if err := opts.Validate(); err != nil {
+		cmdutil.HandleError("Error", err)
+	}
+	return opts
+}
+
+// sizeFormatter returns the formatter of the sizes in bytes of the listed files:
+// human-readable units with --human, raw otherwise.
+func (l *listingFlags) sizeFormatter(raw func(size int64) string) func(size int64) string {
+	if l.human {
+		return units.FormatSize
+	}
+	return raw
+}
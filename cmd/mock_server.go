@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/ephemeralfiles/eph/pkg/logger"
+	"github.com/ephemeralfiles/eph/pkg/mockserver"
+	"github.com/spf13/cobra"
+)
+
+const (
+	mockServerReadHeaderTimeout = 10 * time.Second
+	mockServerShutdownTimeout   = 5 * time.Second
+	mockServerTokenValidity     = 365 * 24 * time.Hour
+)
+
+var (
+	mockServerListen string
+	mockServerData   string
+	mockServerSeed   bool
+	mockServerEmail  string
+)
+
+// mockServerCmd represents the mock-server command.
+var mockServerCmd = &cobra.Command{
+	Use:    "mock-server",
+	Short:  "run a local stand-in for the ephemeralfiles API",
+	Hidden: true,
+	Long: `run a local stand-in for the ephemeralfiles API.
+
+The server keeps files in memory and performs the same E2E key exchange as the
+real service. It is meant for development and for the integration test suite.
+Use --data to persist the state between runs and --seed to start with fixture
+organizations, files and tags.
+
+Point the client to it with the printed environment variables.
+`,
+	Run: func(_ *cobra.Command, _ []string) {
+		log := logger.NoLogger()
+		if debugMode {
+			log = logger.NewLogger("debug")
+		}
+
+		srv, err := mockserver.New(mockserver.Options{
+			DataDir: mockServerData,
+			Seed:    mockServerSeed,
+			Logger:  log,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating mock server: %s\n", err)
+			os.Exit(1)
+		}
+
+		httpServer := &http.Server{
+			Addr:              mockServerListen,
+			Handler:           srv.Handler(),
+			ReadHeaderTimeout: mockServerReadHeaderTimeout,
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		go func() {
+			<-ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), mockServerShutdownTimeout)
+			defer cancel()
+			_ = httpServer.Shutdown(shutdownCtx)
+		}()
+
+		fmt.Printf("mock server listening on %s\n", mockServerListen)
+		fmt.Printf("export EPHEMERALFILES_ENDPOINT=%s\n", mockServerURL(mockServerListen))
+		fmt.Printf("export EPHEMERALFILES_TOKEN=%s\n", mockserver.DevToken(mockServerEmail, mockServerTokenValidity))
+
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Fprintf(os.Stderr, "Error running mock server: %s\n", err)
+			os.Exit(1)
+		}
+		if err := srv.Save(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error saving state: %s\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// mockServerURL returns the URL clients should use for a listen address.
+func mockServerURL(listen string) string {
+	if len(listen) > 0 && listen[0] == ':' {
+		return "http://localhost" + listen
+	}
+	return "http://" + listen
+}
+
+func init() {
+	mockServerCmd.Flags().StringVar(&mockServerListen, "listen", ":8080", "address to listen on")
+	mockServerCmd.Flags().StringVar(&mockServerData, "data", "", "directory where the state is persisted (optional)")
+	mockServerCmd.Flags().BoolVar(&mockServerSeed, "seed", false, "load fixture organizations, files and tags when the state is empty")
+	mockServerCmd.Flags().StringVar(&mockServerEmail, "email", mockserver.DefaultEmail, "email carried by the printed token")
+
+	rootCmd.AddCommand(mockServerCmd)
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ephemeralfiles/eph/pkg/cmdutil"
+	"github.com/spf13/cobra"
+)
+
+// mvCmd represents the mv command.
+var mvCmd = &cobra.Command{
+	Use:   "mv NEW_NAME",
+	Short: "rename a file",
+	Long: `rename an uploaded file. Only the name of the file changes: its content,
+expiration and share links are kept.
+`,
+	Example: `  eph mv -i FILE_ID report-2026.pdf`,
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if uuidFile == "" {
+			fmt.Fprintf(os.Stderr, "uuid is required\n")
+			_ = cmd.Usage()
+			os.Exit(1)
+		}
+
+		InitClient()
+		file, err := c.RenameFile(uuidFile, args[0])
+		if err != nil {
+			cmdutil.HandleError("Error renaming file", err)
+		}
+		fmt.Printf("%s renamed to %s\n", file.FileID, file.FileName)
+	},
+}
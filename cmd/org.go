@@ -27,14 +27,19 @@ func init() {
 	// Add subcommands
 	orgCmd.AddCommand(orgListCmd)
 	orgCmd.AddCommand(orgUseCmd)
+	orgCmd.AddCommand(orgTransferCmd)
 	orgCmd.AddCommand(orgInfoCmd)
+	orgCmd.AddCommand(orgInfoFileCmd)
 	orgCmd.AddCommand(orgStorageCmd)
 	orgCmd.AddCommand(orgUploadCmd)
 	orgCmd.AddCommand(orgListFilesCmd)
 	orgCmd.AddCommand(orgDownloadCmd)
+	orgCmd.AddCommand(orgSyncCmd)
 	orgCmd.AddCommand(orgDeleteCmd)
 	orgCmd.AddCommand(orgStatsCmd)
 	orgCmd.AddCommand(orgTagsCmd)
+	orgCmd.AddCommand(orgTagCmd)
+	orgCmd.AddCommand(orgShareCmd)
 
 	rootCmd.AddCommand(orgCmd)
 }
@@ -1,33 +1,81 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"text/tabwriter"
 
+	"github.com/ephemeralfiles/eph/pkg/cmdutil"
+	"github.com/ephemeralfiles/eph/pkg/ephcli"
+	"github.com/ephemeralfiles/eph/pkg/units"
 	"github.com/spf13/cobra"
 )
 
 var (
-	orgDlFile   string
-	orgDlOutput string
+	orgDlFile        string
+	orgDlOutput      string
+	orgDlTags        string
+	orgDlRecent      int
+	orgDlOwner       string
+	orgDlDryRun      bool
+	orgDlConcurrency int
 )
 
 // orgDownloadCmd represents the organization download command.
 var orgDownloadCmd = &cobra.Command{
 	Use:   "dl",
 	Short: "Download file from organization",
-	Long:  `Download a file from an organization by file ID.`,
+	Long: `Download a file from an organization by file ID.
+
+The name sent by the server is sanitized and the file is written to the
+current directory, or to --output-dir. When the file already exists, it is
+overwritten unless --skip or --rename is set.
+
+With --resume, an interrupted download keeps its partial file and the next
+run with --resume only fetches the missing parts.
+
+Files are downloaded with end-to-end encryption, unless --clear is set or the
+organization defaults to clear ('eph org transfer clear'); --e2e overrides it.
+
+Instead of --input, several files can be selected with --tags (files having
+all the tags), --owner (email or ID of the uploader) and --recent N (the N most
+recent files). They are downloaded in parallel (--concurrency); files sharing a
+name are saved under distinct names. Use --dry-run to list them.
+
+Without any of these flags on a terminal, the file is picked from a list of the
+files of the organization with fuzzy search.`,
+	Example: `  eph org dl -i FILE_ID
+  eph org dl --tags invoice,q1 --output-dir ./out
+  eph org dl --owner alice@example.com --recent 10 --dry-run`,
 	Run: func(_ *cobra.Command, _ []string) {
 		InitClient()
 
-		if orgDlFile == "" {
-			fmt.Fprintf(os.Stderr, "Error: --input flag is required\n")
+		bulk := orgDlTags != "" || orgDlRecent > 0 || orgDlOwner != ""
+		if orgDlFile == "" && !bulk && cmdutil.IsInteractive() {
+			orgDlFile = pickOrganizationFiles("Select the file to download", false)[0].ID
+		}
+		if orgDlFile == "" && !bulk {
+			fmt.Fprintf(os.Stderr, "Error: --input, --tags, --recent or --owner flag is required\n")
 			os.Exit(1)
 		}
+		configureDownloadOptions()
+		if bulk {
+			downloadOrganizationFiles()
+			return
+		}
 
-		// Organization files use encrypted downloads (E2E encryption)
-		// The filename is retrieved from server metadata
-		err := c.DownloadE2E(orgDlFile, orgDlOutput)
+		// Use encrypted download unless --clear is set or the organization defaults to clear
+		var err error
+		if downloadClearTransfer() {
+			err = c.DownloadOrganizationFile(orgDlFile, orgDlOutput)
+		} else {
+			err = c.DownloadE2E(orgDlFile, orgDlOutput)
+		}
+		if errors.Is(err, ephcli.ErrDownloadSkipped) {
+			fmt.Println(err)
+			return
+		}
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error downloading file: %s\n", err)
 			os.Exit(1)
@@ -37,8 +85,88 @@ var orgDownloadCmd = &cobra.Command{
 	},
 }
 
+// downloadOrganizationFiles downloads the organization files selected by the
+// --tags, --recent and --owner flags.
+func downloadOrganizationFiles() {
+	if orgDlRecent < 0 {
+		fmt.Fprintf(os.Stderr, "Error: --recent must be positive\n")
+		os.Exit(1)
+	}
+	org := resolveOrganization()
+	files, err := c.SelectOrganizationFiles(org.ID, ephcli.OrganizationFileFilter{
+		Tags:   ephcli.ParseTags(orgDlTags),
+		Owner:  orgDlOwner,
+		Recent: orgDlRecent,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error listing files: %s\n", err)
+		os.Exit(1)
+	}
+	items, err := c.PlanBulkDownload(files)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+
+	if orgDlDryRun {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0) //nolint:mnd // column padding
+		fmt.Fprintln(w, "ID\tNAME\tSIZE\tOWNER\tTARGET")
+		var total int64
+		for _, item := range items {
+			target := item.Target
+			if item.Skipped {
+				target = "(exists, skipped)"
+			} else {
+				total += item.File.Size
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", item.File.ID, item.File.Filename,
+				units.FormatSize(item.File.Size), item.File.OwnerEmail, target)
+		}
+		_ = w.Flush()
+		fmt.Printf("%d file(s), %s (dry run, nothing downloaded)\n", len(items), units.FormatSize(total))
+		return
+	}
+
+	downloaded, skipped := 0, 0
+	clearDownload := organizationClearTransfer(org)
+	failed, err := c.DownloadOrganizationFiles(items, orgDlConcurrency, clearDownload, func(item ephcli.BulkDownloadItem, err error) {
+		switch {
+		case errors.Is(err, ephcli.ErrDownloadSkipped):
+			skipped++
+			fmt.Println(err)
+		case err != nil:
+			fmt.Fprintf(os.Stderr, "Error downloading %s (%s): %s\n", item.File.ID, item.File.Filename, err)
+		default:
+			downloaded++
+			fmt.Printf("%s -> %s\n", item.File.ID, item.Target)
+		}
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("%d file(s) downloaded, %d skipped, %d failed\n", downloaded, skipped, failed)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
 func init() {
-	orgDownloadCmd.Flags().StringVarP(&orgDlFile, "input", "i", "", "file ID to download (required)")
+	orgDownloadCmd.Flags().StringVarP(&orgDlFile, "input", "i", "", "file ID to download")
 	orgDownloadCmd.Flags().StringVarP(&orgDlOutput, "output", "o", "", "output filename (optional)")
 	orgDownloadCmd.Flags().BoolVarP(&noProgressBar, "no-progress-bar", "n", false, "disable progress bar")
+	addDownloadOptionFlags(orgDownloadCmd)
+	addOrgTransferFlags(orgDownloadCmd, "download")
+	orgDownloadCmd.Flags().StringVar(&orgDlTags, "tags", "", "download the files having all these comma-separated tags")
+	orgDownloadCmd.Flags().IntVar(&orgDlRecent, "recent", 0, "download the N most recent files")
+	orgDownloadCmd.Flags().StringVar(&orgDlOwner, "owner", "", "download the files uploaded by this user (email or ID)")
+	orgDownloadCmd.Flags().BoolVar(&orgDlDryRun, "dry-run", false, "list the selected files without downloading them")
+	orgDownloadCmd.Flags().IntVar(&orgDlConcurrency, "concurrency", ephcli.DefaultBulkConcurrency,
+		"number of parallel downloads")
+	orgDownloadCmd.MarkFlagsMutuallyExclusive("input", "tags")
+	orgDownloadCmd.MarkFlagsMutuallyExclusive("input", "recent")
+	orgDownloadCmd.MarkFlagsMutuallyExclusive("input", "owner")
+	orgDownloadCmd.MarkFlagsMutuallyExclusive("output", "tags")
+	orgDownloadCmd.MarkFlagsMutuallyExclusive("output", "recent")
+	orgDownloadCmd.MarkFlagsMutuallyExclusive("output", "owner")
 }
@@ -1,17 +1,17 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
+	"strconv"
 
 	"github.com/ephemeralfiles/eph/pkg/dto"
 	"github.com/ephemeralfiles/eph/pkg/ephcli"
+	"github.com/ephemeralfiles/eph/pkg/render"
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v2"
 )
 
-var orgInfoFormat string
+var orgInfoOutput outputFlags
 
 // orgInfoCmd represents the organization info command.
 var orgInfoCmd = &cobra.Command{
@@ -62,50 +62,33 @@ var orgInfoCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		switch orgInfoFormat {
-		case renderFormatJSON:
-			combined := map[string]interface{}{
-				"organization": org,
-				"storage":      storage,
-				"stats":        stats,
-			}
-			output, err := json.MarshalIndent(combined, "", "  ")
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error encoding JSON: %s\n", err)
-				os.Exit(1)
-			}
-			fmt.Println(string(output))
-		case renderFormatYAML:
-			combined := map[string]interface{}{
-				"organization": org,
-				"storage":      storage,
-				"stats":        stats,
-			}
-			output, err := yaml.Marshal(combined)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error encoding YAML: %s\n", err)
-				os.Exit(1)
-			}
-			fmt.Print(string(output))
-		default:
-			// Table format
-			fmt.Printf("Organization: %s\n", org.Name)
-			fmt.Printf("ID: %s\n", org.ID)
-			fmt.Printf("Storage: %.2fGB / %.2fGB (%.1f%%)\n",
-				storage.UsedStorageGB, storage.StorageLimitGB, storage.UsagePercent)
-
-			sub := "Active"
-			if !org.SubscriptionActive {
-				sub = "Inactive"
-			}
-			fmt.Printf("Subscription: %s\n", sub)
-			fmt.Printf("Members: %d\n", stats.MemberCount)
-			fmt.Printf("Files: %d (%d active, %d expired)\n",
-				stats.FileCount, stats.ActiveFiles, stats.ExpiredFiles)
-		}
+		info := organizationInfo{Organization: org, Storage: storage, Stats: stats}
+		printItem(&orgInfoOutput, info, organizationInfoColumns)
 	},
 }
 
+// organizationInfo is an organization with its storage and statistics.
+type organizationInfo struct {
+	Organization *dto.Organization        `json:"organization" yaml:"organization"`
+	Storage      *dto.OrganizationStorage `json:"storage"      yaml:"storage"`
+	Stats        *dto.OrganizationStats   `json:"stats"        yaml:"stats"`
+}
+
+// organizationInfoColumns are the fields of the information of an organization.
+var organizationInfoColumns = []render.Column[organizationInfo]{
+	{Name: "Organization", Value: func(i organizationInfo) string { return i.Organization.Name }},
+	{Name: "ID", Value: func(i organizationInfo) string { return i.Organization.ID }},
+	{Name: "Storage", Value: func(i organizationInfo) string {
+		return fmt.Sprintf("%.2fGB / %.2fGB (%.1f%%)",
+			i.Storage.UsedStorageGB, i.Storage.StorageLimitGB, i.Storage.UsagePercent)
+	}},
+	{Name: "Subscription", Value: func(i organizationInfo) string { return subscriptionStatus(*i.Organization) }},
+	{Name: "Members", Value: func(i organizationInfo) string { return strconv.FormatInt(i.Stats.MemberCount, 10) }},
+	{Name: "Files", Value: func(i organizationInfo) string {
+		return fmt.Sprintf("%d (%d active, %d expired)", i.Stats.FileCount, i.Stats.ActiveFiles, i.Stats.ExpiredFiles)
+	}},
+}
+
 func init() {
-	orgInfoCmd.Flags().StringVarP(&orgInfoFormat, "format", "r", renderFormatTable, "output format: table, json, yaml")
+	addOutputFlags(orgInfoCmd, &orgInfoOutput, "format")
 }
@@ -1,16 +1,15 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 
-	"github.com/pterm/pterm"
+	"github.com/ephemeralfiles/eph/pkg/dto"
+	"github.com/ephemeralfiles/eph/pkg/render"
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v2"
 )
 
-var orgListFormat string
+var orgListOutput outputFlags
 
 // orgListCmd represents the organization list command.
 var orgListCmd = &cobra.Command{
@@ -26,54 +25,33 @@ var orgListCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		if len(orgs) == 0 {
-			fmt.Println("No organizations found")
-			return
-		}
-
-		switch orgListFormat {
-		case "json":
-			output, err := json.MarshalIndent(orgs, "", "  ")
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error encoding JSON: %s\n", err)
-				os.Exit(1)
-			}
-			fmt.Println(string(output))
-		case "yaml":
-			output, err := yaml.Marshal(orgs)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error encoding YAML: %s\n", err)
-				os.Exit(1)
-			}
-			fmt.Print(string(output))
-		default:
-			// Table format
-			tableData := pterm.TableData{
-				{"ID", "NAME", "STORAGE", "SUBSCRIPTION", "ROLE"},
-			}
-			for _, org := range orgs {
-				storage := fmt.Sprintf("%.2fGB / %.2fGB", org.UsedStorageGB, org.StorageLimitGB)
-				sub := "Active"
-				if !org.SubscriptionActive {
-					sub = "Inactive"
-				}
-				role := org.UserRole
-				if role == "" {
-					role = "Member"
-				}
-				tableData = append(tableData, []string{
-					org.ID,
-					org.Name,
-					storage,
-					sub,
-					role,
-				})
-			}
-			_ = pterm.DefaultTable.WithHasHeader().WithData(tableData).Render()
-		}
+		printList(&orgListOutput, orgs, organizationColumns, "No organizations found")
 	},
 }
 
+// organizationColumns are the columns of the organizations of the user.
+var organizationColumns = []render.Column[dto.Organization]{
+	{Name: "ID", Value: func(o dto.Organization) string { return o.ID }},
+	{Name: "Name", Value: func(o dto.Organization) string { return o.Name }},
+	{Name: "Storage", Value: func(o dto.Organization) string {
+		return fmt.Sprintf("%.2fGB / %.2fGB", o.UsedStorageGB, o.StorageLimitGB)
+	}},
+	{Name: "Subscription", Value: subscriptionStatus},
+	{Name: "Role", Value: func(o dto.Organization) string { return firstNonEmpty(o.UserRole, "Member") }},
+	{Name: "Retention", Value: func(o dto.Organization) string {
+		return fmt.Sprintf("%d days", o.DefaultRetentionDays)
+	}, Extra: true},
+	{Name: "Created", Value: func(o dto.Organization) string { return formatOrganizationDate(o.CreatedAt) }, Extra: true},
+}
+
+// subscriptionStatus describes the subscription of an organization.
+func subscriptionStatus(org dto.Organization) string {
+	if org.SubscriptionActive {
+		return "Active"
+	}
+	return "Inactive"
+}
+
 func init() {
-	orgListCmd.Flags().StringVarP(&orgListFormat, "format", "r", "table", "output format: table, json, yaml")
+	addOutputFlags(orgListCmd, &orgListOutput, "format")
 }
@@ -1,30 +1,26 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/ephemeralfiles/eph/pkg/dto"
 	"github.com/ephemeralfiles/eph/pkg/ephcli"
-	"github.com/pterm/pterm"
+	"github.com/ephemeralfiles/eph/pkg/listing"
+	"github.com/ephemeralfiles/eph/pkg/render"
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v2"
 )
 
 const (
-	bytesToKB           = 1024.0
-	maxTagsDisplayLen   = 30
-	maxOwnerDisplayLen  = 25
-	defaultFilesLimit   = 100
-	tagsTruncateLen     = 27
-	ownerTruncateLen    = 22
-	expirationDateLen   = 10
+	bytesToKB         = 1024.0
+	defaultFilesLimit = 100
 )
 
 var (
-	orgLsFormat  string
+	orgLsOutput  outputFlags
+	orgLsListing listingFlags
 	orgLsTags    string
 	orgLsLimit   int
 	orgLsOffset  int
@@ -36,8 +32,17 @@ var (
 var orgListFilesCmd = &cobra.Command{
 	Use:   "ls",
 	Short: "List organization files",
-	Long:  `List files in an organization with optional filtering by tags.`,
+	Long: `List files in an organization with optional filtering by tags.
+
+The files can be sorted, filtered and formatted on the client side with the
+flags of eph ls: --sort, --reverse, --name-glob, --larger-than, --expires-within
+and --human. When sorting or filtering, all the files (having --tags) are
+fetched, and --limit and --offset apply to the result. With --recent and
+--expired, they apply to the files returned by the server.`,
+	Example: `  eph org ls --tags invoice --sort expires
+  eph org ls --larger-than 100M --human`,
 	Run: func(_ *cobra.Command, _ []string) {
+		opts := orgLsListing.options()
 		InitClient()
 
 		orgCtx := ephcli.NewOrgContext(c, cfg)
@@ -54,12 +59,15 @@ var orgListFilesCmd = &cobra.Command{
 			files, err = c.ListRecentOrganizationFiles(org.ID, orgLsLimit)
 		} else if orgLsExpired {
 			files, err = c.ListExpiredOrganizationFiles(org.ID, orgLsLimit)
-		} else if orgLsTags != "" {
-			tags := strings.Split(orgLsTags, ",")
-			for i := range tags {
-				tags[i] = strings.TrimSpace(tags[i])
+		} else if !opts.IsZero() {
+			// Sorting and filtering apply to every file, before the page is taken
+			files, err = listAllOrganizationFilesWithTags(org.ID, orgLsTags)
+			if err == nil {
+				files = listing.Page(listing.Apply(files, organizationFileListing, opts, time.Now()),
+					orgLsOffset, orgLsLimit)
 			}
-			files, err = c.GetOrganizationFilesByTags(org.ID, tags, orgLsLimit, orgLsOffset)
+		} else if orgLsTags != "" {
+			files, err = c.GetOrganizationFilesByTags(org.ID, splitTags(orgLsTags), orgLsLimit, orgLsOffset)
 		} else {
 			files, err = c.ListOrganizationFiles(org.ID, orgLsLimit, orgLsOffset)
 		}
@@ -69,66 +77,76 @@ var orgListFilesCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		if len(files) == 0 {
-			fmt.Println("No files found")
-			return
-		}
-
-		switch orgLsFormat {
-		case renderFormatJSON:
-			output, err := json.MarshalIndent(files, "", "  ")
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error encoding JSON: %s\n", err)
-				os.Exit(1)
-			}
-			fmt.Println(string(output))
-		case renderFormatYAML:
-			output, err := yaml.Marshal(files)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error encoding YAML: %s\n", err)
-				os.Exit(1)
-			}
-			fmt.Print(string(output))
-		case renderFormatCSV:
-			fmt.Println("ID,FILENAME,SIZE,TAGS,OWNER,EXPIRATION")
-			for _, file := range files {
-				sizeKB := float64(file.Size) / bytesToKB
-				tags := strings.Join(file.Tags, ";")
-				fmt.Printf("%s,%s,%.2fKB,%s,%s,%s\n",
-					file.ID, file.Filename, sizeKB, tags, file.OwnerEmail, file.ExpirationDate)
-			}
-		default:
-			// Table format
-			tableData := pterm.TableData{
-				{"ID", "FILENAME", "SIZE", "TAGS", "OWNER", "EXPIRATION"},
-			}
-			for _, file := range files {
-				sizeKB := float64(file.Size) / bytesToKB
-				tags := strings.Join(file.Tags, ", ")
-				if len(tags) > maxTagsDisplayLen {
-					tags = tags[:tagsTruncateLen] + "..."
-				}
-				owner := file.OwnerEmail
-				if len(owner) > maxOwnerDisplayLen {
-					owner = owner[:ownerTruncateLen] + "..."
-				}
-				tableData = append(tableData, []string{
-					file.ID,
-					file.Filename,
-					fmt.Sprintf("%.2fKB", sizeKB),
-					tags,
-					owner,
-					file.ExpirationDate[:expirationDateLen],
-				})
-			}
-			_ = pterm.DefaultTable.WithHasHeader().WithData(tableData).Render()
+		if orgLsRecent || orgLsExpired {
+			files = listing.Apply(files, organizationFileListing, opts, time.Now())
 		}
+		columns := organizationFileColumns(orgLsListing.sizeFormatter(formatKilobytes))
+		printList(&orgLsOutput, files, columns, "No files found")
 	},
 }
 
+// listAllOrganizationFilesWithTags lists all the files of an organization,
+// having the comma-separated tags when not empty.
+func listAllOrganizationFilesWithTags(orgID, tags string) ([]dto.OrganizationFile, error) {
+	if tags != "" {
+		return c.ListAllOrganizationFilesByTags(orgID, splitTags(tags)) //nolint:wrapcheck // reported by the caller
+	}
+	return c.ListAllOrganizationFiles(orgID) //nolint:wrapcheck // reported by the caller
+}
+
+// splitTags splits comma-separated tags, trimming spaces.
+func splitTags(list string) []string {
+	tags := strings.Split(list, ",")
+	for i := range tags {
+		tags[i] = strings.TrimSpace(tags[i])
+	}
+	return tags
+}
+
+// organizationFileColumns returns the columns of the files of an organization,
+// with sizes formatted by formatSize.
+func organizationFileColumns(formatSize func(size int64) string) []render.Column[dto.OrganizationFile] {
+	return []render.Column[dto.OrganizationFile]{
+		{Name: "ID", Value: func(f dto.OrganizationFile) string { return f.ID }},
+		{Name: "Filename", Value: func(f dto.OrganizationFile) string { return f.Filename }},
+		{Name: "Size", Value: func(f dto.OrganizationFile) string { return formatSize(f.Size) }},
+		{Name: "Tags", Value: func(f dto.OrganizationFile) string { return strings.Join(f.Tags, ", ") }},
+		{Name: "Owner", Value: func(f dto.OrganizationFile) string { return firstNonEmpty(f.OwnerEmail, f.OwnerID) }},
+		{Name: "Expiration", Value: func(f dto.OrganizationFile) string {
+			return formatOrganizationDate(f.ExpirationDate)
+		}},
+		{Name: "Uploaded", Value: func(f dto.OrganizationFile) string {
+			return formatOrganizationDate(firstNonEmpty(f.UploadDateEnd, f.UploadDateBegin))
+		}, Extra: true},
+		{Name: "Organization", Value: func(f dto.OrganizationFile) string { return f.OrganizationID }, Extra: true},
+	}
+}
+
+// formatKilobytes formats a size in bytes in kilobytes, the raw size of org ls.
+func formatKilobytes(size int64) string {
+	return fmt.Sprintf("%.2fKB", float64(size)/bytesToKB)
+}
+
+// organizationFileListing returns the view of an organization file filtered and
+// sorted by the org ls command.
+func organizationFileListing(f dto.OrganizationFile) listing.File {
+	expiresAt, _ := time.Parse(time.RFC3339, f.ExpirationDate)
+	return listing.File{Name: f.Filename, Size: f.Size, ExpiresAt: expiresAt}
+}
+
+// formatOrganizationDate formats an RFC 3339 date of the organization API in
+// local time, or returns it unchanged when it cannot be parsed.
+func formatOrganizationDate(value string) string {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return value
+	}
+	return formatInfoDate(t)
+}
+
 func init() {
-	orgListFilesCmd.Flags().StringVarP(&orgLsFormat, "format", "r",
-		renderFormatTable, "output format: table, json, csv, yaml")
+	addOutputFlags(orgListFilesCmd, &orgLsOutput, "format")
+	addListingFlags(orgListFilesCmd, &orgLsListing)
 	orgListFilesCmd.Flags().StringVar(&orgLsTags, "tags", "", "filter by comma-separated tags")
 	orgListFilesCmd.Flags().IntVar(&orgLsLimit, "limit", defaultFilesLimit, "maximum number of files")
 	orgListFilesCmd.Flags().IntVar(&orgLsOffset, "offset", 0, "pagination offset")
@@ -6,6 +6,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/ephemeralfiles/eph/pkg/cmdutil"
 	"github.com/spf13/cobra"
 )
 
@@ -18,30 +19,26 @@ var (
 var orgDeleteCmd = &cobra.Command{
 	Use:   "rm",
 	Short: "Delete file from organization",
-	Long:  `Delete a file from an organization by file ID.`,
+	Long: `Delete a file from an organization by file ID.
+
+Without --input on a terminal, the files are picked from a list of the files of
+the organization with fuzzy search: tab selects several files.`,
 	Run: func(_ *cobra.Command, _ []string) {
 		InitClient()
 
+		if orgRmFile == "" && cmdutil.IsInteractive() {
+			deletePickedOrganizationFiles()
+			return
+		}
 		if orgRmFile == "" {
 			fmt.Fprintf(os.Stderr, "Error: --input flag is required\n")
 			os.Exit(1)
 		}
 
 		// Confirmation prompt unless --force
-		if !orgRmForce {
-			fmt.Printf("Are you sure you want to delete file %s? (y/N): ", orgRmFile)
-			reader := bufio.NewReader(os.Stdin)
-			response, err := reader.ReadString('\n')
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error reading input: %s\n", err)
-				os.Exit(1)
-			}
-
-			response = strings.ToLower(strings.TrimSpace(response))
-			if response != "y" && response != "yes" {
-				fmt.Println("Deletion cancelled")
-				return
-			}
+		if !orgRmForce && !confirmDeletion("file "+orgRmFile) {
+			fmt.Println("Deletion cancelled")
+			return
 		}
 
 		err := c.DeleteOrganizationFile(orgRmFile)
@@ -54,7 +51,44 @@ var orgDeleteCmd = &cobra.Command{
 	},
 }
 
+// deletePickedOrganizationFiles deletes the organization files picked by the
+// user, after confirmation unless --force.
+func deletePickedOrganizationFiles() {
+	files := pickOrganizationFiles("Select the files to delete", true)
+	if !orgRmForce && !confirmDeletion(fmt.Sprintf("%d file(s)", len(files))) {
+		fmt.Println("Deletion cancelled")
+		return
+	}
+
+	failed := 0
+	for _, f := range files {
+		if err := c.DeleteOrganizationFile(f.ID); err != nil {
+			fmt.Fprintf(os.Stderr, "Error deleting %s (%s): %s\n", f.Filename, f.ID, err)
+			failed++
+			continue
+		}
+		fmt.Printf("%s (%s) deleted\n", f.Filename, f.ID)
+	}
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// confirmDeletion asks the user to confirm the deletion of what.
+func confirmDeletion(what string) bool {
+	fmt.Printf("Are you sure you want to delete %s? (y/N): ", what)
+	reader := bufio.NewReader(os.Stdin)
+	response, err := reader.ReadString('\n')
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading input: %s\n", err)
+		os.Exit(1)
+	}
+
+	response = strings.ToLower(strings.TrimSpace(response))
+	return response == "y" || response == "yes"
+}
+
 func init() {
-	orgDeleteCmd.Flags().StringVarP(&orgRmFile, "input", "i", "", "file ID to delete (required)")
+	orgDeleteCmd.Flags().StringVarP(&orgRmFile, "input", "i", "", "file ID to delete")
 	orgDeleteCmd.Flags().BoolVarP(&orgRmForce, "force", "f", false, "skip confirmation")
 }
@@ -1,16 +1,17 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
+	"strconv"
 
+	"github.com/ephemeralfiles/eph/pkg/dto"
 	"github.com/ephemeralfiles/eph/pkg/ephcli"
+	"github.com/ephemeralfiles/eph/pkg/render"
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v2"
 )
 
-var orgStatsFormat string
+var orgStatsOutput outputFlags
 
 // orgStatsCmd represents the organization stats command.
 var orgStatsCmd = &cobra.Command{
@@ -33,33 +34,20 @@ var orgStatsCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		switch orgStatsFormat {
-		case "json":
-			output, err := json.MarshalIndent(stats, "", "  ")
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error encoding JSON: %s\n", err)
-				os.Exit(1)
-			}
-			fmt.Println(string(output))
-		case "yaml":
-			output, err := yaml.Marshal(stats)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error encoding YAML: %s\n", err)
-				os.Exit(1)
-			}
-			fmt.Print(string(output))
-		default:
-			// Table format
-			fmt.Printf("Organization ID:  %s\n", stats.OrganizationID)
-			fmt.Printf("Files:            %d\n", stats.FileCount)
-			fmt.Printf("Active:           %d\n", stats.ActiveFiles)
-			fmt.Printf("Expired:          %d\n", stats.ExpiredFiles)
-			fmt.Printf("Total Size:       %.2f GB\n", stats.TotalSizeGB)
-			fmt.Printf("Members:          %d\n", stats.MemberCount)
-		}
+		printItem(&orgStatsOutput, stats, organizationStatsColumns)
 	},
 }
 
+// organizationStatsColumns are the fields of the statistics of an organization.
+var organizationStatsColumns = []render.Column[*dto.OrganizationStats]{
+	{Name: "Organization ID", Value: func(s *dto.OrganizationStats) string { return s.OrganizationID }},
+	{Name: "Files", Value: func(s *dto.OrganizationStats) string { return strconv.FormatInt(s.FileCount, 10) }},
+	{Name: "Active", Value: func(s *dto.OrganizationStats) string { return strconv.FormatInt(s.ActiveFiles, 10) }},
+	{Name: "Expired", Value: func(s *dto.OrganizationStats) string { return strconv.FormatInt(s.ExpiredFiles, 10) }},
+	{Name: "Total Size", Value: func(s *dto.OrganizationStats) string { return fmt.Sprintf("%.2f GB", s.TotalSizeGB) }},
+	{Name: "Members", Value: func(s *dto.OrganizationStats) string { return strconv.FormatInt(s.MemberCount, 10) }},
+}
+
 func init() {
-	orgStatsCmd.Flags().StringVarP(&orgStatsFormat, "format", "r", "table", "output format: table, json, yaml")
+	addOutputFlags(orgStatsCmd, &orgStatsOutput, "format")
 }
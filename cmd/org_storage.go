@@ -1,20 +1,20 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 
+	"github.com/ephemeralfiles/eph/pkg/dto"
 	"github.com/ephemeralfiles/eph/pkg/ephcli"
+	"github.com/ephemeralfiles/eph/pkg/render"
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v2"
 )
 
 const (
 	warningThreshold = 90.0
 )
 
-var orgStorageFormat string
+var orgStorageOutput outputFlags
 
 // orgStorageCmd represents the organization storage command.
 var orgStorageCmd = &cobra.Command{
@@ -37,40 +37,36 @@ var orgStorageCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		switch orgStorageFormat {
-		case renderFormatJSON:
-			output, err := json.MarshalIndent(storage, "", "  ")
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error encoding JSON: %s\n", err)
-				os.Exit(1)
-			}
-			fmt.Println(string(output))
-		case renderFormatYAML:
-			output, err := yaml.Marshal(storage)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error encoding YAML: %s\n", err)
-				os.Exit(1)
-			}
-			fmt.Print(string(output))
-		default:
-			// Table format
-			fmt.Printf("Organization: %s\n", storage.Name)
-			fmt.Printf("Storage Limit:    %.2f GB\n", storage.StorageLimitGB)
-			fmt.Printf("Used:             %.2f GB\n", storage.UsedStorageGB)
-			fmt.Printf("Available:        %.2f GB\n", storage.StorageLimitGB-storage.UsedStorageGB)
-			fmt.Printf("Usage:            %.1f%%\n", storage.UsagePercent)
-
-			status := "Normal"
-			if storage.IsFull {
-				status = "Full"
-			} else if storage.UsagePercent > warningThreshold {
-				status = "Warning"
-			}
-			fmt.Printf("Status:           %s\n", status)
-		}
+		printItem(&orgStorageOutput, storage, organizationStorageColumns)
 	},
 }
 
+// organizationStorageColumns are the fields of the storage of an organization.
+var organizationStorageColumns = []render.Column[*dto.OrganizationStorage]{
+	{Name: "Organization", Value: func(s *dto.OrganizationStorage) string { return s.Name }},
+	{Name: "Storage Limit", Value: func(s *dto.OrganizationStorage) string {
+		return fmt.Sprintf("%.2f GB", s.StorageLimitGB)
+	}},
+	{Name: "Used", Value: func(s *dto.OrganizationStorage) string { return fmt.Sprintf("%.2f GB", s.UsedStorageGB) }},
+	{Name: "Available", Value: func(s *dto.OrganizationStorage) string {
+		return fmt.Sprintf("%.2f GB", s.StorageLimitGB-s.UsedStorageGB)
+	}},
+	{Name: "Usage", Value: func(s *dto.OrganizationStorage) string { return fmt.Sprintf("%.1f%%", s.UsagePercent) }},
+	{Name: "Status", Value: storageStatus},
+}
+
+// storageStatus describes the usage of the storage of an organization.
+func storageStatus(storage *dto.OrganizationStorage) string {
+	switch {
+	case storage.IsFull:
+		return "Full"
+	case storage.UsagePercent > warningThreshold:
+		return "Warning"
+	default:
+		return "Normal"
+	}
+}
+
 func init() {
-	orgStorageCmd.Flags().StringVarP(&orgStorageFormat, "format", "r", "table", "output format: table, json, yaml")
+	addOutputFlags(orgStorageCmd, &orgStorageOutput, "format")
 }
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/ephemeralfiles/eph/pkg/ephcli"
+	"github.com/ephemeralfiles/eph/pkg/orgsync"
+	"github.com/ephemeralfiles/eph/pkg/units"
+	"github.com/spf13/cobra"
+)
+
+var (
+	orgSyncTags        string
+	orgSyncPull        bool
+	orgSyncDelete      bool
+	orgSyncDryRun      bool
+	orgSyncConcurrency int
+)
+
+// orgSyncCmd represents the organization sync command.
+var orgSyncCmd = &cobra.Command{
+	Use:   "sync DIRECTORY",
+	Short: "Synchronize a directory with organization files",
+	Long: `Synchronize a directory with the organization files having the given tags.
+
+By default, the files of the directory and its subdirectories that are new or
+changed are uploaded with the tags. The relative path and the checksum of each
+file are stored in tags of the uploaded file (eph-path:..., eph-checksum:...),
+so that unchanged files are not uploaded again. A changed file is uploaded,
+then its previous version is deleted. With --delete, the synchronized files
+missing from the directory are deleted from the organization, and --delete is
+refused when the directory has no file.
+
+With --pull, the directory mirrors the organization instead: new and changed
+files are downloaded, and with --delete, the local files missing from the
+organization are removed. Only the files downloaded by previous pulls, recorded
+in the .eph-sync.json file of the directory, are removed, and --delete is
+refused when no organization file has the tags. Files that were not uploaded by
+a sync are written under their name.
+
+Use --dry-run to print the plan without applying it.`,
+	Example: `  eph org sync ./reports --tags reports --dry-run
+  eph org sync ./reports --tags reports --delete
+  eph org sync --pull --tags reports ./out`,
+	Args: cobra.ExactArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		InitClient()
+		tags := ephcli.ParseTags(orgSyncTags)
+		if len(tags) == 0 {
+			fmt.Fprintf(os.Stderr, "Error: --tags flag is required\n")
+			os.Exit(1)
+		}
+		configureUploadOptions()
+
+		org := resolveOrganization()
+		syncer := &orgsync.Syncer{
+			Client:         c,
+			OrganizationID: org.ID,
+			Tags:           tags,
+			Dir:            args[0],
+			Pull:           orgSyncPull,
+			Delete:         orgSyncDelete,
+			Clear:          organizationClearTransfer(org),
+		}
+		plan, err := syncer.Plan()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			os.Exit(1)
+		}
+
+		if orgSyncDryRun {
+			printSyncPlan(plan)
+			return
+		}
+
+		failed, err := syncer.Apply(plan, orgSyncConcurrency, func(action orgsync.Action, err error) {
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %s %s: %s\n", syncVerb(action.Op), action.Path, err)
+				return
+			}
+			fmt.Printf("%s %s\n", syncVerb(action.Op), action.Path)
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("%d change(s) applied, %d failed, %d file(s) unchanged\n",
+			len(plan.Actions)-failed, failed, plan.Unchanged)
+		if failed > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+// syncVerb describes the operation of a sync action.
+func syncVerb(op orgsync.Operation) string {
+	switch {
+	case op == orgsync.OpDelete && orgSyncPull:
+		return "remove"
+	case op == orgsync.OpDelete:
+		return "delete"
+	case orgSyncPull:
+		return "download"
+	default:
+		return "upload"
+	}
+}
+
+// printSyncPlan prints the actions of a sync plan.
+func printSyncPlan(plan *orgsync.Plan) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0) //nolint:mnd // column padding
+	fmt.Fprintln(w, "ACTION\tPATH\tSIZE\tREASON")
+	for _, action := range plan.Actions {
+		// Size of the transferred file, or of the deleted one
+		var size int64
+		if action.Local != nil && (!orgSyncPull || action.Op == orgsync.OpDelete) {
+			size = action.Local.Size
+		} else if action.Remote != nil {
+			size = action.Remote.Size
+		}
+		reason := "new"
+		switch action.Op {
+		case orgsync.OpUpdate:
+			reason = "changed"
+		case orgsync.OpDelete:
+			reason = "missing from source"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", syncVerb(action.Op), action.Path, units.FormatSize(size), reason)
+	}
+	_ = w.Flush()
+	fmt.Printf("%d change(s), %d file(s) unchanged (dry run, nothing changed)\n", len(plan.Actions), plan.Unchanged)
+}
+
+func init() {
+	orgSyncCmd.Flags().StringVar(&orgSyncTags, "tags", "", "comma-separated tags of the synchronized files (required)")
+	orgSyncCmd.Flags().BoolVar(&orgSyncPull, "pull", false, "download the organization files to the directory")
+	orgSyncCmd.Flags().BoolVar(&orgSyncDelete, "delete", false, "delete the files missing from the source")
+	orgSyncCmd.Flags().BoolVar(&orgSyncDryRun, "dry-run", false, "print the plan without applying it")
+	orgSyncCmd.Flags().IntVar(&orgSyncConcurrency, "concurrency", orgsync.DefaultConcurrency,
+		"number of parallel transfers")
+	addOrgTransferFlags(orgSyncCmd, "transfer")
+}
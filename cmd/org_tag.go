@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/ephemeralfiles/eph/pkg/dto"
+	"github.com/ephemeralfiles/eph/pkg/ephcli"
+	"github.com/spf13/cobra"
+)
+
+var (
+	orgTagInput  []string
+	orgTagWhere  string
+	orgTagRename string
+)
+
+// orgTagCmd represents the organization tag command.
+var orgTagCmd = &cobra.Command{
+	Use:   "tag",
+	Short: "Change the tags of organization files",
+	Long: `Change the tags of organization files.
+
+Select files with -i (several IDs can be given, comma-separated or by repeating
+the flag) and/or with --where tags=TAG1,TAG2 (files having all these tags).
+
+Use --rename-tag old=new to rewrite a tag across every file of the organization.`,
+	Example: `  eph org tag add -i FILE_ID invoice,q1
+  eph org tag rm -i FILE_ID1,FILE_ID2 draft
+  eph org tag set --where tags=draft final
+  eph org tag --rename-tag 2024-q1=q1-2024`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		if orgTagRename == "" {
+			_ = cmd.Help()
+			return
+		}
+		oldTag, newTag, err := ephcli.ParseTagRename(orgTagRename)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			os.Exit(1)
+		}
+
+		InitClient()
+		files, err := c.ListAllOrganizationFilesByTags(resolveOrganizationID(), []string{oldTag})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error listing files: %s\n", err)
+			os.Exit(1)
+		}
+		var tagged []dto.OrganizationFile
+		for _, f := range files {
+			if slices.Contains(f.Tags, oldTag) {
+				tagged = append(tagged, f)
+			}
+		}
+		updateOrganizationFileTags(tagged, func(current []string) []string {
+			return ephcli.RenameTag(current, oldTag, newTag)
+		})
+	},
+}
+
+// newOrgTagOperationCmd creates the subcommand applying op to the selected files.
+func newOrgTagOperationCmd(use, short string, op ephcli.TagOperation) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   use + " TAG1[,TAG2...]",
+		Short: short,
+		Args:  cobra.ExactArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			tags := ephcli.ParseTags(args[0])
+			if len(tags) == 0 && op != ephcli.TagSet {
+				fmt.Fprintf(os.Stderr, "Error: no tag given\n")
+				os.Exit(1)
+			}
+			if len(orgTagInput) == 0 && orgTagWhere == "" {
+				fmt.Fprintf(os.Stderr, "Error: --input or --where flag is required\n")
+				os.Exit(1)
+			}
+
+			InitClient()
+			files, err := selectOrganizationFilesForTags(resolveOrganizationID())
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+				os.Exit(1)
+			}
+			updateOrganizationFileTags(files, func(current []string) []string {
+				return ephcli.ApplyTagOperation(current, op, tags)
+			})
+		},
+	}
+	cmd.Flags().StringSliceVarP(&orgTagInput, "input", "i", nil, "file IDs to update")
+	cmd.Flags().StringVar(&orgTagWhere, "where", "", "select the files having all the given tags (tags=TAG1,TAG2)")
+	return cmd
+}
+
+// selectOrganizationFilesForTags returns the files of the organization orgID
+// matching the --input and --where flags. Files given by ID are looked up one by
+// one; otherwise the files having the tags of --where are listed.
+func selectOrganizationFilesForTags(orgID string) ([]dto.OrganizationFile, error) {
+	var whereTags []string
+	if orgTagWhere != "" {
+		tags, err := ephcli.ParseTagWhere(orgTagWhere)
+		if err != nil {
+			return nil, fmt.Errorf("--where: %w", err)
+		}
+		whereTags = tags
+	}
+
+	var candidates []dto.OrganizationFile
+	if len(orgTagInput) > 0 {
+		var listed []dto.OrganizationFile
+		for _, id := range orgTagInput {
+			f, err := lookupOrganizationFile(orgID, strings.TrimSpace(id), &listed)
+			if err != nil {
+				return nil, err
+			}
+			candidates = append(candidates, *f)
+		}
+	} else {
+		files, err := c.ListAllOrganizationFilesByTags(orgID, whereTags)
+		if err != nil {
+			return nil, fmt.Errorf("error listing files: %w", err)
+		}
+		candidates = files
+	}
+
+	var selected []dto.OrganizationFile
+	for _, f := range candidates {
+		if hasAllTags(f.Tags, whereTags) {
+			selected = append(selected, f)
+		}
+	}
+	return selected, nil
+}
+
+// lookupOrganizationFile returns the file id of the organization orgID from its
+// information. Servers that do not report the organization of a file do not
+// report its tags either: the file is then searched in the files of the
+// organization, listed once in listed.
+func lookupOrganizationFile(orgID, id string, listed *[]dto.OrganizationFile) (*dto.OrganizationFile, error) {
+	info, err := c.GetFileInfo(id)
+	if err != nil {
+		return nil, fmt.Errorf("error getting file %s: %w", id, err)
+	}
+	if info.OrganizationID != "" {
+		if info.OrganizationID != orgID {
+			return nil, fmt.Errorf("%w in organization: %s", ephcli.ErrFileNotFound, id)
+		}
+		return &dto.OrganizationFile{
+			ID: id, Filename: info.Filename, Size: info.Size, OrganizationID: info.OrganizationID,
+			Tags: info.Tags, OwnerID: info.OwnerID, OwnerEmail: info.OwnerEmail,
+		}, nil
+	}
+
+	if *listed == nil {
+		files, err := c.ListAllOrganizationFiles(orgID)
+		if err != nil {
+			return nil, fmt.Errorf("error listing files: %w", err)
+		}
+		*listed = append([]dto.OrganizationFile{}, files...)
+	}
+	for _, f := range *listed {
+		if f.ID == id {
+			return &f, nil
+		}
+	}
+	return nil, fmt.Errorf("%w in organization: %s", ephcli.ErrFileNotFound, id)
+}
+
+// hasAllTags reports whether tags contains every tag of wanted.
+func hasAllTags(tags, wanted []string) bool {
+	for _, tag := range wanted {
+		if !slices.Contains(tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// updateOrganizationFileTags applies change to the tags of files, skipping unchanged files.
+func updateOrganizationFileTags(files []dto.OrganizationFile, change func([]string) []string) {
+	updated, failed := 0, 0
+	for _, f := range files {
+		tags := change(f.Tags)
+		if ephcli.SameTags(tags, f.Tags) {
+			continue
+		}
+		if _, err := c.UpdateFileTags(f.ID, tags); err != nil {
+			fmt.Fprintf(os.Stderr, "Error updating tags of %s: %s\n", f.ID, err)
+			failed++
+			continue
+		}
+		updated++
+		fmt.Printf("%s (%s): %s\n", f.ID, f.Filename, strings.Join(tags, ", "))
+	}
+
+	fmt.Printf("%d file(s) updated\n", updated)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func init() {
+	orgTagCmd.Flags().StringVar(&orgTagRename, "rename-tag", "", "rename a tag across every file of the organization (old=new)")
+
+	orgTagCmd.AddCommand(newOrgTagOperationCmd("add", "Add tags to files", ephcli.TagAdd))
+	orgTagCmd.AddCommand(newOrgTagOperationCmd("rm", "Remove tags from files", ephcli.TagRemove))
+	orgTagCmd.AddCommand(newOrgTagOperationCmd("set", "Replace the tags of files", ephcli.TagSet))
+}
@@ -1,15 +1,14 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"strconv"
 
+	"github.com/ephemeralfiles/eph/pkg/dto"
 	"github.com/ephemeralfiles/eph/pkg/ephcli"
-	"github.com/pterm/pterm"
+	"github.com/ephemeralfiles/eph/pkg/render"
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v2"
 )
 
 const (
@@ -17,7 +16,7 @@ const (
 )
 
 var (
-	orgTagsFormat string
+	orgTagsOutput outputFlags
 	orgTagsLimit  int
 )
 
@@ -42,43 +41,17 @@ var orgTagsCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		if len(tags) == 0 {
-			fmt.Println("No tags found")
-			return
-		}
-
-		switch orgTagsFormat {
-		case renderFormatJSON:
-			output, err := json.MarshalIndent(tags, "", "  ")
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error encoding JSON: %s\n", err)
-				os.Exit(1)
-			}
-			fmt.Println(string(output))
-		case renderFormatYAML:
-			output, err := yaml.Marshal(tags)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error encoding YAML: %s\n", err)
-				os.Exit(1)
-			}
-			fmt.Print(string(output))
-		default:
-			// Table format
-			tableData := pterm.TableData{
-				{"TAG", "COUNT"},
-			}
-			for _, tag := range tags {
-				tableData = append(tableData, []string{
-					tag.Tag,
-					strconv.FormatInt(tag.Count, 10),
-				})
-			}
-			_ = pterm.DefaultTable.WithHasHeader().WithData(tableData).Render()
-		}
+		printList(&orgTagsOutput, tags, tagColumns, "No tags found")
 	},
 }
 
+// tagColumns are the columns of the popular tags of an organization.
+var tagColumns = []render.Column[dto.TagCount]{
+	{Name: "Tag", Value: func(t dto.TagCount) string { return t.Tag }},
+	{Name: "Count", Value: func(t dto.TagCount) string { return strconv.FormatInt(t.Count, 10) }},
+}
+
 func init() {
-	orgTagsCmd.Flags().StringVarP(&orgTagsFormat, "format", "r", renderFormatTable, "output format: table, json, yaml")
+	addOutputFlags(orgTagsCmd, &orgTagsOutput, "format")
 	orgTagsCmd.Flags().IntVar(&orgTagsLimit, "limit", defaultTagsLimit, "maximum number of tags (max 100)")
 }
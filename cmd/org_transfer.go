@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ephemeralfiles/eph/pkg/config"
+	"github.com/ephemeralfiles/eph/pkg/dto"
+	"github.com/ephemeralfiles/eph/pkg/ephcli"
+	"github.com/spf13/cobra"
+)
+
+var orgTransferReset bool
+
+// orgTransferCmd represents the organization transfer command.
+var orgTransferCmd = &cobra.Command{
+	Use:   "transfer [e2e|clear]",
+	Short: "Show or set the default transfer mode of an organization",
+	Long: `Show or set the default transfer mode of an organization.
+
+Files of organizations are transferred with end-to-end encryption (e2e) by
+default. Set the mode to clear to upload and download the files of an
+organization without encryption; --e2e and --clear on 'eph org up' and
+'eph org dl' override the mode. The mode is saved in the configuration.`,
+	Example: `  eph org transfer --org eph1 clear
+  eph org transfer --org eph1
+  eph org transfer --org eph1 --reset`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		InitClient()
+		if orgTransferReset && len(args) > 0 {
+			fmt.Fprintf(os.Stderr, "Error: --reset takes no mode\n")
+			os.Exit(1)
+		}
+		org := resolveOrganization()
+
+		if len(args) == 0 && !orgTransferReset {
+			fmt.Printf("Transfer mode of '%s': %s\n", org.Name, cfg.OrganizationTransfer(org.Name))
+			return
+		}
+
+		mode := ""
+		if len(args) > 0 {
+			mode = args[0]
+		}
+		if err := cfg.SetOrganizationTransfer(org.Name, mode); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			os.Exit(1)
+		}
+		resolvedConfigPath := config.ResolveConfigPath(configurationFile)
+		if err := cfg.SaveConfiguration(resolvedConfigPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error saving configuration: %s\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Transfer mode of '%s' set to %s\n", org.Name, cfg.OrganizationTransfer(org.Name))
+	},
+}
+
+// addOrgTransferFlags registers the --clear and --e2e flags of organization
+// transfers; action is "upload" or "download".
+func addOrgTransferFlags(cmd *cobra.Command, action string) {
+	cmd.Flags().BoolVar(&clearTransfer, "clear", false, action+" without encryption")
+	cmd.Flags().BoolVar(&e2eTransfer, "e2e", false,
+		action+" with end-to-end encryption, even if the organization defaults to clear")
+	cmd.MarkFlagsMutuallyExclusive("clear", "e2e")
+}
+
+// organizationClearTransfer reports whether the files of org are transferred
+// without encryption: with --clear, or when the organization defaults to clear
+// and --e2e is not set.
+func organizationClearTransfer(org *dto.Organization) bool {
+	switch {
+	case clearTransfer:
+		return true
+	case e2eTransfer:
+		return false
+	default:
+		return cfg.OrganizationTransfer(org.Name) == dto.EncryptionModeClear
+	}
+}
+
+// downloadClearTransfer is organizationClearTransfer for downloads by file ID,
+// which do not need the organization: it is only resolved when an organization
+// of the configuration defaults to clear.
+func downloadClearTransfer() bool {
+	if clearTransfer || e2eTransfer || len(cfg.Organizations) == 0 {
+		return clearTransfer
+	}
+	org, err := ephcli.NewOrgContext(c, cfg).ResolveOrganization(orgName, orgID)
+	if errors.Is(err, ephcli.ErrNoOrganizationSpecified) {
+		return false
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+	return organizationClearTransfer(org)
+}
+
+func init() {
+	orgTransferCmd.Flags().BoolVar(&orgTransferReset, "reset", false, "remove the transfer mode of the organization")
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/ephemeralfiles/eph/pkg/ephcli"
 	"github.com/spf13/cobra"
@@ -18,14 +19,29 @@ var (
 var orgUploadCmd = &cobra.Command{
 	Use:   "up",
 	Short: "Upload file to organization",
-	Long:  `Upload a file to an organization with optional tags.`,
+	Long: `Upload a file to an organization with optional tags.
+
+Files are uploaded with end-to-end encryption, in chunks of 128MB by default.
+Use --chunk-size or --adaptive-chunks to change the size of the chunks, and
+--expires to replace the retention of the organization (24h, 7d, 2026-12-31).
+
+Use --clear to upload without encryption. The default of an organization can
+be set to clear with 'eph org transfer clear'; --e2e overrides it.
+
+Only the base name of the file is sent, unless --name or --keep-path is set.
+
+Use --from-url instead of --input to upload the body of an http(s) URL, streamed
+to the encrypted upload without being written to disk. The response must have a
+Content-Length. URL uploads are always encrypted.`,
 	Run: func(_ *cobra.Command, _ []string) {
 		InitClient()
 
-		if orgUploadFile == "" {
-			fmt.Fprintf(os.Stderr, "Error: --input flag is required\n")
+		if orgUploadFile == "" && uploadFromURL == "" {
+			fmt.Fprintf(os.Stderr, "Error: --input or --from-url flag is required\n")
 			os.Exit(1)
 		}
+		configureUploadOptions()
+		expiresAt := uploadExpiration()
 
 		orgCtx := ephcli.NewOrgContext(c, cfg)
 		org, err := orgCtx.ResolveOrganization(orgName, orgID)
@@ -44,23 +60,51 @@ var orgUploadCmd = &cobra.Command{
 			}
 		}
 
-		// Upload file with E2E encryption
-		fileID, err := c.UploadOrganizationFileE2E(org.ID, orgUploadFile, tags)
+		opts := ephcli.UploadOptions{
+			OrganizationID: org.ID,
+			Tags:           tags,
+			ExpiresAt:      expiresAt,
+			Name:           uploadName,
+			KeepPath:       uploadKeepPath,
+		}
+		// Use encrypted upload unless --clear is set or the organization defaults to clear
+		clearUpload := uploadFromURL == "" && organizationClearTransfer(org)
+		var result *ephcli.UploadResult
+		switch {
+		case uploadFromURL != "":
+			result, err = c.UploadE2EFromURL(uploadFromURL, opts)
+		case clearUpload:
+			result, err = c.UploadWithOptions(orgUploadFile, opts)
+		default:
+			result, err = c.UploadE2EWithOptions(orgUploadFile, opts)
+		}
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error uploading file: %s\n", err)
 			os.Exit(1)
 		}
 
-		fmt.Printf("File uploaded successfully with E2E encryption\n")
-		fmt.Printf("File ID: %s\n", fileID)
+		if clearUpload {
+			fmt.Printf("File uploaded successfully without encryption\n")
+		} else {
+			fmt.Printf("File uploaded successfully with E2E encryption\n")
+		}
+		fmt.Printf("File ID: %s\n", result.FileID)
 		if len(tags) > 0 {
 			fmt.Printf("Tags: %s\n", strings.Join(tags, ", "))
 		}
+		if !result.ExpiresAt.IsZero() {
+			fmt.Printf("Expires: %s\n", result.ExpiresAt.Local().Format(time.DateTime))
+		}
 	},
 }
 
 func init() {
 	orgUploadCmd.Flags().StringVarP(&orgUploadFile, "input", "i", "", "file to upload (required)")
 	orgUploadCmd.Flags().StringVar(&orgUploadTags, "tags", "", "comma-separated tags")
+	orgUploadCmd.Flags().StringVar(&uploadFromURL, "from-url", "", "upload the body of an http(s) URL instead of a file")
+	orgUploadCmd.MarkFlagsMutuallyExclusive("input", "from-url")
+	addOrgTransferFlags(orgUploadCmd, "upload")
+	orgUploadCmd.MarkFlagsMutuallyExclusive("clear", "from-url")
 	orgUploadCmd.Flags().BoolVarP(&noProgressBar, "no-progress-bar", "n", false, "disable progress bar")
+	addUploadOptionFlags(orgUploadCmd)
 }
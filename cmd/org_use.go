@@ -1,10 +1,14 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
+	"github.com/ephemeralfiles/eph/pkg/cmdutil"
 	"github.com/ephemeralfiles/eph/pkg/config"
+	"github.com/ephemeralfiles/eph/pkg/dto"
+	"github.com/ephemeralfiles/eph/pkg/ephcli"
 	"github.com/spf13/cobra"
 )
 
@@ -14,7 +18,10 @@ var clearDefault bool
 var orgUseCmd = &cobra.Command{
 	Use:   "use [organization-name]",
 	Short: "Set default organization",
-	Long:  `Set the default organization context for subsequent commands.`,
+	Long: `Set the default organization context for subsequent commands.
+
+On a terminal, the organization is picked from a list with fuzzy search when
+no name is given, or when no organization has exactly this name.`,
 	Run: func(_ *cobra.Command, args []string) {
 		InitClient()
 
@@ -29,19 +36,30 @@ var orgUseCmd = &cobra.Command{
 			return
 		}
 
-		if len(args) == 0 {
+		if len(args) == 0 && !cmdutil.IsInteractive() {
 			fmt.Fprintf(os.Stderr, "Error: organization name required\n")
 			fmt.Fprintf(os.Stderr, "Usage: eph org use <organization-name>\n")
 			os.Exit(1)
 		}
 
-		orgName := args[0]
+		var org *dto.Organization
+		if len(args) == 0 {
+			picked := pickOrganization("")
+			org = &picked
+		} else {
+			orgName := args[0]
 
-		// Verify organization exists
-		org, err := c.GetOrganizationByName(orgName)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error finding organization '%s': %s\n", orgName, err)
-			os.Exit(1)
+			// Verify organization exists, or let the user pick the closest ones
+			var err error
+			org, err = c.GetOrganizationByName(orgName)
+			if errors.Is(err, ephcli.ErrOrganizationNotFound) && cmdutil.IsInteractive() {
+				picked := pickOrganization(orgName)
+				org, err = &picked, nil
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error finding organization '%s': %s\n", orgName, err)
+				os.Exit(1)
+			}
 		}
 
 		cfg.DefaultOrganization = org.Name
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/ephemeralfiles/eph/pkg/cmdutil"
+	"github.com/ephemeralfiles/eph/pkg/render"
+	"github.com/spf13/cobra"
+)
+
+// outputFlags are the --output, --columns and --allow-missing flags of a
+// command printing DTOs.
+type outputFlags struct {
+	format       string
+	columns      []string
+	allowMissing bool
+	// custom are the formats printed by the command itself, such as id-only.
+	custom []string
+}
+
+// addOutputFlags registers the --output (-o), --columns and --allow-missing
+// flags of cmd. When legacy is set, the -r flag of that name, which selected the
+// format before --output, is kept as a deprecated alias.
+func addOutputFlags(cmd *cobra.Command, o *outputFlags, legacy string) {
+	usage := "output format: " + strings.Join(slices.Concat(render.Formats, o.custom), ", ") +
+		", go-template=TEMPLATE, go-template-file=PATH, jsonpath=TEMPLATE or jsonpath-file=PATH"
+	cmd.Flags().StringVarP(&o.format, "output", "o", render.FormatTable, usage)
+	cmd.Flags().StringSliceVar(&o.columns, "columns", nil,
+		"comma-separated columns of the table, csv and markdown formats")
+	cmd.Flags().BoolVar(&o.allowMissing, "allow-missing", false,
+		"print nothing for a field or an index a jsonpath output does not find, instead of failing")
+	if legacy != "" {
+		cmd.Flags().StringVarP(&o.format, legacy, "r", render.FormatTable, usage)
+		_ = cmd.Flags().MarkDeprecated(legacy, "use --output instead")
+	}
+	// Reject an unknown format before contacting the server, keeping the
+	// existing hook of cmd (cobra ignores PreRun when PreRunE is set)
+	if preRunE := cmd.PreRunE; preRunE != nil {
+		cmd.PreRunE = func(cmd *cobra.Command, args []string) error {
+			o.options()
+			return preRunE(cmd, args)
+		}
+		return
+	}
+	preRun := cmd.PreRun
+	cmd.PreRun = func(cmd *cobra.Command, args []string) {
+		o.options()
+		if preRun != nil {
+			preRun(cmd, args)
+		}
+	}
+}
+
+// options returns the render options of the flags, exiting on an unknown format.
+func (o *outputFlags) options() render.Options {
+	opts := render.Options{Format: o.format, Columns: o.columns, AllowMissing: o.allowMissing}
+	if err := opts.Validate(); err != nil && !slices.Contains(o.custom, o.format) {
+		cmdutil.HandleError("Error", err)
+	}
+	return opts
+}
+
+// printList prints items with the --output and --columns flags, or empty when
+// there is no item and the output is a table.
+func printList[T any](o *outputFlags, items []T, columns []render.Column[T], empty string) {
+	opts := o.options()
+	if len(items) == 0 && opts.IsTable() {
+		fmt.Println(empty)
+		return
+	}
+	if err := render.List(os.Stdout, items, columns, opts); err != nil {
+		cmdutil.HandleError("Error rendering output", err)
+	}
+}
+
+// printItem prints item with the --output and --columns flags.
+func printItem[T any](o *outputFlags, item T, columns []render.Column[T]) {
+	if err := render.Item(os.Stdout, item, columns, o.options()); err != nil {
+		cmdutil.HandleError("Error rendering output", err)
+	}
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ephemeralfiles/eph/pkg/dto"
+	"github.com/ephemeralfiles/eph/pkg/tui"
+	"github.com/ephemeralfiles/eph/pkg/units"
+)
+
+// pick lets the user select items with a fuzzy search picker, one or several
+// with multiple, and exits when the user cancels. query is the initial search.
+func pick[T any](title string, items []T, label func(T) string, multiple bool, query string) []T {
+	if len(items) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: nothing to select")
+		os.Exit(1)
+	}
+	labels := make([]string, 0, len(items))
+	for _, item := range items {
+		labels = append(labels, label(item))
+	}
+	indexes, err := tui.Pick(title, labels, multiple, query)
+	if errors.Is(err, tui.ErrPickerCancelled) {
+		fmt.Fprintln(os.Stderr, "Selection cancelled")
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+	selected := make([]T, 0, len(indexes))
+	for _, i := range indexes {
+		selected = append(selected, items[i])
+	}
+	return selected
+}
+
+// pickFiles lets the user select personal files.
+func pickFiles(title string, multiple bool) []dto.File {
+	files, err := c.Fetch()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error listing files: %s\n", err)
+		os.Exit(1)
+	}
+	return pick(title, files, func(f dto.File) string {
+		return fmt.Sprintf("%s  %s  %s", f.FileName, units.FormatSize(f.Size), f.FileID)
+	}, multiple, "")
+}
+
+// pickOrganizationFiles lets the user select files of the organization of the
+// --org and --org-id flags, or of the default organization.
+func pickOrganizationFiles(title string, multiple bool) []dto.OrganizationFile {
+	files, err := c.ListAllOrganizationFiles(resolveOrganizationID())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error listing files: %s\n", err)
+		os.Exit(1)
+	}
+	return pick(title, files, func(f dto.OrganizationFile) string {
+		label := fmt.Sprintf("%s  %s  %s", f.Filename, units.FormatSize(f.Size), f.ID)
+		if len(f.Tags) > 0 {
+			label += "  [" + strings.Join(f.Tags, ", ") + "]"
+		}
+		return label
+	}, multiple, "")
+}
+
+// pickOrganization lets the user select an organization, searching for query
+// first.
+func pickOrganization(query string) dto.Organization {
+	orgs, err := c.ListOrganizations()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error listing organizations: %s\n", err)
+		os.Exit(1)
+	}
+	return pick("Select an organization", orgs, func(org dto.Organization) string {
+		return fmt.Sprintf("%s  %s", org.Name, org.UserRole)
+	}, false, query)[0]
+}
@@ -4,18 +4,29 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/ephemeralfiles/eph/pkg/cmdutil"
 	"github.com/spf13/cobra"
 )
 
+var removeForce bool
+
 // removeCmd represents the remove command.
 var removeCmd = &cobra.Command{
 	Use:   "rm",
 	Short: "remove file from ephemeralfiles",
 	Long: `remove file from ephemeralfiles.
 The uuid is required.
+
+Without --input on a terminal, the files are picked from a list of your files
+with fuzzy search: tab selects several files. Their deletion is confirmed
+unless --force is set.
 `,
 	Run: func(cmd *cobra.Command, _ []string) {
 		InitClient()
+		if uuidFile == "" && cmdutil.IsInteractive() {
+			removePickedFiles()
+			return
+		}
 		if uuidFile == "" {
 			fmt.Fprintf(os.Stderr, "uuid is required\n")
 			_ = cmd.Usage()
@@ -29,3 +40,26 @@ The uuid is required.
 		}
 	},
 }
+
+// removePickedFiles removes the files picked by the user, after confirmation
+// unless --force.
+func removePickedFiles() {
+	files := pickFiles("Select the files to remove", true)
+	if !removeForce && !confirmDeletion(fmt.Sprintf("%d file(s)", len(files))) {
+		fmt.Println("Deletion cancelled")
+		return
+	}
+
+	failed := 0
+	for _, f := range files {
+		if err := c.Remove(f.FileID); err != nil {
+			fmt.Fprintf(os.Stderr, "Error removing %s (%s): %s\n", f.FileName, f.FileID, err)
+			failed++
+			continue
+		}
+		fmt.Printf("%s (%s) removed\n", f.FileName, f.FileID)
+	}
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/ephemeralfiles/eph/pkg/config"
 	"github.com/ephemeralfiles/eph/pkg/ephcli"
+	"github.com/ephemeralfiles/eph/pkg/ratelimit"
 	"github.com/spf13/cobra"
 )
 
@@ -27,11 +28,39 @@ var (
 	fileToUpload string
 	uuidFile     string
 	outputFile   string
+	downloadLast bool
 
-	renderingType string
+	// Download option flags.
+	outputDir         string
+	overwriteExisting bool
+	skipExisting      bool
+	renameExisting    bool
+	resumeDownload    bool
 
-	// Transfer method flag.
+	// Transfer method flags. --e2e overrides the clear transfer mode of an organization.
 	clearTransfer bool
+	e2eTransfer   bool
+
+	// Bandwidth limit flag, overriding the limit of the configuration.
+	limitRate string
+
+	// Upload chunking flags.
+	chunkSize      string
+	adaptiveChunks bool
+
+	// Expiration flags of uploads and of the expire command.
+	uploadExpires string
+	expireIn      string
+	expireAt      string
+
+	// Remote name flags of uploads.
+	uploadName     string
+	uploadKeepPath bool
+
+	// Source URL of uploads fetched over HTTP instead of read from a file.
+	uploadFromURL string
+	// Output format of the uploaded file.
+	uploadOutput = outputFlags{custom: []string{renderFormatIDOnly}}
 
 	cfg *config.Config
 	c   *ephcli.ClientEphemeralfiles
@@ -66,20 +95,40 @@ func init() {
 		config.DefaultConfigFilePath(), "configuration name or file path (e.g., 'production' or '/path/to/config.yml')")
 	// -d option to enable debug mode
 	rootCmd.PersistentFlags().BoolVarP(&debugMode, "debug", "d", false, "enable debug mode (disable progress bar)")
+	// --limit-rate option to cap the bandwidth of transfers
+	rootCmd.PersistentFlags().StringVar(&limitRate, "limit-rate", "",
+		"maximum transfer rate in bytes per second, e.g. 500K or 5M (overrides the configuration)")
 
 	// upload subcommand parameters
 	uploadCmd.PersistentFlags().StringVarP(&fileToUpload, "input", "i", "", "file to upload")
 	uploadCmd.PersistentFlags().BoolVarP(&noProgressBar, "no-progress-bar", "n", false, "disable progress bar")
 	uploadCmd.PersistentFlags().BoolVar(&clearTransfer, "clear", false, "upload without encryption")
+	uploadCmd.PersistentFlags().StringVar(&uploadFromURL, "from-url", "", "upload the body of an http(s) URL instead of a file")
+	uploadCmd.MarkFlagsMutuallyExclusive("input", "from-url")
+	uploadCmd.MarkFlagsMutuallyExclusive("clear", "from-url")
+	addOutputFlags(uploadCmd, &uploadOutput, "")
+	addUploadOptionFlags(uploadCmd)
 	// download subcommand parameters
 	downloadCmd.PersistentFlags().StringVarP(&uuidFile, "input", "i", "", "uuid of file to download")
 	downloadCmd.PersistentFlags().StringVarP(&outputFile, "output", "o", "", "output file path (optional)")
 	downloadCmd.PersistentFlags().BoolVarP(&noProgressBar, "no-progress-bar", "n", false, "disable progress bar")
 	downloadCmd.PersistentFlags().BoolVar(&clearTransfer, "clear", false, "download without encryption")
+	downloadCmd.PersistentFlags().BoolVar(&downloadLast, "last", false, "download the most recent upload of the history")
+	downloadCmd.MarkFlagsMutuallyExclusive("input", "last")
+	downloadCmd.MarkFlagsMutuallyExclusive("clear", "last")
+	addDownloadOptionFlags(downloadCmd)
 	// list subcommand parameters
-	listCmd.PersistentFlags().StringVarP(&renderingType, "rendering", "r", "table", "rendering type (table, json, csv)")
+	addOutputFlags(listCmd, &listOutput, "rendering")
+	addListingFlags(listCmd, &listListing)
 	// remove subcommand parameters
 	removeCmd.PersistentFlags().StringVarP(&uuidFile, "input", "i", "", "uuid of file to download")
+	removeCmd.PersistentFlags().BoolVarP(&removeForce, "force", "f", false, "skip confirmation of the picked files")
+	// expire subcommand parameters
+	expireCmd.PersistentFlags().StringVarP(&uuidFile, "input", "i", "", "uuid of file to update")
+	expireCmd.PersistentFlags().StringVar(&expireIn, "in", "", "expire the file after this duration from now, e.g. 24h or 7d")
+	expireCmd.PersistentFlags().StringVar(&expireAt, "at", "", "expire the file at this date, e.g. 2026-12-31")
+	expireCmd.MarkFlagsMutuallyExclusive("in", "at")
+	mvCmd.PersistentFlags().StringVarP(&uuidFile, "input", "i", "", "uuid of file to rename")
 	// config subcommand parameters
 	configCmd.PersistentFlags().StringVarP(&token, "token", "t", "", "ephemeralfiles token")
 	configCmd.PersistentFlags().StringVarP(&endpoint, "endpoint", "e", "", "ephemeralfiles endpoint")
@@ -88,6 +137,15 @@ func init() {
 	// add subcommands
 	rootCmd.AddCommand(downloadCmd)
 	rootCmd.AddCommand(removeCmd)
+	rootCmd.AddCommand(expireCmd)
+	rootCmd.AddCommand(mvCmd)
+	rootCmd.AddCommand(batchCmd)
+	rootCmd.AddCommand(watchCmd)
+	rootCmd.AddCommand(uiCmd)
+	rootCmd.AddCommand(historyCmd)
+	rootCmd.AddCommand(shareCmd)
+	rootCmd.AddCommand(getCmd)
+	rootCmd.AddCommand(infoCmd)
 	rootCmd.AddCommand(purgeCmd)
 	rootCmd.AddCommand(uploadCmd)
 	rootCmd.AddCommand(listCmd)
@@ -117,4 +175,40 @@ func InitClient() {
 	if debugMode {
 		c.SetDebug()
 	}
+	configureRateLimit()
+	configureHistory()
+}
+
+// InitAnonymousClient initializes a client without token for endpoint, used to
+// download shared links. The configuration is optional and only provides the
+// transfer settings.
+func InitAnonymousClient(endpoint string) {
+	cfg = config.NewConfig()
+	// Without a configuration file, the defaults apply
+	_ = cfg.LoadConfigFromFile(config.ResolveConfigPath(configurationFile))
+	c = ephcli.NewClient("")
+	c.SetEndpoint(endpoint)
+	if noProgressBar {
+		c.DisableProgressBar()
+	}
+	if debugMode {
+		c.SetDebug()
+	}
+	configureRateLimit()
+	configureHistory()
+}
+
+// configureRateLimit applies the bandwidth limit of the --limit-rate flag or, when
+// the flag is not set, of the configuration (limit_rate and limit_rate_schedule).
+func configureRateLimit() {
+	rate, schedule := cfg.LimitRate, cfg.LimitRateSchedule
+	if limitRate != "" {
+		rate, schedule = limitRate, nil
+	}
+	limiter, err := ratelimit.Parse(rate, schedule)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error configuring rate limit: %s\n", err)
+		os.Exit(1)
+	}
+	c.SetRateLimiter(limiter)
 }
@@ -0,0 +1,232 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ephemeralfiles/eph/pkg/cmdutil"
+	"github.com/ephemeralfiles/eph/pkg/dto"
+	"github.com/ephemeralfiles/eph/pkg/ephcli"
+	"github.com/ephemeralfiles/eph/pkg/render"
+	"github.com/mdp/qrterminal/v3"
+	"github.com/spf13/cobra"
+)
+
+var (
+	shareInput        string
+	sharePassword     string
+	shareAskPassword  bool
+	shareMaxDownloads int
+	shareExpires      string
+	shareQR           bool
+	shareOutput       outputFlags
+)
+
+var (
+	// shareCmd represents the share command.
+	shareCmd = newShareCmd(false)
+	// orgShareCmd represents the organization share command.
+	orgShareCmd = newShareCmd(true)
+)
+
+// newShareCmd creates the share command and its list and revoke subcommands,
+// for personal files or, when org is true, for the files of an organization.
+func newShareCmd(org bool) *cobra.Command {
+	scope := "a file"
+	example := `  eph share -i FILE_ID
+  eph share -i FILE_ID --ask-password --max-downloads 3 --expires 24h --qr
+  eph share list
+  eph share revoke SHARE_ID`
+	if org {
+		scope = "an organization file"
+		example = strings.ReplaceAll(example, "eph share", "eph org share")
+	}
+
+	cmd := &cobra.Command{
+		Use:   "share",
+		Short: "Create a public download link to " + scope,
+		Long: `Create a public, time-limited download link to ` + scope + `.
+
+The link can be protected by a password (--password, or --ask-password to type
+it), limited to a number of downloads (--max-downloads) and expire before the
+file (--expires, a duration such as 24h or a date such as 2026-12-31).
+The URL is printed, or rendered as a QR code with --qr.`,
+		Example: example,
+		Args:    cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			if shareInput == "" {
+				fmt.Fprintf(os.Stderr, "Error: --input flag is required\n")
+				_ = cmd.Usage()
+				os.Exit(1)
+			}
+			opts := shareOptions()
+
+			InitClient()
+			if org {
+				checkOrganizationFile(shareInput)
+			}
+			link, err := c.CreateShareLink(shareInput, opts)
+			if err != nil {
+				cmdutil.HandleError("Error creating share link", err)
+			}
+			printShareLink(link)
+		},
+	}
+	cmd.Flags().StringVarP(&shareInput, "input", "i", "", "uuid of the file to share (required)")
+	cmd.Flags().StringVar(&sharePassword, "password", "", "password required to download the file")
+	cmd.Flags().BoolVar(&shareAskPassword, "ask-password", false, "prompt for the password required to download the file")
+	cmd.Flags().IntVar(&shareMaxDownloads, "max-downloads", 0, "number of downloads after which the link stops working")
+	cmd.Flags().StringVar(&shareExpires, "expires", "",
+		"expiration of the link, as a duration (24h, 7d) or a date (defaults to the expiration of the file)")
+	cmd.Flags().BoolVar(&shareQR, "qr", false, "render the link as a QR code")
+	cmd.MarkFlagsMutuallyExclusive("password", "ask-password")
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List share links",
+		Args:  cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			InitClient()
+			var (
+				links []dto.ShareLink
+				err   error
+			)
+			if org {
+				links, err = c.ListOrganizationShareLinks(resolveOrganizationID())
+			} else {
+				links, err = c.ListShareLinks()
+			}
+			if err != nil {
+				cmdutil.HandleError("Error listing share links", err)
+			}
+			printList(&shareOutput, links, shareLinkColumns, "No share links found")
+		},
+	}
+	addOutputFlags(listCmd, &shareOutput, "format")
+
+	revokeCmd := &cobra.Command{
+		Use:   "revoke SHARE_ID...",
+		Short: "Revoke share links",
+		Args:  cobra.MinimumNArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			InitClient()
+			failed := false
+			for _, shareID := range args {
+				if err := c.RevokeShareLink(shareID); err != nil {
+					fmt.Fprintf(os.Stderr, "Error revoking %s: %s\n", shareID, err)
+					failed = true
+					continue
+				}
+				fmt.Printf("Share link %s revoked\n", shareID)
+			}
+			if failed {
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.AddCommand(listCmd, revokeCmd)
+	return cmd
+}
+
+// shareOptions returns the share options of the flags, prompting for the
+// password if requested.
+func shareOptions() ephcli.ShareOptions {
+	if shareMaxDownloads < 0 {
+		fmt.Fprintf(os.Stderr, "Error: --max-downloads must be positive\n")
+		os.Exit(1)
+	}
+	opts := ephcli.ShareOptions{Password: sharePassword, MaxDownloads: shareMaxDownloads}
+	if shareAskPassword {
+		password, err := cmdutil.ReadPassword("Link password: ")
+		if err != nil {
+			cmdutil.HandleError("Error", err)
+		}
+		opts.Password = password
+	}
+	if shareExpires != "" {
+		expiresAt, err := ephcli.ParseExpiration(shareExpires, time.Now())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			os.Exit(1)
+		}
+		opts.ExpiresAt = expiresAt
+	}
+	return opts
+}
+
+// resolveOrganizationID returns the ID of the organization of the --org and --org-id flags,
+// or of the default organization.
+func resolveOrganizationID() string {
+	return resolveOrganization().ID
+}
+
+// resolveOrganization returns the organization of the --org and --org-id flags,
+// or the default organization.
+func resolveOrganization() *dto.Organization {
+	orgCtx := ephcli.NewOrgContext(c, cfg)
+	org, err := orgCtx.ResolveOrganization(orgName, orgID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+	return org
+}
+
+// checkOrganizationFile exits if fileID is not a file of the resolved organization.
+func checkOrganizationFile(fileID string) {
+	files, err := c.ListAllOrganizationFiles(resolveOrganizationID())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error listing files: %s\n", err)
+		os.Exit(1)
+	}
+	if !slices.ContainsFunc(files, func(f dto.OrganizationFile) bool { return f.ID == fileID }) {
+		fmt.Fprintf(os.Stderr, "Error: %s in organization: %s\n", ephcli.ErrFileNotFound, fileID)
+		os.Exit(1)
+	}
+}
+
+// printShareLink prints the URL of a link, as a QR code with --qr, and its
+// restrictions on stderr so that the output can be captured.
+func printShareLink(link *dto.ShareLink) {
+	if shareQR {
+		qrterminal.GenerateHalfBlock(link.URL, qrterminal.L, os.Stdout)
+	}
+	fmt.Println(link.URL)
+
+	details := []string{"expires on " + link.ExpirationDate.Local().Format(time.DateTime)}
+	if link.MaxDownloads > 0 {
+		details = append(details, fmt.Sprintf("%d download(s) max", link.MaxDownloads))
+	}
+	if link.PasswordRequired {
+		details = append(details, "password protected")
+	}
+	fmt.Fprintf(os.Stderr, "Share link %s to %s: %s\n", link.ID, link.Filename, strings.Join(details, ", "))
+}
+
+// shareLinkColumns are the columns of share links.
+var shareLinkColumns = []render.Column[dto.ShareLink]{
+	{Name: "ID", Value: func(l dto.ShareLink) string { return l.ID }},
+	{Name: "Filename", Value: func(l dto.ShareLink) string { return l.Filename }},
+	{Name: "URL", Value: func(l dto.ShareLink) string { return l.URL }},
+	{Name: "Downloads", Value: func(l dto.ShareLink) string {
+		downloads := strconv.Itoa(l.DownloadCount)
+		if l.MaxDownloads > 0 {
+			downloads += "/" + strconv.Itoa(l.MaxDownloads)
+		}
+		return downloads
+	}},
+	{Name: "Password", Value: func(l dto.ShareLink) string {
+		if l.PasswordRequired {
+			return "yes"
+		}
+		return "no"
+	}},
+	{Name: "Expiration", Value: func(l dto.ShareLink) string { return formatInfoDate(l.ExpirationDate) }},
+	{Name: "File ID", Value: func(l dto.ShareLink) string { return l.FileID }, Extra: true},
+	{Name: "Created", Value: func(l dto.ShareLink) string { return formatInfoDate(l.CreatedAt) }, Extra: true},
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/ephemeralfiles/eph/pkg/cmdutil"
+	"github.com/ephemeralfiles/eph/pkg/ephcli"
+	"github.com/ephemeralfiles/eph/pkg/tui"
+	"github.com/spf13/cobra"
+)
+
+var uiOutputDir string
+
+// uiCmd represents the ui command.
+var uiCmd = &cobra.Command{
+	Use:   "ui",
+	Short: "browse and manage files in a full-screen terminal interface",
+	Long: `browse and manage files in a full-screen terminal interface.
+
+The personal files are listed first; press o to switch to the files of an
+organization and f to filter them by tag. The metadata of the selected file is
+previewed next to the list, i loads its complete metadata.
+
+d queues the download of the file, shown with its progress in the transfer
+pane; x deletes the file after confirmation, t replaces the tags of an
+organization file and s creates a share link. Press ? for every key and q to
+quit.
+
+Files are downloaded in the transfer mode they were uploaded with, to the
+current directory or to --output-dir. Existing files are not overwritten: the
+download is written to a new name.`,
+	Run: func(_ *cobra.Command, _ []string) {
+		InitClient()
+		c.SetOutputDir(uiOutputDir)
+		c.SetExistPolicy(ephcli.ExistRename)
+
+		model := tui.New(tui.NewClientBackend(c))
+		if err := tui.Run(model, os.Stdin, os.Stdout); err != nil {
+			cmdutil.HandleError("Error", err)
+		}
+	},
+}
+
+func init() {
+	uiCmd.Flags().StringVar(&uiOutputDir, "output-dir", "", "directory where downloaded files are written")
+}
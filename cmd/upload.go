@@ -1,7 +1,14 @@
 package cmd
 
 import (
+	"fmt"
+	"os"
+	"time"
+
 	"github.com/ephemeralfiles/eph/pkg/cmdutil"
+	"github.com/ephemeralfiles/eph/pkg/ephcli"
+	"github.com/ephemeralfiles/eph/pkg/render"
+	"github.com/ephemeralfiles/eph/pkg/units"
 	"github.com/spf13/cobra"
 )
 
@@ -14,22 +21,128 @@ The file is required.
 
 By default, files are uploaded with end-to-end encryption.
 Use --clear to upload without encryption.
+
+Encrypted uploads are sent in chunks of 128MB by default. Use --chunk-size to
+send smaller chunks on slow links, or --adaptive-chunks to size them from the
+measured throughput.
+
+Use --expires to replace the default retention of the file, with a duration
+(24h, 7d) or a date (2026-12-31).
+
+Only the base name of the file is sent to the server. Use --name to upload it
+under another name, or --keep-path to send the path as given.
+
+Use --from-url instead of --input to upload the body of an http(s) URL: it is
+streamed to the encrypted upload without being written to disk, and named after
+the URL unless --name is set. The response must have a Content-Length, as the
+size of the file is needed before its first chunk is sent.
+
+The uploaded file is described once the upload ends: its ID, name, size and
+expiration. Use --output json for scripts, or --output id-only to print the ID
+alone. The progress bar is disabled with any format but table.
 `,
 	Run: func(cmd *cobra.Command, _ []string) {
 		InitClient()
-		cmdutil.ValidateRequired(fileToUpload, "file", cmd)
+		if uploadFromURL == "" {
+			cmdutil.ValidateRequired(fileToUpload, "file", cmd)
+		}
+		if !uploadOutput.options().IsTable() {
+			// Keep stdout parseable
+			c.DisableProgressBar()
+		}
+		configureUploadOptions()
+
+		opts := ephcli.UploadOptions{
+			ExpiresAt: uploadExpiration(),
+			Name:      uploadName,
+			KeepPath:  uploadKeepPath,
+		}
 
 		// Use encrypted upload by default, unless --clear flag is set
+		var result *ephcli.UploadResult
 		var err error
-		if clearTransfer {
-			err = c.Upload(fileToUpload)
-		} else {
-			err = c.UploadE2E(fileToUpload)
+		switch {
+		case uploadFromURL != "":
+			result, err = c.UploadE2EFromURL(uploadFromURL, opts)
+		case clearTransfer:
+			result, err = c.UploadWithOptions(fileToUpload, opts)
+		default:
+			result, err = c.UploadE2EWithOptions(fileToUpload, opts)
 		}
 
 		if err != nil {
 			cmdutil.HandleError("Error uploading file", err)
 		}
+		printUploadResult(result)
 	},
 }
 
+// uploadResultColumns are the fields of an uploaded file.
+var uploadResultColumns = []render.Column[*ephcli.UploadResult]{
+	{Name: "ID", Value: func(r *ephcli.UploadResult) string { return r.FileID }},
+	{Name: "Name", Value: func(r *ephcli.UploadResult) string { return r.Name }},
+	{Name: "Size", Value: func(r *ephcli.UploadResult) string {
+		return fmt.Sprintf("%s (%d bytes)", units.FormatSize(r.Size), r.Size)
+	}},
+	{Name: "Organization", Value: func(r *ephcli.UploadResult) string { return r.OrganizationID }},
+	{Name: "Expires", Value: func(r *ephcli.UploadResult) string { return formatInfoDate(r.ExpiresAt) }},
+	{Name: "Encryption", Value: func(r *ephcli.UploadResult) string { return encryptionModeLabel(r.Encryption) }},
+}
+
+// printUploadResult prints the uploaded file in the format of the --output flag.
+func printUploadResult(result *ephcli.UploadResult) {
+	if uploadOutput.format == renderFormatIDOnly {
+		fmt.Println(result.FileID)
+		return
+	}
+	printItem(&uploadOutput, result, uploadResultColumns)
+}
+
+// renderFormatIDOnly prints the ID of the uploaded file alone.
+const renderFormatIDOnly = "id-only"
+
+// addUploadOptionFlags registers the expiration and name flags, and the chunking
+// flags of E2E uploads.
+func addUploadOptionFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&uploadExpires, "expires", "",
+		"expiration of the file, as a duration (24h, 7d) or a date (2026-12-31)")
+	cmd.Flags().StringVar(&uploadName, "name", "", "name of the file on the server (default: base name of the file)")
+	cmd.Flags().BoolVar(&uploadKeepPath, "keep-path", false, "send the path of the file as given instead of its base name")
+	cmd.MarkFlagsMutuallyExclusive("name", "keep-path")
+	cmd.Flags().StringVar(&chunkSize, "chunk-size", "",
+		"size of the chunks of encrypted uploads, e.g. 8M (overrides the configuration)")
+	cmd.Flags().BoolVar(&adaptiveChunks, "adaptive-chunks", false,
+		"size the chunks of encrypted uploads from the measured throughput")
+}
+
+// configureUploadOptions applies the chunking flags, or the configuration
+// (chunk_size and adaptive_chunks) when they are not set, to the client.
+func configureUploadOptions() {
+	size := cfg.ChunkSize
+	if chunkSize != "" {
+		size = chunkSize
+	}
+	if size != "" {
+		bytes, err := units.ParseSize(size)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid chunk size: %s\n", err)
+			os.Exit(1)
+		}
+		c.SetChunkSize(bytes)
+	}
+	c.SetAdaptiveChunkSize(adaptiveChunks || cfg.AdaptiveChunks)
+}
+
+// uploadExpiration returns the expiration of the --expires flag, or the zero
+// time to keep the default retention.
+func uploadExpiration() time.Time {
+	if uploadExpires == "" {
+		return time.Time{}
+	}
+	expiresAt, err := ephcli.ParseExpiration(uploadExpires, time.Now())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+	return expiresAt
+}
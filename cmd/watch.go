@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/ephemeralfiles/eph/pkg/cmdutil"
+	"github.com/ephemeralfiles/eph/pkg/ephcli"
+	"github.com/ephemeralfiles/eph/pkg/logger"
+	"github.com/ephemeralfiles/eph/pkg/watch"
+	"github.com/spf13/cobra"
+)
+
+var (
+	watchTags       string
+	watchDelete     bool
+	watchDoneDir    string
+	watchPoll       bool
+	watchInterval   time.Duration
+	watchStableFor  time.Duration
+	watchRetryDelay time.Duration
+	watchLogFormat  string
+)
+
+// watchCmd represents the watch command.
+var watchCmd = &cobra.Command{
+	Use:   "watch DIRECTORY",
+	Short: "upload the files dropped into a directory",
+	Long: `upload the files dropped into a directory, until interrupted.
+
+New files are detected with inotify (or by polling every --interval with --poll,
+and on systems without inotify) and uploaded with end-to-end encryption once
+their size has not changed for --stable. Hidden files and subdirectories are
+ignored.
+
+Uploaded files are moved to DIRECTORY/.done (--done-dir), or deleted with
+--delete. Failed uploads are retried after --retry-delay, doubled on each
+failure up to an hour; the retries are recorded in DIRECTORY/.eph-watch-queue.json
+and survive restarts.
+
+Files are uploaded to your box, or to the organization of --org or --org-id
+with --tags. Logs are written to stdout, as text or JSON (--log-format json).
+`,
+	Example: `  eph watch ./inbox --org finance --tags scan
+  eph watch ./inbox --delete --poll --interval 10s --log-format json`,
+	Args: cobra.ExactArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		InitClient()
+		configureUploadOptions()
+
+		opts := ephcli.UploadOptions{Tags: ephcli.ParseTags(watchTags)}
+		switch {
+		case orgName != "" || orgID != "":
+			opts.OrganizationID = resolveOrganizationID()
+		case len(opts.Tags) > 0:
+			cmdutil.HandleErrorf("Error: --tags requires --org or --org-id")
+		}
+
+		logLevel := "info"
+		if debugMode {
+			logLevel = "debug"
+		}
+		log := logger.NewFormattedLogger(os.Stdout, logLevel, watchLogFormat)
+		w, err := watch.New(watch.Options{
+			Dir:        args[0],
+			Poll:       watchPoll,
+			Interval:   watchInterval,
+			StableFor:  watchStableFor,
+			Delete:     watchDelete,
+			DoneDir:    watchDoneDir,
+			RetryDelay: watchRetryDelay,
+		}, func(path string) (string, error) {
+			client := c.Clone()
+			client.DisableProgressBar()
+			result, err := client.UploadE2EWithOptions(path, opts)
+			if err != nil {
+				return "", err
+			}
+			return result.FileID, nil
+		}, log)
+		if err != nil {
+			cmdutil.HandleError("Error", err)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		if err := w.Run(ctx); err != nil {
+			cmdutil.HandleError("Error watching directory", err)
+		}
+	},
+}
+
+func init() {
+	watchCmd.Flags().StringVar(&orgName, "org", "", "organization name")
+	watchCmd.Flags().StringVar(&orgID, "org-id", "", "organization ID (UUID)")
+	watchCmd.Flags().StringVar(&watchTags, "tags", "", "comma-separated tags of the uploaded files (requires --org)")
+	watchCmd.Flags().BoolVar(&watchDelete, "delete", false, "delete uploaded files instead of moving them")
+	watchCmd.Flags().StringVar(&watchDoneDir, "done-dir", "",
+		"directory uploaded files are moved to (default: DIRECTORY/.done)")
+	watchCmd.MarkFlagsMutuallyExclusive("delete", "done-dir")
+	watchCmd.Flags().BoolVar(&watchPoll, "poll", false, "poll the directory instead of using inotify")
+	watchCmd.Flags().DurationVar(&watchInterval, "interval", watch.DefaultInterval, "delay between two scans")
+	watchCmd.Flags().DurationVar(&watchStableFor, "stable", watch.DefaultStableFor,
+		"how long a file must stay unchanged before its upload")
+	watchCmd.Flags().DurationVar(&watchRetryDelay, "retry-delay", watch.DefaultRetryDelay,
+		"delay before the first retry of a failed upload")
+	watchCmd.Flags().StringVar(&watchLogFormat, "log-format", logger.FormatText, "log format (text, json)")
+}
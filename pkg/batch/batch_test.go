@@ -0,0 +1,225 @@
+package batch_test
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ephemeralfiles/eph/pkg/batch"
+	"github.com/ephemeralfiles/eph/pkg/dto"
+	"github.com/ephemeralfiles/eph/pkg/ephcli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+var errUploadFailed = errors.New("upload failed")
+
+// writeFiles creates the given files, relative to dir.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0750))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	}
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	m, err := batch.Parse([]byte(`
+organization: eph1
+tags: [release]
+concurrency: 2
+files:
+  - path: dist/*.tar.gz
+    tags: [archive]
+  - path: dist/checksums.txt
+    encryption: clear
+`), false)
+	require.NoError(t, err)
+	assert.Equal(t, "eph1", m.Organization)
+	assert.Equal(t, 2, m.Concurrency)
+	require.Len(t, m.Files, 2)
+	assert.Equal(t, dto.EncryptionModeClear, m.Files[1].Encryption)
+
+	fromJSON, err := batch.Parse([]byte(`{"organization": "eph1", "tags": ["release"], "concurrency": 2,
+		"files": [{"path": "dist/*.tar.gz", "tags": ["archive"]}, {"path": "dist/checksums.txt", "encryption": "clear"}]}`),
+		true)
+	require.NoError(t, err)
+	assert.Equal(t, m, fromJSON)
+
+	invalid := map[string]string{
+		"no files":           `organization: eph1`,
+		"unknown field":      "files:\n  - path: a\n    tag: [x]",
+		"invalid encryption": "files:\n  - path: a\n    encryption: aes",
+		"missing path":       "files:\n  - name: a",
+		"invalid yaml":       "files: [",
+	}
+	for name, manifest := range invalid {
+		_, err := batch.Parse([]byte(manifest), false)
+		require.ErrorIs(t, err, batch.ErrInvalidManifest, name)
+	}
+}
+
+func TestPlan(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"dist/a.tar.gz":      "a",
+		"dist/b.tar.gz":      "bb",
+		"dist/checksums.txt": "sums",
+	})
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "dist", "dir.tar.gz"), 0750))
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	m := &batch.Manifest{
+		Organization: "eph1",
+		Tags:         []string{"release"},
+		Expires:      "30d",
+		Files: []batch.Entry{
+			{Path: "dist/*.tar.gz", Tags: []string{"archive", "release"}},
+			{Path: "dist/checksums.txt", Name: "SHA256SUMS", Encryption: dto.EncryptionModeClear, Expires: "7d"},
+		},
+	}
+	items, err := batch.Plan(m, dir, now)
+	require.NoError(t, err)
+	require.Len(t, items, 3)
+
+	assert.Equal(t, batch.Item{
+		Path:         filepath.Join(dir, "dist", "a.tar.gz"),
+		Source:       "dist/a.tar.gz",
+		Name:         "a.tar.gz",
+		Size:         1,
+		Organization: "eph1",
+		Tags:         []string{"release", "archive"},
+		ExpiresAt:    now.AddDate(0, 0, 30),
+		Encryption:   dto.EncryptionModeE2E,
+	}, items[0])
+	assert.Equal(t, "dist/b.tar.gz", items[1].Source, "directories are skipped")
+	assert.Equal(t, "SHA256SUMS", items[2].Name)
+	assert.Equal(t, dto.EncryptionModeClear, items[2].Encryption)
+	assert.Equal(t, now.AddDate(0, 0, 7), items[2].ExpiresAt)
+	assert.Equal(t, []string{"eph1"}, batch.Organizations(items))
+
+	errorCases := []struct {
+		name    string
+		files   []batch.Entry
+		org     string
+		wantErr error
+	}{
+		{name: "no match", org: "eph1", files: []batch.Entry{{Path: "dist/*.zip"}}, wantErr: batch.ErrNoMatch},
+		{name: "missing file", org: "eph1", files: []batch.Entry{{Path: "dist/c.tar.gz"}}, wantErr: batch.ErrNoMatch},
+		{
+			name: "duplicate", org: "eph1",
+			files:   []batch.Entry{{Path: "dist/*"}, {Path: "dist/a.tar.gz"}},
+			wantErr: batch.ErrDuplicatePath,
+		},
+		{
+			name: "name on several files", org: "eph1",
+			files: []batch.Entry{{Path: "dist/*.tar.gz", Name: "x"}}, wantErr: batch.ErrInvalidManifest,
+		},
+		{name: "tags without organization", files: []batch.Entry{{Path: "dist/a.tar.gz", Tags: []string{"x"}}},
+			wantErr: batch.ErrInvalidManifest},
+		{name: "invalid name", files: []batch.Entry{{Path: "dist/a.tar.gz", Name: "a/b"}}, wantErr: ephcli.ErrInvalidFilename},
+		{name: "past expiration", files: []batch.Entry{{Path: "dist/a.tar.gz", Expires: "2020-01-01"}},
+			wantErr: ephcli.ErrExpirationInPast},
+	}
+	for _, tt := range errorCases {
+		_, err := batch.Plan(&batch.Manifest{Organization: tt.org, Files: tt.files}, dir, now)
+		require.ErrorIs(t, err, tt.wantErr, tt.name)
+	}
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	files := map[string]string{}
+	var items []batch.Item
+	for _, name := range []string{"a", "b", "c", "d", "e", "f", "g"} {
+		files[name] = "content of " + name
+		items = append(items, batch.Item{Path: filepath.Join(dir, name), Source: name, Name: name})
+	}
+	writeFiles(t, dir, files)
+	items = append(items, batch.Item{Path: filepath.Join(dir, "missing"), Source: "missing"})
+
+	var running, maxRunning atomic.Int32
+	var mu sync.Mutex
+	var done []string
+	results := batch.Run(items, 3, func(item batch.Item) (*ephcli.UploadResult, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			current := maxRunning.Load()
+			if n <= current || maxRunning.CompareAndSwap(current, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		if item.Source == "c" {
+			return nil, errUploadFailed
+		}
+		checksum, err := ephcli.FileChecksum(item.Path)
+		if err != nil {
+			return nil, err
+		}
+		return &ephcli.UploadResult{FileID: "id-" + item.Source, Checksum: checksum}, nil
+	}, func(r batch.Result) {
+		mu.Lock()
+		defer mu.Unlock()
+		done = append(done, r.Source)
+	})
+
+	assert.LessOrEqual(t, maxRunning.Load(), int32(3))
+	assert.Len(t, done, len(items))
+	require.Len(t, results, len(items))
+	assert.Equal(t, "id-a", results[0].FileID, "results follow the order of the items")
+	checksum, err := ephcli.FileChecksum(filepath.Join(dir, "a"))
+	require.NoError(t, err)
+	assert.Equal(t, checksum, results[0].Checksum)
+	require.ErrorIs(t, results[2].Err, errUploadFailed)
+	require.ErrorIs(t, results[7].Err, ephcli.ErrOpeningFile)
+	assert.Equal(t, 2, batch.Failed(results))
+}
+
+func TestWriteResults(t *testing.T) {
+	t.Parallel()
+
+	results := []batch.Result{
+		{
+			Item:     batch.Item{Source: "dist/a.tar.gz", Name: "a.tar.gz", Size: 1, Organization: "eph1"},
+			FileID:   "id-a",
+			Checksum: "sha256:abc",
+		},
+		{Item: batch.Item{Source: "dist/b.tar.gz", Name: "b.tar.gz", Size: 2}, Err: errUploadFailed},
+	}
+	expected := map[string]batch.ResultEntry{
+		"dist/a.tar.gz": {FileID: "id-a", Name: "a.tar.gz", Size: 1, Checksum: "sha256:abc", Organization: "eph1"},
+		"dist/b.tar.gz": {Name: "b.tar.gz", Size: 2, Error: errUploadFailed.Error()},
+	}
+
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, "results.json")
+	require.NoError(t, batch.WriteResults(jsonPath, results))
+	data, err := os.ReadFile(jsonPath)
+	require.NoError(t, err)
+	var fromJSON map[string]batch.ResultEntry
+	require.NoError(t, json.Unmarshal(data, &fromJSON))
+	assert.Equal(t, expected, fromJSON)
+
+	yamlPath := filepath.Join(dir, "results.yml")
+	require.NoError(t, batch.WriteResults(yamlPath, results))
+	data, err = os.ReadFile(yamlPath)
+	require.NoError(t, err)
+	var fromYAML map[string]batch.ResultEntry
+	require.NoError(t, yaml.Unmarshal(data, &fromYAML))
+	assert.Equal(t, expected, fromYAML)
+}
@@ -0,0 +1,121 @@
+// Package batch uploads the files declared by a manifest, with bounded
+// concurrency, and records the outcome of each upload in a results file.
+package batch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ephemeralfiles/eph/pkg/dto"
+	"gopkg.in/yaml.v2"
+)
+
+// DefaultConcurrency is the number of parallel uploads when the manifest does not set one.
+const DefaultConcurrency = 4
+
+var (
+	// ErrInvalidManifest is returned when a manifest cannot be parsed or is inconsistent.
+	ErrInvalidManifest = errors.New("invalid manifest")
+	// ErrNoMatch is returned when a path of the manifest matches no file.
+	ErrNoMatch = errors.New("no file matches")
+	// ErrDuplicatePath is returned when a file is matched by several entries.
+	ErrDuplicatePath = errors.New("file declared several times")
+)
+
+// Manifest declares a batch of uploads. Its organization, tags, expiration and
+// encryption apply to every entry that does not override them.
+type Manifest struct {
+	// Organization is the name or ID of the target organization. Files are
+	// uploaded to the personal box when it is empty.
+	Organization string `json:"organization" yaml:"organization"`
+	// Tags are added to the tags of every entry.
+	Tags []string `json:"tags" yaml:"tags"`
+	// Expires is a duration (7d) or a date (2026-12-31), see ephcli.ParseExpiration.
+	Expires string `json:"expires" yaml:"expires"`
+	// Encryption is "e2e" (default) or "clear".
+	Encryption string `json:"encryption" yaml:"encryption"`
+	// Concurrency is the number of parallel uploads.
+	Concurrency int `json:"concurrency" yaml:"concurrency"`
+	// Files are the entries of the batch.
+	Files []Entry `json:"files" yaml:"files"`
+}
+
+// Entry is a file, or a glob pattern matching files, to upload.
+type Entry struct {
+	// Path is a file path or a glob pattern, relative to the directory of the manifest.
+	Path string `json:"path" yaml:"path"`
+	// Name is the name of the file on the server. It is only allowed on paths
+	// matching a single file.
+	Name         string   `json:"name" yaml:"name"`
+	Organization string   `json:"organization" yaml:"organization"`
+	Tags         []string `json:"tags" yaml:"tags"`
+	Expires      string   `json:"expires" yaml:"expires"`
+	Encryption   string   `json:"encryption" yaml:"encryption"`
+}
+
+// Load reads a manifest from a YAML or JSON file. Unknown fields are rejected
+// so that a typo does not silently drop a setting.
+func Load(path string) (*Manifest, error) {
+	// #nosec G304 -- path is provided by user
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading manifest: %w", err)
+	}
+	return Parse(data, strings.EqualFold(filepath.Ext(path), ".json"))
+}
+
+// Parse decodes a manifest, from JSON when isJSON is set and from YAML otherwise.
+func Parse(data []byte, isJSON bool) (*Manifest, error) {
+	var m Manifest
+	if isJSON {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&m); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidManifest, err)
+		}
+	} else if err := yaml.UnmarshalStrict(data, &m); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidManifest, err)
+	}
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// validate checks the settings that do not depend on the file system.
+func (m *Manifest) validate() error {
+	if len(m.Files) == 0 {
+		return fmt.Errorf("%w: no files declared", ErrInvalidManifest)
+	}
+	if m.Concurrency < 0 {
+		return fmt.Errorf("%w: negative concurrency", ErrInvalidManifest)
+	}
+	if err := validateEncryption(m.Encryption); err != nil {
+		return err
+	}
+	for i, e := range m.Files {
+		if strings.TrimSpace(e.Path) == "" {
+			return fmt.Errorf("%w: files[%d]: path is required", ErrInvalidManifest, i)
+		}
+		if err := validateEncryption(e.Encryption); err != nil {
+			return fmt.Errorf("files[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// validateEncryption checks an encryption mode of the manifest.
+func validateEncryption(mode string) error {
+	switch mode {
+	case "", dto.EncryptionModeE2E, dto.EncryptionModeClear:
+		return nil
+	default:
+		return fmt.Errorf("%w: encryption %q, expected %s or %s",
+			ErrInvalidManifest, mode, dto.EncryptionModeE2E, dto.EncryptionModeClear)
+	}
+}
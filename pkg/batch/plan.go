@@ -0,0 +1,160 @@
+package batch
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/ephemeralfiles/eph/pkg/dto"
+	"github.com/ephemeralfiles/eph/pkg/ephcli"
+)
+
+// Item is a single file to upload, with the settings resolved from its entry
+// and the manifest defaults.
+type Item struct {
+	// Path is the path used to read the file.
+	Path string
+	// Source is the path of the file relative to the directory of the
+	// manifest. It identifies the file in the results.
+	Source string
+	// Name is the name of the file on the server.
+	Name string
+	// Size is the size of the file in bytes.
+	Size int64
+	// Organization is the organization as declared in the manifest (name or ID),
+	// empty for the personal box.
+	Organization string
+	// OrganizationID is the resolved ID of Organization, set by the caller
+	// before running the batch.
+	OrganizationID string
+	Tags           []string
+	ExpiresAt      time.Time
+	// Encryption is dto.EncryptionModeE2E or dto.EncryptionModeClear.
+	Encryption string
+}
+
+// Plan expands the entries of m into the items to upload. Relative paths and
+// patterns are resolved from baseDir, usually the directory of the manifest.
+// Expirations are computed from now.
+func Plan(m *Manifest, baseDir string, now time.Time) ([]Item, error) {
+	var items []Item
+	seen := make(map[string]bool)
+	for i, e := range m.Files {
+		matches, err := expandEntry(e.Path, baseDir)
+		if err != nil {
+			return nil, fmt.Errorf("files[%d]: %w", i, err)
+		}
+		if e.Name != "" && len(matches) > 1 {
+			return nil, fmt.Errorf("%w: files[%d]: name set on %q, which matches %d files",
+				ErrInvalidManifest, i, e.Path, len(matches))
+		}
+
+		settings, err := m.resolve(e, now)
+		if err != nil {
+			return nil, fmt.Errorf("files[%d]: %w", i, err)
+		}
+		for _, match := range matches {
+			item := settings
+			item.Path = match.path
+			item.Size = match.size
+			item.Source = match.path
+			if rel, err := filepath.Rel(baseDir, match.path); err == nil {
+				item.Source = filepath.ToSlash(rel)
+			}
+			if seen[item.Source] {
+				return nil, fmt.Errorf("%w: %s", ErrDuplicatePath, item.Source)
+			}
+			seen[item.Source] = true
+
+			item.Name = e.Name
+			if item.Name == "" {
+				item.Name = filepath.Base(match.path)
+			}
+			if err := ephcli.ValidateRemoteName(item.Name); err != nil {
+				return nil, fmt.Errorf("files[%d]: %w", i, err)
+			}
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+// resolve returns the settings of the items of e, merged with the manifest defaults.
+func (m *Manifest) resolve(e Entry, now time.Time) (Item, error) {
+	item := Item{
+		Organization: firstNonEmpty(e.Organization, m.Organization),
+		Encryption:   firstNonEmpty(e.Encryption, m.Encryption, dto.EncryptionModeE2E),
+	}
+	if tags := ephcli.ApplyTagOperation(m.Tags, ephcli.TagAdd, e.Tags); len(tags) > 0 {
+		item.Tags = tags
+	}
+	if len(item.Tags) > 0 && item.Organization == "" {
+		return Item{}, fmt.Errorf("%w: tags require an organization", ErrInvalidManifest)
+	}
+	if expires := firstNonEmpty(e.Expires, m.Expires); expires != "" {
+		expiresAt, err := ephcli.ParseExpiration(expires, now)
+		if err != nil {
+			return Item{}, err
+		}
+		item.ExpiresAt = expiresAt
+	}
+	return item, nil
+}
+
+// match is a regular file matched by an entry.
+type match struct {
+	path string
+	size int64
+}
+
+// expandEntry returns the regular files matched by pattern, in lexical order.
+// A pattern without glob characters must name an existing file.
+func expandEntry(pattern, baseDir string) ([]match, error) {
+	if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(baseDir, pattern)
+	}
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidManifest, err)
+	}
+	slices.Sort(paths)
+
+	var matches []match
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("error getting file info: %w", err)
+		}
+		// Globs commonly match directories too: only files are uploaded
+		if info.Mode().IsRegular() {
+			matches = append(matches, match{path: path, size: info.Size()})
+		}
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("%w %s", ErrNoMatch, pattern)
+	}
+	return matches, nil
+}
+
+// Organizations returns the organizations declared by items, without duplicates.
+func Organizations(items []Item) []string {
+	var orgs []string
+	for _, item := range items {
+		if item.Organization != "" && !slices.Contains(orgs, item.Organization) {
+			orgs = append(orgs, item.Organization)
+		}
+	}
+	return orgs
+}
+
+// firstNonEmpty returns the first non-empty value.
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
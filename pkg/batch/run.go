@@ -0,0 +1,139 @@
+package batch
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/ephemeralfiles/eph/pkg/ephcli"
+	"gopkg.in/yaml.v2"
+)
+
+// resultsFilePerm is the permission of the results file.
+const resultsFilePerm = 0600
+
+// UploadFunc uploads the file of an item and returns the uploaded file, with the
+// checksum computed during the upload. It is called concurrently and must not
+// share per-transfer state.
+type UploadFunc func(item Item) (*ephcli.UploadResult, error)
+
+// Result is the outcome of the upload of an item.
+type Result struct {
+	Item
+	FileID   string
+	Checksum string
+	Err      error
+}
+
+// Run uploads items with at most concurrency uploads in progress, and returns
+// the results in the order of items. done, when set, is called as each upload
+// ends; calls are serialized.
+func Run(items []Item, concurrency int, upload UploadFunc, done func(Result)) []Result {
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+
+	results := make([]Result, len(items))
+	indexes := make(chan int)
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+	for range min(concurrency, len(items)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				result := uploadItem(items[i], upload)
+				results[i] = result
+				if done != nil {
+					mu.Lock()
+					done(result)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for i := range items {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return results
+}
+
+// uploadItem uploads the file of item.
+func uploadItem(item Item, upload UploadFunc) Result {
+	uploaded, err := upload(item)
+	if err != nil {
+		return Result{Item: item, Err: err}
+	}
+	return Result{Item: item, FileID: uploaded.FileID, Checksum: uploaded.Checksum}
+}
+
+// Failed returns the number of results holding an error.
+func Failed(results []Result) int {
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+		}
+	}
+	return failed
+}
+
+// ResultEntry is the record of an uploaded file in the results file.
+type ResultEntry struct {
+	FileID       string `json:"file_id,omitempty" yaml:"file_id,omitempty"`
+	Name         string `json:"name" yaml:"name"`
+	Size         int64  `json:"size" yaml:"size"`
+	Checksum     string `json:"checksum,omitempty" yaml:"checksum,omitempty"`
+	Organization string `json:"organization,omitempty" yaml:"organization,omitempty"`
+	Error        string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// Entries maps the source path of each result to its record.
+func Entries(results []Result) map[string]ResultEntry {
+	entries := make(map[string]ResultEntry, len(results))
+	for _, r := range results {
+		entry := ResultEntry{
+			FileID:       r.FileID,
+			Name:         r.Name,
+			Size:         r.Size,
+			Checksum:     r.Checksum,
+			Organization: r.Organization,
+		}
+		if r.Err != nil {
+			entry.Error = r.Err.Error()
+		}
+		entries[r.Source] = entry
+	}
+	return entries
+}
+
+// WriteResults writes the results to path, in YAML when its extension is .yml
+// or .yaml and in JSON otherwise.
+func WriteResults(path string, results []Result) error {
+	entries := Entries(results)
+	var (
+		data []byte
+		err  error
+	)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yml", ".yaml":
+		data, err = yaml.Marshal(entries)
+	default:
+		data, err = json.MarshalIndent(entries, "", "  ")
+		data = append(data, '\n')
+	}
+	if err != nil {
+		return fmt.Errorf("error encoding results: %w", err)
+	}
+	if err := os.WriteFile(path, data, resultsFilePerm); err != nil {
+		return fmt.Errorf("error writing results: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package cmdutil
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// ReadPassword prints prompt to stderr and reads a password from stdin, without
+// echo when stdin is a terminal. When stdin is not a terminal, the first line
+// is read, so that a password can be piped.
+func ReadPassword(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	fd := int(os.Stdin.Fd()) //nolint:gosec // file descriptors fit in an int
+	if term.IsTerminal(fd) {
+		password, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("error reading password: %w", err)
+		}
+		return string(password), nil
+	}
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	fmt.Fprintln(os.Stderr)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("error reading password: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// IsInteractive reports whether stdin and stderr are terminals, so that the
+// user can pick or confirm values interactively.
+func IsInteractive() bool {
+	//nolint:gosec // file descriptors fit in an int
+	return term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stderr.Fd()))
+}
@@ -9,6 +9,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/ephemeralfiles/eph/pkg/dto"
 	"gopkg.in/yaml.v2"
 )
 
@@ -26,6 +27,8 @@ var (
 	ErrInvalidToken          = errors.New("token is invalid")
 	// ErrInvalidEndpoint is returned when the provided endpoint is invalid.
 	ErrInvalidEndpoint       = errors.New("endpoint is invalid")
+	// ErrInvalidTransferMode is returned when a transfer mode is neither "e2e" nor "clear".
+	ErrInvalidTransferMode = errors.New("invalid transfer mode")
 )
 
 // Config is the configuration for the application.
@@ -33,7 +36,58 @@ type Config struct {
 	Token               string `yaml:"token"`
 	Endpoint            string `yaml:"endpoint"`
 	DefaultOrganization string `yaml:"default_organization,omitempty"`
-	homedir             string
+	// LimitRate is the bandwidth limit of transfers, e.g. "5M" (bytes per second).
+	LimitRate string `yaml:"limit_rate,omitempty"`
+	// LimitRateSchedule lists time-of-day limits such as "09:00-18:00=2M",
+	// overriding LimitRate during their windows.
+	LimitRateSchedule []string `yaml:"limit_rate_schedule,omitempty"`
+	// ChunkSize is the size of the chunks of E2E uploads, e.g. "8M".
+	ChunkSize string `yaml:"chunk_size,omitempty"`
+	// AdaptiveChunks sizes the chunks of E2E uploads from the measured throughput.
+	AdaptiveChunks bool `yaml:"adaptive_chunks,omitempty"`
+	// DisableHistory stops recording transfers in the local history.
+	DisableHistory bool `yaml:"disable_history,omitempty"`
+	// Organizations holds the settings of organizations, by organization name.
+	Organizations map[string]OrganizationConfig `yaml:"organizations,omitempty"`
+	homedir       string
+}
+
+// OrganizationConfig is the configuration of an organization.
+type OrganizationConfig struct {
+	// Transfer is the default transfer mode of the organization, "e2e" or "clear".
+	Transfer string `yaml:"transfer,omitempty"`
+}
+
+// OrganizationTransfer returns the default transfer mode of an organization,
+// "e2e" unless "clear" is configured.
+func (c *Config) OrganizationTransfer(name string) string {
+	if c.Organizations[name].Transfer == dto.EncryptionModeClear {
+		return dto.EncryptionModeClear
+	}
+	return dto.EncryptionModeE2E
+}
+
+// SetOrganizationTransfer sets the default transfer mode of an organization.
+// An empty mode removes the setting.
+func (c *Config) SetOrganizationTransfer(name string, mode string) error {
+	switch mode {
+	case "", dto.EncryptionModeE2E, dto.EncryptionModeClear:
+	default:
+		return fmt.Errorf("%w: %q (use %s or %s)", ErrInvalidTransferMode, mode,
+			dto.EncryptionModeE2E, dto.EncryptionModeClear)
+	}
+
+	orgConfig := c.Organizations[name]
+	orgConfig.Transfer = mode
+	if orgConfig == (OrganizationConfig{}) {
+		delete(c.Organizations, name)
+		return nil
+	}
+	if c.Organizations == nil {
+		c.Organizations = make(map[string]OrganizationConfig)
+	}
+	c.Organizations[name] = orgConfig
+	return nil
 }
 
 // NewConfig creates a new configuration for the application.
@@ -3,6 +3,7 @@ package config_test
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/ephemeralfiles/eph/pkg/config"
@@ -589,3 +590,44 @@ func TestResolveConfigPathEdgeCases(t *testing.T) {
 		assert.Equal(t, expected, result)
 	})
 }
+
+func TestLoadTransferSettings(t *testing.T) {
+	t.Parallel()
+
+	cfgFile := filepath.Join(t.TempDir(), "office.yml")
+	content := "token: sdf\nendpoint: http://localhost:8080\nlimit_rate: 5M\n" +
+		"limit_rate_schedule:\n  - 09:00-18:00=1M\n  - 18:00-09:00=0\n" +
+		"chunk_size: 8M\nadaptive_chunks: true\n"
+	require.NoError(t, os.WriteFile(cfgFile, []byte(content), 0600))
+
+	cfg := config.NewConfig()
+	require.NoError(t, cfg.LoadConfigFromFile(cfgFile))
+	assert.Equal(t, "5M", cfg.LimitRate)
+	assert.Equal(t, []string{"09:00-18:00=1M", "18:00-09:00=0"}, cfg.LimitRateSchedule)
+	assert.Equal(t, "8M", cfg.ChunkSize)
+	assert.True(t, cfg.AdaptiveChunks)
+}
+
+func TestOrganizationTransfer(t *testing.T) {
+	t.Parallel()
+
+	cfgFile := filepath.Join(t.TempDir(), "orgs.yml")
+	content := "token: sdf\nendpoint: http://localhost:8080\norganizations:\n  eph1:\n    transfer: clear\n"
+	require.NoError(t, os.WriteFile(cfgFile, []byte(content), 0600))
+
+	cfg := config.NewConfig()
+	require.NoError(t, cfg.LoadConfigFromFile(cfgFile))
+	assert.Equal(t, "clear", cfg.OrganizationTransfer("eph1"))
+	assert.Equal(t, "e2e", cfg.OrganizationTransfer("eph2"), "e2e is the default")
+
+	require.ErrorIs(t, cfg.SetOrganizationTransfer("eph2", "aes"), config.ErrInvalidTransferMode)
+	require.NoError(t, cfg.SetOrganizationTransfer("eph2", "clear"))
+	require.NoError(t, cfg.SetOrganizationTransfer("eph1", ""))
+	assert.Equal(t, map[string]config.OrganizationConfig{"eph2": {Transfer: "clear"}}, cfg.Organizations)
+
+	require.NoError(t, cfg.SaveConfiguration(cfgFile))
+	saved := config.NewConfig()
+	require.NoError(t, saved.LoadConfigFromFile(cfgFile))
+	assert.Equal(t, "clear", saved.OrganizationTransfer("eph2"))
+	assert.Equal(t, "e2e", saved.OrganizationTransfer("eph1"))
+}
@@ -6,11 +6,30 @@ import "time"
 
 // InfoFile contains information about a file to be uploaded.
 type InfoFile struct {
-	Filename string `json:"filename"`
-	Size     int64  `json:"size"`
-	NbParts  int    `json:"nb_parts"`
+	Filename string `json:"filename" yaml:"filename"`
+	Size     int64  `json:"size" yaml:"size"`
+	NbParts  int    `json:"nb_parts" yaml:"nb_parts"`
+
+	// Optional metadata, omitted by servers that do not provide it.
+	FileID         string    `json:"file_id,omitempty" yaml:"file_id,omitempty"`
+	OwnerID        string    `json:"owner_id,omitempty" yaml:"owner_id,omitempty"`
+	OwnerEmail     string    `json:"owner_email,omitempty" yaml:"owner_email,omitempty"`
+	OrganizationID string    `json:"organization_id,omitempty" yaml:"organization_id,omitempty"`
+	Tags           []string  `json:"tags,omitempty" yaml:"tags,omitempty"`
+	UploadDate     time.Time `json:"upload_date,omitzero" yaml:"upload_date,omitempty"`
+	ExpirationDate time.Time `json:"expiration_date,omitzero" yaml:"expiration_date,omitempty"`
+	EncryptionMode string    `json:"encryption_mode,omitempty" yaml:"encryption_mode,omitempty"`
+	Checksum       string    `json:"checksum,omitempty" yaml:"checksum,omitempty"`
 }
 
+// Encryption modes of InfoFile.
+const (
+	// EncryptionModeE2E is the mode of files uploaded with end-to-end encryption.
+	EncryptionModeE2E = "e2e"
+	// EncryptionModeClear is the mode of files uploaded without encryption.
+	EncryptionModeClear = "clear"
+)
+
 // RequestAESKey contains the AES encryption key for E2E encrypted operations.
 type RequestAESKey struct {
 	AESKey string `json:"aeskey"`
@@ -109,3 +128,26 @@ type TagCount struct {
 	Tag   string `json:"tag"`
 	Count int64  `json:"count"`
 }
+
+// ShareLink represents a public download link to a file.
+type ShareLink struct {
+	ID               string    `json:"id"`
+	FileID           string    `json:"file_id"`
+	Filename         string    `json:"filename"`
+	OrganizationID   string    `json:"organization_id,omitempty"`
+	URL              string    `json:"url"`
+	PasswordRequired bool      `json:"password_required"`
+	MaxDownloads     int       `json:"max_downloads,omitempty"`
+	DownloadCount    int       `json:"download_count"`
+	CreatedAt        time.Time `json:"created_at"`
+	ExpirationDate   time.Time `json:"expiration_date"`
+}
+
+// SharedFile is the public information of a file shared by a link.
+type SharedFile struct {
+	Filename         string    `json:"filename"`
+	Size             int64     `json:"size"`
+	NbParts          int       `json:"nb_parts"`
+	PasswordRequired bool      `json:"password_required"`
+	ExpirationDate   time.Time `json:"expiration_date"`
+}
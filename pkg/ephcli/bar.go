@@ -1,10 +1,38 @@
 // Package ephcli provides progress bar functionality for file operations.
 package ephcli
 
-import "github.com/schollz/progressbar/v3"
+import (
+	"io"
+	"sync"
+	"time"
+
+	"github.com/schollz/progressbar/v3"
+)
+
+// progressInterval is the interval at which the progress of a transfer is
+// reported to the progress function of the client.
+const progressInterval = 100 * time.Millisecond
+
+// ProgressFunc receives the number of bytes transferred and the total size of
+// a transfer while it runs.
+type ProgressFunc func(current, total int64)
+
+// SetProgressFunc reports the progress of the transfers of the client to fn,
+// instead of printing a progress bar. A nil fn restores the progress bar.
+func (c *ClientEphemeralfiles) SetProgressFunc(fn ProgressFunc) {
+	c.progress = fn
+}
 
 // InitProgressBar initializes a progress bar with the given message and total size.
 func (c *ClientEphemeralfiles) InitProgressBar(msg string, totalSize int64) {
+	if c.progress != nil {
+		// An invisible bar does not count bytes: render it to nowhere instead.
+		c.bar = progressbar.NewOptions64(totalSize,
+			progressbar.OptionSetWriter(io.Discard),
+			progressbar.OptionShowBytes(true))
+		c.stopProgress = reportProgress(c.bar, totalSize, c.progress)
+		return
+	}
 	c.bar = progressbar.NewOptions64(totalSize,
 		progressbar.OptionClearOnFinish(),
 		progressbar.OptionShowBytes(true),
@@ -15,6 +43,44 @@ func (c *ClientEphemeralfiles) InitProgressBar(msg string, totalSize int64) {
 
 // CloseProgressBar clears and closes the progress bar.
 func (c *ClientEphemeralfiles) CloseProgressBar() {
+	if c.stopProgress != nil {
+		c.stopProgress()
+		c.stopProgress = nil
+	}
 	_ = c.bar.Clear()
 	_ = c.bar.Close()
 }
+
+// reportProgress reports the progress of bar to fn periodically, until the
+// returned function is called; fn is called a last time before it returns.
+// The encryption overhead of E2E transfers is not reported beyond the total.
+func reportProgress(bar *progressbar.ProgressBar, total int64, fn ProgressFunc) func() {
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(progressInterval)
+		defer ticker.Stop()
+		report := func() {
+			current := bar.State().CurrentNum
+			if total > 0 {
+				current = min(current, total)
+			}
+			fn(current, total)
+		}
+		for {
+			report()
+			select {
+			case <-done:
+				report()
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
@@ -0,0 +1,49 @@
+package ephcli_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/ephemeralfiles/eph/pkg/ephcli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProgressFunc(t *testing.T) {
+	t.Parallel()
+
+	client := newSeededClient(t)
+	var (
+		mu      sync.Mutex
+		reports [][2]int64
+	)
+	client.SetProgressFunc(func(current, total int64) {
+		mu.Lock()
+		defer mu.Unlock()
+		reports = append(reports, [2]int64{current, total})
+	})
+
+	dir := t.TempDir()
+	content := bytes.Repeat([]byte("eph"), 10000)
+	source := filepath.Join(dir, "data.bin")
+	require.NoError(t, os.WriteFile(source, content, 0600))
+
+	uploaded, err := client.UploadE2EWithOptions(source, ephcli.UploadOptions{})
+	require.NoError(t, err)
+	mu.Lock()
+	require.NotEmpty(t, reports)
+	assert.Equal(t, [2]int64{int64(len(content)), int64(len(content))}, reports[len(reports)-1])
+	reports = nil
+	mu.Unlock()
+
+	require.NoError(t, client.DownloadE2E(uploaded.FileID, filepath.Join(dir, "copy.bin")))
+	mu.Lock()
+	defer mu.Unlock()
+	require.NotEmpty(t, reports)
+	last := reports[len(reports)-1]
+	assert.Equal(t, last[1], last[0])
+	assert.Positive(t, last[1])
+}
@@ -0,0 +1,53 @@
+package ephcli
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+)
+
+// checksumPrefix is the algorithm prefix of the checksums reported by the server.
+const checksumPrefix = "sha256:"
+
+// FileChecksum returns the checksum of a local file in the format reported by
+// the server for uploaded files ("sha256:" followed by the hex digest).
+func FileChecksum(path string) (string, error) {
+	// #nosec G304 -- path is provided by user for file upload
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrOpeningFile, err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	h := newChecksum()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("error computing checksum of %s: %w", path, err)
+	}
+	return formatChecksum(h), nil
+}
+
+// newChecksum returns the hash computing checksums. Transfers write their data
+// to it as it is sent or received, so that the file is not read again.
+func newChecksum() hash.Hash {
+	return sha256.New()
+}
+
+// formatChecksum returns the checksum of the data written to h.
+func formatChecksum(h hash.Hash) string {
+	return checksumPrefix + hex.EncodeToString(h.Sum(nil))
+}
+
+// transferChecksum returns the checksum of the data written to h by a
+// transfer, empty when the transfer failed with err: its data is incomplete,
+// and may still be written to h.
+func transferChecksum(h hash.Hash, err error) string {
+	if err != nil {
+		return ""
+	}
+	return formatChecksum(h)
+}
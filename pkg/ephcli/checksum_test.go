@@ -0,0 +1,46 @@
+package ephcli_test
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ephemeralfiles/eph/pkg/ephcli"
+	"github.com/ephemeralfiles/eph/pkg/mockserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileChecksum(t *testing.T) {
+	t.Parallel()
+
+	srv, err := mockserver.New(mockserver.Options{})
+	require.NoError(t, err)
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+	client := ephcli.NewClient(mockserver.DevToken(mockserver.DefaultEmail, time.Hour))
+	client.SetEndpoint(ts.URL)
+	client.DisableProgressBar()
+
+	src := filepath.Join(t.TempDir(), "notes.txt")
+	require.NoError(t, os.WriteFile(src, []byte("hello"), 0600))
+	checksum, err := ephcli.FileChecksum(src)
+	require.NoError(t, err)
+	assert.Equal(t, "sha256:2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", checksum)
+
+	// The checksum matches the one reported by the server, for clear and E2E uploads
+	clearUpload, err := client.UploadWithOptions(src, ephcli.UploadOptions{})
+	require.NoError(t, err)
+	e2eUpload, err := client.Clone().UploadE2EWithOptions(src, ephcli.UploadOptions{})
+	require.NoError(t, err)
+	for _, id := range []string{clearUpload.FileID, e2eUpload.FileID} {
+		info, err := client.GetFileInfo(id)
+		require.NoError(t, err)
+		assert.Equal(t, checksum, info.Checksum)
+	}
+
+	_, err = ephcli.FileChecksum(filepath.Join(t.TempDir(), "missing"))
+	require.ErrorIs(t, err, ephcli.ErrOpeningFile)
+}
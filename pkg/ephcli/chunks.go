@@ -0,0 +1,120 @@
+package ephcli
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// DefaultChunkSize is the size of the chunks of E2E uploads when none is configured.
+	DefaultChunkSize int64 = 128 * 1024 * 1024
+	// MinChunkSize is the smallest chunk size used for E2E uploads.
+	MinChunkSize int64 = 64 * 1024
+	// adaptiveInitialChunkSize is the size of the first chunk of an adaptive upload
+	// when no chunk size is configured.
+	adaptiveInitialChunkSize int64 = 8 * 1024 * 1024
+	// adaptiveTargetDuration is the time an adaptive chunk should take to upload.
+	adaptiveTargetDuration = 10 * time.Second
+	// adaptiveMaxGrowth bounds the growth of a chunk compared to the previous one.
+	adaptiveMaxGrowth = 4
+)
+
+// chunkLimits are the chunk size bounds accepted by the server for an upload.
+// Zero values mean the server did not advertise a bound.
+type chunkLimits struct {
+	min int64
+	max int64
+}
+
+// chunkLimitsFromHeaders reads the X-Min-Chunk-Size and X-Max-Chunk-Size headers
+// advertised when an upload transaction is created.
+func chunkLimitsFromHeaders(header http.Header) chunkLimits {
+	parse := func(name string) int64 {
+		v, err := strconv.ParseInt(header.Get(name), 10, 64)
+		if err != nil || v < 0 {
+			return 0
+		}
+		return v
+	}
+	return chunkLimits{min: parse("X-Min-Chunk-Size"), max: parse("X-Max-Chunk-Size")}
+}
+
+// SetChunkSize sets the size of the chunks of E2E uploads.
+// Zero restores DefaultChunkSize. The size is clamped to the bounds advertised
+// by the server. In adaptive mode, it is the size of the first chunk.
+func (c *ClientEphemeralfiles) SetChunkSize(size int64) {
+	c.chunkSize = size
+}
+
+// SetAdaptiveChunkSize enables or disables adaptive chunking: the size of each
+// chunk of an E2E upload is computed from the throughput measured on the previous
+// ones, so that a chunk takes about ten seconds to upload.
+func (c *ClientEphemeralfiles) SetAdaptiveChunkSize(adaptive bool) {
+	c.adaptiveChunks = adaptive
+}
+
+// chunkSizer chooses the size of the successive chunks of an upload.
+type chunkSizer struct {
+	size     int64
+	min      int64
+	max      int64
+	adaptive bool
+	log      *slog.Logger
+}
+
+// newChunkSizer returns a chunk sizer for an upload with the given server limits.
+func (c *ClientEphemeralfiles) newChunkSizer(limits chunkLimits) *chunkSizer {
+	s := &chunkSizer{
+		size:     c.chunkSize,
+		min:      MinChunkSize,
+		max:      DefaultChunkSize,
+		adaptive: c.adaptiveChunks,
+		log:      c.log,
+	}
+	if limits.max > 0 {
+		s.max = limits.max
+	}
+	if limits.min > 0 {
+		s.min = limits.min
+	}
+	s.min = min(s.min, s.max)
+	if c.chunkSize > s.max && limits.max == 0 {
+		// An explicit size above the default is allowed unless the server sets a bound
+		s.max = c.chunkSize
+	}
+	if s.size <= 0 {
+		s.size = DefaultChunkSize
+		if s.adaptive {
+			s.size = adaptiveInitialChunkSize
+		}
+	}
+	s.size = s.clamp(s.size)
+	return s
+}
+
+// next returns the size of the next chunk.
+func (s *chunkSizer) next() int64 {
+	return s.size
+}
+
+// observe records that n bytes were uploaded in elapsed and, in adaptive mode,
+// sizes the next chunk to take adaptiveTargetDuration at the measured throughput.
+func (s *chunkSizer) observe(n int64, elapsed time.Duration) {
+	if !s.adaptive || n <= 0 || elapsed <= 0 {
+		return
+	}
+	throughput := float64(n) / elapsed.Seconds()
+	target := int64(throughput * adaptiveTargetDuration.Seconds())
+	target = min(target, n*adaptiveMaxGrowth)
+	s.size = s.clamp(target)
+	s.log.Debug("Adaptive chunk size",
+		slog.Float64("throughput", throughput),
+		slog.Int64("nextChunkSize", s.size))
+}
+
+// clamp bounds size to the limits of the sizer.
+func (s *chunkSizer) clamp(size int64) int64 {
+	return max(s.min, min(size, s.max))
+}
@@ -0,0 +1,129 @@
+package ephcli_test
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ephemeralfiles/eph/pkg/ephcli"
+	"github.com/ephemeralfiles/eph/pkg/mockserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// chunkRecorder is a mock server recording the size of the uploaded E2E chunks.
+type chunkRecorder struct {
+	mu     sync.Mutex
+	sizes  []int64
+	client *ephcli.ClientEphemeralfiles
+}
+
+// newChunkRecorder starts a mock server and returns a recorder with a client configured for it.
+func newChunkRecorder(t *testing.T, opts mockserver.Options) *chunkRecorder {
+	t.Helper()
+	srv, err := mockserver.New(opts)
+	require.NoError(t, err)
+
+	rec := &chunkRecorder{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/chunks") {
+			var start, end, total int64
+			_, err := fmt.Sscanf(r.Header.Get("Content-Range"), "bytes %d-%d/%d", &start, &end, &total)
+			if err == nil {
+				rec.mu.Lock()
+				rec.sizes = append(rec.sizes, end-start+1)
+				rec.mu.Unlock()
+			}
+		}
+		srv.Handler().ServeHTTP(w, r)
+	}))
+	t.Cleanup(ts.Close)
+
+	rec.client = ephcli.NewClient(mockserver.DevToken(mockserver.DefaultEmail, time.Hour))
+	rec.client.SetEndpoint(ts.URL)
+	rec.client.DisableProgressBar()
+	return rec
+}
+
+// uploadRandomFile uploads a random file of the given size and checks it downloads back identical.
+func (rec *chunkRecorder) uploadRandomFile(t *testing.T, size int) {
+	t.Helper()
+	dir := t.TempDir()
+	src := filepath.Join(dir, "source.bin")
+	content := make([]byte, size)
+	_, err := rand.Read(content)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(src, content, 0600))
+
+	_, err = rec.client.UploadE2E(src)
+	require.NoError(t, err)
+	files, err := rec.client.Fetch()
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	out := filepath.Join(dir, "downloaded.bin")
+	require.NoError(t, rec.client.DownloadE2E(files[0].FileID, out))
+	downloaded, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Equal(t, content, downloaded)
+}
+
+func TestUploadChunkSize(t *testing.T) {
+	t.Parallel()
+
+	t.Run("configured chunk size", func(t *testing.T) {
+		t.Parallel()
+		rec := newChunkRecorder(t, mockserver.Options{})
+		rec.client.SetChunkSize(100 * 1024)
+		rec.uploadRandomFile(t, 250*1024)
+		assert.Equal(t, []int64{100 * 1024, 100 * 1024, 50 * 1024}, rec.sizes)
+	})
+
+	t.Run("chunk size below the minimum is raised", func(t *testing.T) {
+		t.Parallel()
+		rec := newChunkRecorder(t, mockserver.Options{})
+		rec.client.SetChunkSize(1024)
+		rec.uploadRandomFile(t, 100*1024)
+		assert.Equal(t, []int64{ephcli.MinChunkSize, 100*1024 - ephcli.MinChunkSize}, rec.sizes)
+	})
+
+	t.Run("server maximum is negotiated", func(t *testing.T) {
+		t.Parallel()
+		rec := newChunkRecorder(t, mockserver.Options{MaxChunkSize: 80 * 1024})
+		rec.client.SetChunkSize(1024 * 1024)
+		rec.uploadRandomFile(t, 200*1024)
+		assert.Equal(t, []int64{80 * 1024, 80 * 1024, 40 * 1024}, rec.sizes)
+	})
+
+	t.Run("empty file sends no chunk", func(t *testing.T) {
+		t.Parallel()
+		rec := newChunkRecorder(t, mockserver.Options{})
+		src := filepath.Join(t.TempDir(), "empty.txt")
+		require.NoError(t, os.WriteFile(src, nil, 0600))
+		result, err := rec.client.UploadE2E(src)
+		require.NoError(t, err)
+		assert.Zero(t, result.Size)
+		assert.Empty(t, rec.sizes)
+	})
+
+	t.Run("adaptive chunks grow with the throughput", func(t *testing.T) {
+		t.Parallel()
+		rec := newChunkRecorder(t, mockserver.Options{MaxChunkSize: 1024 * 1024})
+		rec.client.SetChunkSize(64 * 1024)
+		rec.client.SetAdaptiveChunkSize(true)
+		rec.uploadRandomFile(t, 2*1024*1024)
+		// A local server is fast: each chunk grows by the maximum factor up to the server bound
+		require.GreaterOrEqual(t, len(rec.sizes), 3)
+		assert.Equal(t, []int64{64 * 1024, 256 * 1024, 1024 * 1024}, rec.sizes[:3])
+		for _, size := range rec.sizes {
+			assert.LessOrEqual(t, size, int64(1024*1024))
+		}
+	})
+}
@@ -6,10 +6,12 @@ import (
 	"crypto/cipher"
 	"encoding/json"
 	"fmt"
+	"hash"
 	"io"
 	"log/slog"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/ephemeralfiles/eph/pkg/dto"
 )
@@ -69,27 +71,39 @@ func (c *ClientEphemeralfiles) CreateNewDownloadTransaction(
 
 // DownloadE2E downloads and decrypts a file using end-to-end encryption.
 func (c *ClientEphemeralfiles) DownloadE2E(fileID string, outputPath string) error {
+	started, sum := time.Now(), newChecksum()
+	outputFilePath, err := c.downloadE2E(fileID, outputPath, sum)
+	c.recordTransfer(Transfer{
+		Direction: TransferDownload, FileID: fileID, Path: outputFilePath, Encryption: dto.EncryptionModeE2E,
+		Checksum: transferChecksum(sum, err),
+	}, started, err)
+	return err
+}
+
+// downloadE2E downloads and decrypts a file, writing its content to sum, and
+// returns its path, empty if the download failed before the path was known.
+func (c *ClientEphemeralfiles) downloadE2E(fileID string, outputPath string, sum hash.Hash) (string, error) {
 	// Get file information
-	fileInfo, err := c.getFileInformation(fileID)
+	fileInfo, err := c.GetFileInfo(fileID)
 	if err != nil {
-		return err
+		return "", err
 	}
 	c.logFileInfo(fileInfo)
 
-	// Determine output file path: use provided path or fallback to server filename
-	outputFilePath := outputPath
-	if outputFilePath == "" {
-		outputFilePath = fileInfo.Filename
+	// Determine output file path: use provided path or fallback to the sanitized server filename
+	outputFilePath, err := c.resolveOutputPath(outputPath, fileInfo.Filename, fileID)
+	if err != nil {
+		return "", err
 	}
 
 	// Setup download transaction and encryption
 	transactionID, keyBundle, err := c.setupDownloadTransaction(fileID)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	// Download all parts
-	return c.downloadAllParts(fileInfo, transactionID, keyBundle.AESKey, outputFilePath)
+	return outputFilePath, c.downloadAllParts(fileID, fileInfo, transactionID, keyBundle.AESKey, outputFilePath, sum)
 }
 
 // DownloadPartE2EEndpoint returns the API endpoint URL for downloading a specific part of an E2E encrypted file.
@@ -114,18 +128,23 @@ func (c *ClientEphemeralfiles) DownloadPartE2E(
 	return c.DownloadPartE2EToFile(file, transactionID, aesKey, part)
 }
 
-// DownloadPartE2EToFile downloads and decrypts a specific part of an E2E encrypted file to an open file handle.
+// DownloadPartE2EToFile downloads and decrypts a specific part of an E2E encrypted file to an open file handle,
+// or any writer.
 func (c *ClientEphemeralfiles) DownloadPartE2EToFile(
-	file *os.File, transactionID string, aesKey []byte, part int,
+	file io.Writer, transactionID string, aesKey []byte, part int,
 ) (int, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), ChunkDownloadTimeout)
+	// The size of the part, and so its timeout under a rate limit, is known
+	// from the response headers
+	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
+	deadline := time.AfterFunc(ChunkDownloadTimeout, cancel)
+	defer deadline.Stop()
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.DownloadPartE2EEndpoint(transactionID, part), nil)
 	if err != nil {
 		return 0, fmt.Errorf("error creating request: %w", err)
 	}
 	// Set headers
-	req.Header.Set("Authorization", "Bearer "+c.token)
+	c.addAuthHeader(req)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -138,10 +157,11 @@ func (c *ClientEphemeralfiles) DownloadPartE2EToFile(
 	if resp.StatusCode != http.StatusOK {
 		return 0, fmt.Errorf("%w: %d", ErrUnexpectedStatusCode, resp.StatusCode)
 	}
+	deadline.Reset(c.transferTimeout(ChunkDownloadTimeout, resp.ContentLength))
 
 	// Wrap response body with progress reader for byte-level progress tracking
 	progressBody := &progressReader{
-		reader: resp.Body,
+		reader: c.limitReader(ctx, resp.Body),
 		bar:    c.bar,
 	}
 
@@ -211,15 +231,17 @@ func DecryptAES(key []byte, ciphertext []byte) ([]byte, error) {
 	return plaintext, nil
 }
 
-// getFileInformation retrieves file information from the server.
-func (c *ClientEphemeralfiles) getFileInformation(fileID string) (*dto.InfoFile, error) {
+// GetFileInfo retrieves the metadata of a personal or organization file: name,
+// size and number of parts and, when the server provides them, owner, tags,
+// dates, encryption mode and checksum.
+func (c *ClientEphemeralfiles) GetFileInfo(fileID string) (*dto.InfoFile, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), DefaultAPIRequestTimeout)
 	defer cancel()
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.GetFileInformationEndpoint(fileID), nil)
 	if err != nil {
 		return nil, fmt.Errorf("error creating request: %w", err)
 	}
-	req.Header.Set("Authorization", "Bearer "+c.token)
+	c.addAuthHeader(req)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -230,7 +252,7 @@ func (c *ClientEphemeralfiles) getFileInformation(fileID string) (*dto.InfoFile,
 	}()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("%w: %d", ErrUnexpectedStatusCode, resp.StatusCode)
+		return nil, parseError(resp)
 	}
 
 	var fileInfo dto.InfoFile
@@ -251,7 +273,17 @@ func (c *ClientEphemeralfiles) logFileInfo(fileInfo *dto.InfoFile) {
 
 // setupDownloadTransaction creates download transaction and encryption keys.
 func (c *ClientEphemeralfiles) setupDownloadTransaction(fileID string) (string, *E2EKeyBundle, error) {
-	transactionID, pubkey, err := c.CreateNewDownloadTransaction(fileID)
+	return c.negotiateDownloadTransaction(func() (string, string, error) {
+		return c.CreateNewDownloadTransaction(fileID)
+	})
+}
+
+// negotiateDownloadTransaction creates a download transaction with create, which
+// returns its ID and public key, then generates and sends the AES key of the transaction.
+func (c *ClientEphemeralfiles) negotiateDownloadTransaction(
+	create func() (string, string, error),
+) (string, *E2EKeyBundle, error) {
+	transactionID, pubkey, err := create()
 	if err != nil {
 		return "", nil, fmt.Errorf("error creating new download transaction: %w", err)
 	}
@@ -276,34 +308,46 @@ func (c *ClientEphemeralfiles) setupDownloadTransaction(fileID string) (string,
 }
 
 // downloadAllParts downloads all file parts with progress tracking.
+// When resume is enabled, parts already present in a partial download are skipped.
+// The decrypted content is written to sum.
 func (c *ClientEphemeralfiles) downloadAllParts(
-	fileInfo *dto.InfoFile, transactionID string, aesKey []byte, outputFilePath string,
+	fileID string, fileInfo *dto.InfoFile, transactionID string, aesKey []byte, outputFilePath string,
+	sum hash.Hash,
 ) error {
+	// Write all chunks to a temporary file renamed into place once complete
+	file, err := c.openPartialFile(outputFilePath,
+		partialState{FileID: fileID, Size: fileInfo.Size, Encrypted: true}, sum)
+	if err != nil {
+		return err
+	}
+
 	c.InitProgressBar("downloading file...", fileInfo.Size)
 	defer c.CloseProgressBar()
 
-	// Open file once for all chunks
-	// #nosec G304 -- outputFilePath is controlled by user for file download
-	file, err := os.OpenFile(outputFilePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, FilePermission)
-	if err != nil {
-		return fmt.Errorf("error opening output file: %w", err)
+	firstPart, totalBytesWritten := file.state.Parts, file.state.Offset
+	_ = c.bar.Set64(totalBytesWritten)
+	if firstPart > 0 {
+		c.log.Info("Resuming download",
+			slog.Int("firstPart", firstPart),
+			slog.Int64("offset", totalBytesWritten))
 	}
-	defer func() {
-		_ = file.Close()
-	}()
 
 	c.log.Info("Starting download",
 		slog.Int("totalParts", fileInfo.NbParts),
 		slog.Int64("expectedSize", fileInfo.Size))
 
-	totalBytesWritten := int64(0)
-	for i := range fileInfo.NbParts {
+	for i := firstPart; i < fileInfo.NbParts; i++ {
 		c.log.Debug("DownloadE2E", slog.Int("Part", i))
-		chunkSize, err := c.DownloadPartE2EToFile(file, transactionID, aesKey, i)
+		chunkSize, err := c.DownloadPartE2EToFile(file.writer(), transactionID, aesKey, i)
 		if err != nil {
+			file.fail()
 			return fmt.Errorf("error downloading part %d: %w", i, err)
 		}
 		totalBytesWritten += int64(chunkSize)
+		if err := file.checkpoint(i+1, totalBytesWritten); err != nil {
+			file.fail()
+			return err
+		}
 		c.log.Info("Downloaded chunk",
 			slog.Int("part", i),
 			slog.Int("chunkSize", chunkSize),
@@ -316,5 +360,5 @@ func (c *ClientEphemeralfiles) downloadAllParts(
 		slog.Int64("expectedSize", fileInfo.Size),
 		slog.Int64("difference", fileInfo.Size-totalBytesWritten))
 
-	return nil
+	return file.commit(fileInfo.Size)
 }
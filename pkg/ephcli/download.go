@@ -3,11 +3,14 @@ package ephcli
 import (
 	"context"
 	"fmt"
+	"hash"
 	"io"
+	"log/slog"
 	"net/http"
-	"os"
 	"path/filepath"
-	"strings"
+	"time"
+
+	"github.com/ephemeralfiles/eph/pkg/dto"
 )
 
 const (
@@ -22,77 +25,182 @@ func (c *ClientEphemeralfiles) DownloadEndpoint(uuidFile string) string {
 
 // Download downloads a file from the server
 // and saves it to the outputfile
-// If the outputfile is empty, the file will be saved to the current directory
-// with the same name as the file on the server (retrieving the name from the Content-Disposition header).
+// If the outputfile is empty, the file will be saved to the output directory (current directory
+// by default) with the sanitized name of the file on the server (retrieved from the
+// Content-Disposition header).
 func (c *ClientEphemeralfiles) Download(uuidFile string, outputfile string) error {
-	var filename string
+	started, sum := time.Now(), newChecksum()
+	filename, err := c.download(uuidFile, outputfile, sum)
+	c.recordTransfer(Transfer{
+		Direction: TransferDownload, FileID: uuidFile, Path: filename, Encryption: dto.EncryptionModeClear,
+		Checksum: transferChecksum(sum, err),
+	}, started, err)
+	return err
+}
+
+// download downloads a file without encryption, writing its content to sum, and
+// returns its path, empty if the download failed before the path was known.
+func (c *ClientEphemeralfiles) download(uuidFile string, outputfile string, sum hash.Hash) (string, error) {
 	url := c.DownloadEndpoint(uuidFile)
 	ctx := context.Background()
 	// prepare request
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return fmt.Errorf("error creating request: %w", err)
+		return "", fmt.Errorf("error creating request: %w", err)
 	}
 	req.Header.Set("Authorization", "Bearer "+c.token)
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.sendDownloadRequest(req, uuidFile, outputfile)
 	if err != nil {
-		return fmt.Errorf("error sending request: %w", err)
+		return "", err
 	}
-
 	defer func() {
 		_ = resp.Body.Close()
 	}()
 
-	if resp.StatusCode != http.StatusOK {
-		return parseError(resp)
+	filename, err := c.getFileName(resp, outputfile)
+	if err != nil {
+		return "", err
 	}
+	return filename, c.saveResponseBody(resp, filename, uuidFile, sum)
+}
 
-	filename = c.getFileName(resp, outputfile)
-	totalSize := resp.ContentLength
+// sendDownloadRequest sends the download request req of fileID to outputFile.
+// When resume is enabled and a partial download exists, only the missing bytes
+// are requested with a Range header, and the whole file is requested again if
+// the server cannot satisfy the range. The status of the returned response is
+// 200, or 206 for a range.
+func (c *ClientEphemeralfiles) sendDownloadRequest(req *http.Request, fileID, outputFile string) (*http.Response, error) {
+	if offset := c.partialOffset(fileID, outputFile); offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSendingRequest, err)
+	}
+	if resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && req.Header.Get("Range") != "" {
+		_ = resp.Body.Close()
+		c.log.Info("Cannot resume download, restarting", slog.String("range", req.Header.Get("Range")))
+		req.Header.Del("Range")
+		if resp, err = c.httpClient.Do(req); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrSendingRequest, err)
+		}
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		defer func() {
+			_ = resp.Body.Close()
+		}()
+		return nil, parseError(resp)
+	}
+	return resp, nil
+}
 
-	// #nosec G304 -- filename is derived from server response headers for downloads
-	f, err := os.Create(filename)
+// saveResponseBody writes the body of resp to filename through a temporary file.
+// The file is only renamed into place once the whole body has been written, synced
+// and checked against the size of the file.
+// A partial content response continues the partial download of fileID; any other
+// response restarts it, and the whole file is requested again when the partial
+// content starts elsewhere. The whole content of the file is written to sum.
+func (c *ClientEphemeralfiles) saveResponseBody(
+	resp *http.Response, filename string, fileID string, sum hash.Hash,
+) error {
+	start, totalSize := int64(0), resp.ContentLength
+	if resp.StatusCode == http.StatusPartialContent {
+		var err error
+		if start, totalSize, err = parseContentRange(resp.Header.Get("Content-Range")); err != nil {
+			return err
+		}
+	}
+	f, err := c.openPartialFile(filename, partialState{FileID: fileID, Size: totalSize}, sum)
 	if err != nil {
-		return fmt.Errorf("error creating file: %w", err)
+		return err
+	}
+
+	offset, _ := f.Seek(0, io.SeekCurrent)
+	switch {
+	case offset == start && offset > 0:
+		c.log.Info("Resuming download", slog.Int64("offset", offset), slog.Int64("size", totalSize))
+	case offset != start:
+		// The body does not continue the partial file: start it over
+		c.log.Info("Cannot resume download, restarting", slog.Int64("offset", offset), slog.Int64("start", start))
+		if err := f.restart(); err != nil {
+			f.fail()
+			return err
+		}
+		if start > 0 {
+			// The body starts elsewhere in the file: request the whole file once
+			retry, err := c.requestWholeFile(resp.Request)
+			if err != nil {
+				f.fail()
+				return err
+			}
+			if idle, ok := resp.Body.(*idleTimeoutBody); ok {
+				retry.Body = &idleTimeoutBody{ReadCloser: retry.Body, timer: idle.timer, timeout: idle.timeout}
+			}
+			defer func() {
+				_ = retry.Body.Close()
+			}()
+			resp, totalSize = retry, retry.ContentLength
+		}
+		offset = 0
 	}
-	defer func() {
-		_ = f.Close()
-	}()
 
+	body := c.limitReader(resp.Request.Context(), resp.Body)
 	c.InitProgressBar("downloading file...", totalSize)
 	defer c.CloseProgressBar()
+	_ = c.bar.Set64(offset)
 	if !c.noProgressBar {
-		_, err = io.Copy(io.MultiWriter(f, c.bar), resp.Body)
+		_, err = io.Copy(io.MultiWriter(f.writer(), c.bar), body)
 	} else {
-		_, err = io.Copy(f, resp.Body)
+		_, err = io.Copy(f.writer(), body)
 	}
 	if err != nil {
+		f.fail()
 		return fmt.Errorf("error writing file: %w", err)
 	}
-	return nil
+	return f.commit(totalSize)
 }
 
-// getFileName returns outputFileName if not empty
-// If empty, try to retrieve the filename from the Content-Disposition header
-// If not present, return the last part of the URL.
-func (c *ClientEphemeralfiles) getFileName(resp *http.Response, outputfileName string) string {
-	const defaultContentDispositionLength = 2
-	var filename string
+// requestWholeFile sends req again without its Range header, and returns the
+// response once its status is checked.
+func (c *ClientEphemeralfiles) requestWholeFile(req *http.Request) (*http.Response, error) {
+	retry := req.Clone(req.Context())
+	retry.Header.Del("Range")
+	resp, err := c.httpClient.Do(retry)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSendingRequest, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer func() {
+			_ = resp.Body.Close()
+		}()
+		return nil, parseError(resp)
+	}
+	return resp, nil
+}
 
-	if outputfileName != "" {
-		return outputfileName
+// parseContentRange returns the first byte and the size of the file of the
+// Content-Range header of a partial content response, "bytes start-end/size".
+func parseContentRange(header string) (int64, int64, error) {
+	var start, end, size int64
+	if _, err := fmt.Sscanf(header, "bytes %d-%d/%d", &start, &end, &size); err != nil || end != size-1 {
+		return 0, 0, fmt.Errorf("%w: Content-Range %q", ErrRangeNotSatisfied, header)
 	}
+	return start, size, nil
+}
 
-	contentDisposition := resp.Header.Get("Content-Disposition")
-	// extract filename from contentDisposition
-	contentDispositionSplitted := strings.Split(contentDisposition, "=")
-	if len(contentDispositionSplitted) < defaultContentDispositionLength {
-		filename = filepath.Base(resp.Request.URL.Path)
-	} else {
-		filename = contentDispositionSplitted[1]
-		// remove double quotes at the begin and the end from filename with regexp
-		filename = strings.TrimLeft(strings.TrimRight(filename, "\""), "\"")
+// getFileName returns the path where the response body must be written.
+// outputFileName is used if not empty. Otherwise the filename is retrieved from the
+// Content-Disposition header and sanitized, falling back to the last part of the URL.
+// The output directory and the exist policy of the client are applied to the result.
+func (c *ClientEphemeralfiles) getFileName(resp *http.Response, outputFileName string) (string, error) {
+	var serverName string
+	if outputFileName == "" {
+		name, err := ParseContentDispositionFilename(resp.Header.Get("Content-Disposition"))
+		if err != nil {
+			c.log.Debug("unusable Content-Disposition header", slog.String("error", err.Error()))
+		}
+		serverName = name
 	}
-	return filename
+	return c.resolveOutputPath(outputFileName, serverName, filepath.Base(resp.Request.URL.Path))
 }
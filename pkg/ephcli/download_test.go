@@ -1,15 +1,25 @@
 package ephcli_test
 
 import (
+	"bytes"
 	"crypto/rand"
 	"io"
 	"log"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/ephemeralfiles/eph/pkg/ephcli"
+	"github.com/ephemeralfiles/eph/pkg/mockserver"
+	"github.com/ephemeralfiles/eph/pkg/ratelimit"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -71,3 +81,218 @@ func TestDownload(t *testing.T) {
 		os.Remove("testfile-downloaded")
 	})
 }
+
+func TestDownloadAtomic(t *testing.T) {
+	t.Parallel()
+
+	t.Run("complete download replaces the existing file", func(t *testing.T) {
+		t.Parallel()
+		ts := newDispositionServer(t, "data.txt", "new content")
+		dir := t.TempDir()
+		target := filepath.Join(dir, "data.txt")
+		require.NoError(t, os.WriteFile(target, []byte("old"), 0600))
+
+		client := ephcli.NewClient("token")
+		client.SetEndpoint(ts.URL)
+		client.DisableProgressBar()
+
+		require.NoError(t, client.Download("file-id", target))
+		content, err := os.ReadFile(target)
+		require.NoError(t, err)
+		assert.Equal(t, "new content", string(content))
+		assert.NoFileExists(t, target+ephcli.PartialSuffix)
+	})
+
+	t.Run("interrupted download leaves the existing file untouched", func(t *testing.T) {
+		t.Parallel()
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			// Announce more bytes than sent to simulate a connection lost mid-transfer
+			w.Header().Set("Content-Length", "1024")
+			_, _ = w.Write([]byte("truncated"))
+		}))
+		t.Cleanup(ts.Close)
+		dir := t.TempDir()
+		target := filepath.Join(dir, "data.txt")
+		require.NoError(t, os.WriteFile(target, []byte("old"), 0600))
+
+		client := ephcli.NewClient("token")
+		client.SetEndpoint(ts.URL)
+		client.DisableProgressBar()
+
+		require.Error(t, client.Download("file-id", target))
+		content, err := os.ReadFile(target)
+		require.NoError(t, err)
+		assert.Equal(t, "old", string(content))
+		assert.NoFileExists(t, target+ephcli.PartialSuffix)
+	})
+}
+
+func TestDownloadResume(t *testing.T) {
+	t.Parallel()
+
+	clearTests := []struct {
+		name        string
+		ignoreRange bool
+		shiftRange  bool
+		outputFile  string
+		ranges      []string
+	}{
+		{
+			name: "clear download continues with a Range request", outputFile: "data.bin",
+			ranges: []string{"", "bytes=32768-"},
+		},
+		{
+			name:   "clear download to the server name continues with a Range request",
+			ranges: []string{"", "bytes=32768-"},
+		},
+		{
+			name: "clear download restarts when the range is ignored", outputFile: "data.bin", ignoreRange: true,
+			ranges: []string{"", "bytes=32768-"},
+		},
+		{
+			name: "clear download requests the whole file when another range is sent", outputFile: "data.bin",
+			shiftRange: true, ranges: []string{"", "bytes=32768-", ""},
+		},
+	}
+	for _, tt := range clearTests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			content := make([]byte, 64*1024)
+			_, err := rand.Read(content)
+			require.NoError(t, err)
+
+			var calls atomic.Int32
+			var ranges []string
+			var mu sync.Mutex
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				ranges = append(ranges, r.Header.Get("Range"))
+				mu.Unlock()
+				w.Header().Set("Content-Disposition", `attachment; filename="data.bin"`)
+				if calls.Add(1) == 1 {
+					// First attempt: the connection is lost in the middle of the body
+					w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+					_, _ = w.Write(content[:len(content)/2])
+					return
+				}
+				if tt.ignoreRange {
+					r.Header.Del("Range")
+				}
+				if tt.shiftRange && r.Header.Get("Range") != "" {
+					r.Header.Set("Range", "bytes=1024-")
+				}
+				http.ServeContent(w, r, "data.bin", time.Time{}, bytes.NewReader(content))
+			}))
+			t.Cleanup(ts.Close)
+			dir := t.TempDir()
+			target := filepath.Join(dir, "data.bin")
+
+			client := ephcli.NewClient("token")
+			client.SetEndpoint(ts.URL)
+			client.SetOutputDir(dir)
+			client.DisableProgressBar()
+			client.SetResume(true)
+			var checksum string
+			client.SetTransferRecorder(func(transfer ephcli.Transfer) {
+				checksum = transfer.Checksum
+			})
+
+			require.Error(t, client.Download("file-id", tt.outputFile))
+			assert.NoFileExists(t, target)
+			assert.FileExists(t, target+ephcli.PartialSuffix)
+
+			require.NoError(t, client.Download("file-id", tt.outputFile))
+			downloaded, err := os.ReadFile(target)
+			require.NoError(t, err)
+			assert.Equal(t, content, downloaded)
+			assert.NoFileExists(t, target+ephcli.PartialSuffix)
+			assert.NoFileExists(t, target+ephcli.PartialJournalSuffix)
+			// The checksum covers the bytes of the first attempt
+			want, err := ephcli.FileChecksum(target)
+			require.NoError(t, err)
+			assert.Equal(t, want, checksum)
+			// The missing bytes are requested by the first request of the resume
+			assert.Equal(t, tt.ranges, ranges)
+		})
+	}
+
+	t.Run("E2E download only fetches the missing parts", func(t *testing.T) {
+		t.Parallel()
+		srv, err := mockserver.New(mockserver.Options{PartSize: 1024})
+		require.NoError(t, err)
+
+		var failPart atomic.Bool
+		var partsServed []string
+		var mu sync.Mutex
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if strings.Contains(r.URL.Path, "/chunks/") {
+				part := path.Base(r.URL.Path)
+				if part == "2" && failPart.Load() {
+					http.Error(w, "connection lost", http.StatusBadGateway)
+					return
+				}
+				mu.Lock()
+				partsServed = append(partsServed, part)
+				mu.Unlock()
+			}
+			srv.Handler().ServeHTTP(w, r)
+		}))
+		t.Cleanup(ts.Close)
+
+		client := ephcli.NewClient(mockserver.DevToken(mockserver.DefaultEmail, time.Hour))
+		client.SetEndpoint(ts.URL)
+		client.DisableProgressBar()
+		client.SetResume(true)
+
+		dir := t.TempDir()
+		src := filepath.Join(dir, "source.bin")
+		content := make([]byte, 3*1024+512)
+		_, err = rand.Read(content)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(src, content, 0600))
+		_, err = client.Upload(src)
+		require.NoError(t, err)
+		files, err := client.Fetch()
+		require.NoError(t, err)
+		require.Len(t, files, 1)
+
+		target := filepath.Join(dir, "downloaded.bin")
+		failPart.Store(true)
+		require.Error(t, client.DownloadE2E(files[0].FileID, target))
+		assert.NoFileExists(t, target)
+		assert.FileExists(t, target+ephcli.PartialJournalSuffix)
+
+		failPart.Store(false)
+		mu.Lock()
+		partsServed = nil
+		mu.Unlock()
+		var checksum string
+		client.SetTransferRecorder(func(transfer ephcli.Transfer) {
+			checksum = transfer.Checksum
+		})
+		require.NoError(t, client.DownloadE2E(files[0].FileID, target))
+		downloaded, err := os.ReadFile(target)
+		require.NoError(t, err)
+		assert.Equal(t, content, downloaded)
+		assert.Equal(t, []string{"2", "3"}, partsServed)
+		want, err := ephcli.FileChecksum(target)
+		require.NoError(t, err)
+		assert.Equal(t, want, checksum)
+		assert.NoFileExists(t, target+ephcli.PartialJournalSuffix)
+	})
+}
+
+func TestDownloadRateLimit(t *testing.T) {
+	t.Parallel()
+
+	ts := newDispositionServer(t, "data.bin", string(make([]byte, 96*1024)))
+	client := ephcli.NewClient("token")
+	client.SetEndpoint(ts.URL)
+	client.DisableProgressBar()
+	client.SetRateLimiter(ratelimit.New(64 * 1024))
+
+	start := time.Now()
+	require.NoError(t, client.Download("file-id", filepath.Join(t.TempDir(), "data.bin")))
+	// The first second worth of data is the burst, the remaining 32K take half a second
+	assert.GreaterOrEqual(t, time.Since(start), 400*time.Millisecond)
+}
@@ -160,7 +160,7 @@ func (c *ClientEphemeralfiles) SendAESKeyToEndpoint(endpoint, encryptedAESKey st
 	}
 
 	// Set headers
-	req.Header.Set("Authorization", "Bearer "+c.token)
+	c.addAuthHeader(req)
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := c.httpClient.Do(req)
@@ -32,6 +32,15 @@ var (
 	ErrSeekingInFile       = errors.New("error seeking in file")
 	ErrWritingChunkToFile  = errors.New("error writing chunk to file")
 	ErrDecryptingChunk     = errors.New("error decrypting chunk")
+	ErrSizeMismatch        = errors.New("downloaded size does not match the expected size")
+	ErrRangeNotSatisfied   = errors.New("server did not return the requested range")
+	ErrEmptyUpload         = errors.New("nothing to upload, the source is empty")
+	ErrSourceTruncated     = errors.New("source ended before its announced size")
+
+	// Source URL errors.
+	ErrInvalidSourceURL  = errors.New("invalid source URL")
+	ErrSourceStatus      = errors.New("unexpected status fetching the source URL")
+	ErrSourceSizeUnknown = errors.New("source URL response has no Content-Length, its size is needed to upload it")
 
 	// Payload and marshalling errors.
 	ErrMarshallingPayload = errors.New("error marshalling payload")
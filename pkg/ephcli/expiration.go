@@ -0,0 +1,86 @@
+package ephcli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ephemeralfiles/eph/pkg/dto"
+	"github.com/ephemeralfiles/eph/pkg/units"
+)
+
+var (
+	// ErrInvalidExpiration is returned when an expiration is neither a duration nor a date.
+	ErrInvalidExpiration = errors.New("invalid expiration, expected a duration (24h, 7d) or a date (2026-12-31)")
+	// ErrExpirationInPast is returned when an expiration is not in the future.
+	ErrExpirationInPast = errors.New("expiration must be in the future")
+)
+
+// ParseExpiration parses an expiration relative to now. It accepts a duration
+// ("24h", "7d", "1w"), a date ("2026-12-31", meaning the end of that day in the
+// location of now) or an RFC 3339 timestamp.
+func ParseExpiration(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+
+	var expiresAt time.Time
+	if d, err := units.ParseDuration(value); err == nil {
+		expiresAt = now.Add(d)
+	} else if t, err := time.Parse(time.RFC3339, value); err == nil {
+		expiresAt = t
+	} else if day, err := time.ParseInLocation(time.DateOnly, value, now.Location()); err == nil {
+		expiresAt = day.AddDate(0, 0, 1).Add(-time.Second)
+	} else {
+		return time.Time{}, fmt.Errorf("%w: %q", ErrInvalidExpiration, value)
+	}
+
+	if !expiresAt.After(now) {
+		return time.Time{}, fmt.Errorf("%w: %s", ErrExpirationInPast, expiresAt.Format(time.RFC3339))
+	}
+	return expiresAt, nil
+}
+
+// formatExpiration formats an expiration as sent to the API.
+func formatExpiration(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// UpdateFileExpirationEndpoint returns the API endpoint URL for changing the expiration of a file.
+func (c *ClientEphemeralfiles) UpdateFileExpirationEndpoint(fileID string) string {
+	return fmt.Sprintf("%s/%s/files/%s/expiration", c.endpoint, apiVersion, fileID)
+}
+
+// UpdateFileExpiration extends or shortens the retention of a file so that it
+// expires at expiresAt.
+func (c *ClientEphemeralfiles) UpdateFileExpiration(fileID string, expiresAt time.Time) (*dto.File, error) {
+	payload, err := json.Marshal(map[string]string{"expiration_date": formatExpiration(expiresAt)})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMarshallingPayload, err)
+	}
+
+	req, cancel, err := c.createRequestWithTimeout(http.MethodPut, c.UpdateFileExpirationEndpoint(fileID),
+		strings.NewReader(string(payload)))
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.doRequestWithAuth(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	var file dto.File
+	if err := json.NewDecoder(resp.Body).Decode(&file); err != nil {
+		return nil, fmt.Errorf("failed to decode file response: %w", err)
+	}
+
+	return &file, nil
+}
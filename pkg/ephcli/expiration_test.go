@@ -0,0 +1,100 @@
+package ephcli_test
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ephemeralfiles/eph/pkg/dto"
+	"github.com/ephemeralfiles/eph/pkg/ephcli"
+	"github.com/ephemeralfiles/eph/pkg/mockserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExpiration(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 10, 18, 15, 30, 0, 0, time.UTC)
+	tests := []struct {
+		input    string
+		expected time.Time
+		wantErr  error
+	}{
+		{input: "24h", expected: now.Add(24 * time.Hour)},
+		{input: "7d", expected: now.AddDate(0, 0, 7)},
+		{input: "2026-12-31", expected: time.Date(2026, 12, 31, 23, 59, 59, 0, time.UTC)},
+		{input: "2026-11-01T08:00:00Z", expected: time.Date(2026, 11, 1, 8, 0, 0, 0, time.UTC)},
+		{input: "2026-01-01", wantErr: ephcli.ErrExpirationInPast},
+		{input: "0d", wantErr: ephcli.ErrExpirationInPast},
+		{input: "next week", wantErr: ephcli.ErrInvalidExpiration},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+			got, err := ephcli.ParseExpiration(tt.input, now)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, tt.expected.Equal(got), "expected %s, got %s", tt.expected, got)
+		})
+	}
+}
+
+func TestUploadExpiration(t *testing.T) {
+	t.Parallel()
+
+	srv, err := mockserver.New(mockserver.Options{Seed: true})
+	require.NoError(t, err)
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+
+	client := ephcli.NewClient(mockserver.DevToken(mockserver.DefaultEmail, time.Hour))
+	client.SetEndpoint(ts.URL)
+	client.DisableProgressBar()
+
+	src := filepath.Join(t.TempDir(), "report.pdf")
+	require.NoError(t, os.WriteFile(src, []byte("report"), 0600))
+	expiresAt := time.Now().Add(3 * time.Hour).UTC().Truncate(time.Second)
+
+	t.Run("E2E upload", func(t *testing.T) {
+		uploaded, err := client.UploadE2EWithOptions(src, ephcli.UploadOptions{ExpiresAt: expiresAt})
+		require.NoError(t, err)
+		fileID := uploaded.FileID
+		files, err := client.Fetch()
+		require.NoError(t, err)
+		idx := slices.IndexFunc(files, func(f dto.File) bool { return f.FileID == fileID })
+		require.GreaterOrEqual(t, idx, 0)
+		assert.True(t, expiresAt.Equal(files[idx].ExpirationDate))
+	})
+
+	t.Run("clear organization upload", func(t *testing.T) {
+		file, err := client.UploadOrganizationFileWithOptions(src, ephcli.UploadOptions{
+			OrganizationID: mockserver.FixtureOrganizationID,
+			Tags:           []string{"report"},
+			ExpiresAt:      expiresAt,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"report"}, file.Tags)
+		assert.Equal(t, expiresAt.Format(time.RFC3339), file.ExpirationDate)
+	})
+
+	t.Run("update expiration", func(t *testing.T) {
+		files, err := client.Fetch()
+		require.NoError(t, err)
+		require.NotEmpty(t, files)
+
+		extended := expiresAt.Add(48 * time.Hour)
+		file, err := client.UpdateFileExpiration(files[0].FileID, extended)
+		require.NoError(t, err)
+		assert.True(t, extended.Equal(file.ExpirationDate))
+
+		_, err = client.UpdateFileExpiration(files[0].FileID, time.Now().Add(-time.Hour))
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,58 @@
+package ephcli_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ephemeralfiles/eph/pkg/dto"
+	"github.com/ephemeralfiles/eph/pkg/ephcli"
+	"github.com/ephemeralfiles/eph/pkg/mockserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetFileInfo(t *testing.T) {
+	t.Parallel()
+
+	srv, err := mockserver.New(mockserver.Options{Seed: true})
+	require.NoError(t, err)
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+
+	client := ephcli.NewClient(mockserver.DevToken(mockserver.DefaultEmail, time.Hour))
+	client.SetEndpoint(ts.URL)
+	client.DisableProgressBar()
+
+	content := []byte("quarterly report")
+	src := filepath.Join(t.TempDir(), "report.pdf")
+	require.NoError(t, os.WriteFile(src, content, 0600))
+	uploaded, err := client.UploadE2EWithOptions(src, ephcli.UploadOptions{
+		OrganizationID: mockserver.FixtureOrganizationID,
+		Tags:           []string{"report", "q1"},
+	})
+	require.NoError(t, err)
+	fileID := uploaded.FileID
+
+	info, err := client.GetFileInfo(fileID)
+	require.NoError(t, err)
+	sum := sha256.Sum256(content)
+	assert.Equal(t, fileID, info.FileID)
+	assert.Equal(t, "report.pdf", info.Filename)
+	assert.Equal(t, int64(len(content)), info.Size)
+	assert.Equal(t, 1, info.NbParts)
+	assert.Equal(t, mockserver.DefaultEmail, info.OwnerEmail)
+	assert.Equal(t, mockserver.FixtureOrganizationID, info.OrganizationID)
+	assert.Equal(t, []string{"report", "q1"}, info.Tags)
+	assert.False(t, info.UploadDate.IsZero())
+	assert.True(t, info.ExpirationDate.After(info.UploadDate))
+	assert.Equal(t, dto.EncryptionModeE2E, info.EncryptionMode)
+	assert.Equal(t, "sha256:"+hex.EncodeToString(sum[:]), info.Checksum)
+
+	_, err = client.GetFileInfo("missing-file")
+	require.Error(t, err)
+}
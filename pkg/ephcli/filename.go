@@ -0,0 +1,169 @@
+package ephcli
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+const (
+	// maxFilenameLength is the maximum length in bytes of a downloaded file name.
+	maxFilenameLength = 255
+	// maxRenameAttempts bounds the search for a free name with ExistRename.
+	maxRenameAttempts = 1000
+	// outputDirPerm is the permission of the output directory when it has to be created.
+	outputDirPerm = 0750
+)
+
+// ExistPolicy tells what a download does when the target file already exists.
+type ExistPolicy int
+
+const (
+	// ExistOverwrite replaces the existing file (default).
+	ExistOverwrite ExistPolicy = iota
+	// ExistSkip leaves the existing file untouched and returns ErrDownloadSkipped.
+	ExistSkip
+	// ExistRename writes to a free name such as "report (1).pdf".
+	ExistRename
+)
+
+var (
+	// ErrInvalidFilename is returned when a server-provided filename cannot be used safely.
+	ErrInvalidFilename = errors.New("invalid filename")
+	// ErrDownloadSkipped is returned when the target file exists and the policy is ExistSkip.
+	ErrDownloadSkipped = errors.New("file already exists, download skipped")
+	// ErrNoFreeFilename is returned when ExistRename cannot find an unused name.
+	ErrNoFreeFilename = errors.New("no free filename found")
+)
+
+// SetOutputDir sets the directory where files named by the server are written.
+func (c *ClientEphemeralfiles) SetOutputDir(dir string) {
+	c.outputDir = dir
+}
+
+// SetExistPolicy sets the behavior of downloads when the target file already exists.
+func (c *ClientEphemeralfiles) SetExistPolicy(policy ExistPolicy) {
+	c.existPolicy = policy
+}
+
+// ParseContentDispositionFilename extracts and sanitizes the filename of a
+// Content-Disposition header. RFC 5987 encoded values (the filename* parameter) are
+// decoded and take precedence over the plain filename parameter.
+func ParseContentDispositionFilename(header string) (string, error) {
+	_, params, err := mime.ParseMediaType(header)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidFilename, err)
+	}
+	return SanitizeFilename(params["filename"])
+}
+
+// SanitizeFilename reduces a server-provided name to a single, safe path element:
+// directories are dropped, control and reserved characters are replaced and the
+// result is truncated to a portable length.
+func SanitizeFilename(name string) (string, error) {
+	// Treat backslashes as separators too so that "..\\..\\x" cannot escape on Windows
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsControl(r):
+			return -1
+		case strings.ContainsRune(`<>:"/|?*`, r):
+			return '_'
+		default:
+			return r
+		}
+	}, name)
+	name = strings.TrimSpace(name)
+
+	if name == "" || name == "." || name == ".." {
+		return "", ErrInvalidFilename
+	}
+	if len(name) > maxFilenameLength {
+		ext := filepath.Ext(name)
+		if len(ext) >= maxFilenameLength {
+			ext = ""
+		}
+		name = strings.ToValidUTF8(name[:maxFilenameLength-len(ext)], "") + ext
+	}
+	return name, nil
+}
+
+// ValidateRemoteName checks that name can be used as the name of a file on the
+// server: a single, non-empty path element without control characters.
+func ValidateRemoteName(name string) error {
+	switch {
+	case strings.TrimSpace(name) == "", name == ".", name == "..":
+		return fmt.Errorf("%w: empty name", ErrInvalidFilename)
+	case strings.ContainsAny(name, `/\`):
+		return fmt.Errorf("%w: %q contains a path separator", ErrInvalidFilename, name)
+	case strings.IndexFunc(name, unicode.IsControl) >= 0:
+		return fmt.Errorf("%w: %q contains control characters", ErrInvalidFilename, name)
+	case len(name) > maxFilenameLength:
+		return fmt.Errorf("%w: longer than %d bytes", ErrInvalidFilename, maxFilenameLength)
+	}
+	return nil
+}
+
+// resolveOutputPath returns the path a download must be written to.
+// An explicit outputFile is trusted as given (relative paths are placed in the output
+// directory); a server-provided name is sanitized first, falling back to fallbackName.
+// The exist policy is then applied to the resulting path.
+func (c *ClientEphemeralfiles) resolveOutputPath(outputFile, serverName, fallbackName string) (string, error) {
+	target := outputFile
+	if target == "" {
+		name, err := SanitizeFilename(serverName)
+		if err != nil {
+			name, err = SanitizeFilename(fallbackName)
+			if err != nil {
+				return "", err
+			}
+		}
+		target = name
+	}
+	if c.outputDir != "" && !filepath.IsAbs(target) {
+		if err := os.MkdirAll(c.outputDir, outputDirPerm); err != nil {
+			return "", fmt.Errorf("error creating output directory: %w", err)
+		}
+		target = filepath.Join(c.outputDir, target)
+	}
+	return c.applyExistPolicy(target)
+}
+
+// applyExistPolicy checks whether target exists and applies the exist policy.
+func (c *ClientEphemeralfiles) applyExistPolicy(target string) (string, error) {
+	if !fileExists(target) {
+		return target, nil
+	}
+	switch c.existPolicy {
+	case ExistSkip:
+		return "", fmt.Errorf("%w: %s", ErrDownloadSkipped, target)
+	case ExistRename:
+		return nextFreeName(target, fileExists)
+	default:
+		return target, nil
+	}
+}
+
+// nextFreeName returns the first "name (n).ext" variant of target that is not taken.
+func nextFreeName(target string, taken func(string) bool) (string, error) {
+	ext := filepath.Ext(target)
+	base := strings.TrimSuffix(target, ext)
+	for i := 1; i <= maxRenameAttempts; i++ {
+		candidate := fmt.Sprintf("%s (%d)%s", base, i, ext)
+		if !taken(candidate) {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrNoFreeFilename, target)
+}
+
+// fileExists reports whether a file or directory exists at path.
+func fileExists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
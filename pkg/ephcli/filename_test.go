@@ -0,0 +1,175 @@
+package ephcli_test
+
+import (
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ephemeralfiles/eph/pkg/ephcli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanitizeFilename(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		wantErr  bool
+	}{
+		{name: "plain name", input: "report.pdf", expected: "report.pdf"},
+		{name: "parent traversal", input: "../../.bashrc", expected: ".bashrc"},
+		{name: "absolute path", input: "/etc/passwd", expected: "passwd"},
+		{name: "windows traversal", input: `..\..\evil.exe`, expected: "evil.exe"},
+		{name: "reserved characters", input: `a:b*c?.txt`, expected: "a_b_c_.txt"},
+		{name: "control characters", input: "bad\x00name\n.txt", expected: "badname.txt"},
+		{name: "unicode", input: "résumé.pdf", expected: "résumé.pdf"},
+		{name: "empty", input: "", wantErr: true},
+		{name: "dot dot", input: "..", wantErr: true},
+		{name: "ends with parent", input: "dir/..", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := ephcli.SanitizeFilename(tt.input)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ephcli.ErrInvalidFilename)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+
+	t.Run("long names keep their extension", func(t *testing.T) {
+		t.Parallel()
+		got, err := ephcli.SanitizeFilename(strings.Repeat("a", 300) + ".tar.gz")
+		require.NoError(t, err)
+		assert.Len(t, got, 255)
+		assert.True(t, strings.HasSuffix(got, ".gz"))
+	})
+}
+
+func TestParseContentDispositionFilename(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		header   string
+		expected string
+		wantErr  bool
+	}{
+		{name: "quoted", header: `attachment; filename="report.pdf"`, expected: "report.pdf"},
+		{name: "unquoted", header: `attachment; filename=report.pdf`, expected: "report.pdf"},
+		{name: "RFC 5987", header: `attachment; filename*=UTF-8''r%C3%A9sum%C3%A9.pdf`, expected: "résumé.pdf"},
+		{
+			name:     "RFC 5987 takes precedence",
+			header:   `attachment; filename="fallback.pdf"; filename*=UTF-8''real.pdf`,
+			expected: "real.pdf",
+		},
+		{name: "traversal", header: `attachment; filename="../../.bashrc"`, expected: ".bashrc"},
+		{name: "equals in name", header: `attachment; filename="a=b.txt"`, expected: "a=b.txt"},
+		{name: "missing filename", header: `attachment`, wantErr: true},
+		{name: "empty header", header: ``, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := ephcli.ParseContentDispositionFilename(tt.header)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+// newDispositionServer serves content with the given Content-Disposition filename.
+func newDispositionServer(t *testing.T, filename, content string) *httptest.Server {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+		_, _ = w.Write([]byte(content))
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestDownloadOutputPath(t *testing.T) {
+	t.Parallel()
+
+	t.Run("malicious filename stays in the output directory", func(t *testing.T) {
+		t.Parallel()
+		ts := newDispositionServer(t, "../../escape.txt", "payload")
+		dir := t.TempDir()
+		outDir := filepath.Join(dir, "out", "nested")
+
+		client := ephcli.NewClient("token")
+		client.SetEndpoint(ts.URL)
+		client.DisableProgressBar()
+		client.SetOutputDir(outDir)
+
+		require.NoError(t, client.Download("file-id", ""))
+		content, err := os.ReadFile(filepath.Join(outDir, "escape.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, "payload", string(content))
+		assert.NoFileExists(t, filepath.Join(dir, "escape.txt"))
+	})
+
+	t.Run("existing file policies", func(t *testing.T) {
+		t.Parallel()
+		ts := newDispositionServer(t, "data.txt", "new")
+
+		tests := []struct {
+			name     string
+			policy   ephcli.ExistPolicy
+			expected map[string]string
+			wantErr  error
+		}{
+			{name: "overwrite", policy: ephcli.ExistOverwrite, expected: map[string]string{"data.txt": "new"}},
+			{
+				name: "skip", policy: ephcli.ExistSkip,
+				expected: map[string]string{"data.txt": "old"}, wantErr: ephcli.ErrDownloadSkipped,
+			},
+			{
+				name: "rename", policy: ephcli.ExistRename,
+				expected: map[string]string{"data.txt": "old", "data (1).txt": "new"},
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				t.Parallel()
+				dir := t.TempDir()
+				require.NoError(t, os.WriteFile(filepath.Join(dir, "data.txt"), []byte("old"), 0600))
+
+				client := ephcli.NewClient("token")
+				client.SetEndpoint(ts.URL)
+				client.DisableProgressBar()
+				client.SetOutputDir(dir)
+				client.SetExistPolicy(tt.policy)
+
+				err := client.Download("file-id", "")
+				if tt.wantErr != nil {
+					require.ErrorIs(t, err, tt.wantErr)
+				} else {
+					require.NoError(t, err)
+				}
+				for name, want := range tt.expected {
+					content, err := os.ReadFile(filepath.Join(dir, name))
+					require.NoError(t, err)
+					assert.Equal(t, want, string(content), name)
+				}
+			})
+		}
+	})
+}
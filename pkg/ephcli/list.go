@@ -1,17 +1,16 @@
 package ephcli
 
 import (
-	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/ephemeralfiles/eph/pkg/dto"
-	"github.com/pterm/pterm"
-	"gopkg.in/yaml.v2"
+	"github.com/ephemeralfiles/eph/pkg/render"
 )
 
 // FilesEndpoint returns the endpoint for the files.
@@ -49,58 +48,46 @@ func (c *ClientEphemeralfiles) Fetch() (dto.FileList, error) {
 	return fl, nil
 }
 
+// printColumns are the columns printed by Print and PrintCSV.
+var printColumns = []render.Column[dto.File]{
+	{Name: "ID", Value: func(f dto.File) string { return f.FileID }},
+	{Name: "Filename", Value: func(f dto.File) string { return f.FileName }},
+	{Name: "Size", Value: func(f dto.File) string { return strconv.FormatInt(f.Size, 10) }},
+	{Name: "Expiration date", Value: func(f dto.File) string { return f.ExpirationDate.Format(time.DateTime) }},
+}
+
 // Print prints the list of files as a table.
+//
+// Deprecated: use render.List, which also prints the other formats.
 func Print(fl *dto.FileList) error {
-	tData := pterm.TableData{
-		{"ID", "Filename", "Size", "Expiration date"},
-	}
-	for _, file := range *fl {
-		tData = append(tData, []string{file.FileID, file.FileName,
-			strconv.FormatInt(file.Size, 10), file.ExpirationDate.Format("2006-01-02 15:04:05")})
-	}
-	// Create a table with a header and the defined data, then render it
-	err := pterm.DefaultTable.WithHasHeader().WithData(tData).Render()
-	if err != nil {
-		return fmt.Errorf("error rendering table: %w", err)
-	}
-	return nil
+	return printFiles(fl, render.FormatTable)
 }
 
 // PrintCSV prints the list of files as a CSV.
+//
+// Deprecated: use render.List with render.FormatCSV.
 func PrintCSV(fl *dto.FileList) error {
-	csvwriter := csv.NewWriter(os.Stdout)
-	err := csvwriter.Write([]string{"ID", "Filename", "Size", "Expiration date"})
-	if err != nil {
-		return fmt.Errorf("error writing CSV header: %w", err)
-	}
-
-	for _, file := range *fl {
-		err = csvwriter.Write([]string{file.FileID, file.FileName,
-			strconv.FormatInt(file.Size, 10), file.ExpirationDate.Format("2006-01-02 15:04:05")})
-		if err != nil {
-			return fmt.Errorf("error writing CSV row: %w", err)
-		}
-	}
-
-	csvwriter.Flush()
-	return nil
+	return printFiles(fl, render.FormatCSV)
 }
 
 // PrintJSON prints the list of files as JSON.
+//
+// Deprecated: use render.List with render.FormatJSON.
 func PrintJSON(fl *dto.FileList) error {
-	err := json.NewEncoder(os.Stdout).Encode(fl)
-	if err != nil {
-		return fmt.Errorf("error encoding JSON: %w", err)
-	}
-	return nil
+	return printFiles(fl, render.FormatJSON)
 }
 
 // PrintYAML prints the list of files as YAML.
+//
+// Deprecated: use render.List with render.FormatYAML.
 func PrintYAML(fl *dto.FileList) error {
-	yamlData, err := yaml.Marshal(fl)
-	if err != nil {
-		return fmt.Errorf("error marshalling YAML: %w", err)
+	return printFiles(fl, render.FormatYAML)
+}
+
+// printFiles prints the list of files to stdout in format.
+func printFiles(fl *dto.FileList, format string) error {
+	if err := render.List(os.Stdout, *fl, printColumns, render.Options{Format: format}); err != nil {
+		return fmt.Errorf("error printing files: %w", err)
 	}
-	fmt.Println(string(yamlData))
 	return nil
 }
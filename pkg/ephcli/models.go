@@ -5,10 +5,12 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"math"
 	"net/http"
 	"time"
 
 	"github.com/ephemeralfiles/eph/pkg/logger"
+	"github.com/ephemeralfiles/eph/pkg/ratelimit"
 	"github.com/schollz/progressbar/v3"
 )
 
@@ -21,6 +23,14 @@ const ChunkUploadTimeout = 30 * time.Minute
 // ChunkDownloadTimeout is the timeout for chunk download requests (longer for large files).
 const ChunkDownloadTimeout = 30 * time.Minute
 
+// transferTimeoutMargin is the factor applied to the time a rate limited
+// transfer needs, to leave room for the latency and the server.
+const transferTimeoutMargin = 2
+
+// DownloadIdleTimeout is the longest wait for the response headers of a
+// streamed download, then for each read of its body.
+const DownloadIdleTimeout = 5 * time.Minute
+
 // apiVersion is the version of the API that the client expects.
 const apiVersion string = "api/v1"
 
@@ -29,12 +39,21 @@ const defaultEndpoint string = "https://ephemeralfiles.com"
 
 // ClientEphemeralfiles is the client to interact with the API.
 type ClientEphemeralfiles struct {
-	httpClient    *http.Client
-	token         string
-	endpoint      string
-	noProgressBar bool
-	bar           *progressbar.ProgressBar
-	log           *slog.Logger
+	httpClient     *http.Client
+	token          string
+	endpoint       string
+	noProgressBar  bool
+	bar            *progressbar.ProgressBar
+	log            *slog.Logger
+	outputDir      string
+	existPolicy    ExistPolicy
+	resume         bool
+	limiter        *ratelimit.Limiter
+	chunkSize      int64
+	adaptiveChunks bool
+	recorder       TransferRecorder
+	progress       ProgressFunc
+	stopProgress   func()
 }
 
 // NewClient creates a new client.
@@ -48,6 +67,16 @@ func NewClient(token string) *ClientEphemeralfiles {
 	}
 }
 
+// Clone returns a copy of the client to run transfers concurrently. The copy
+// shares the HTTP client, logger and rate limiter of c, but has its own
+// progress bar.
+func (c *ClientEphemeralfiles) Clone() *ClientEphemeralfiles {
+	clone := *c
+	clone.bar = nil
+	clone.stopProgress = nil
+	return &clone
+}
+
 // SetLogger sets the logger.
 func (c *ClientEphemeralfiles) SetLogger(logger *slog.Logger) {
 	c.log = logger
@@ -75,10 +104,50 @@ func (c *ClientEphemeralfiles) SetHTTPClient(client *http.Client) {
 	c.httpClient = client
 }
 
+// SetRateLimiter limits the bandwidth of uploads and downloads.
+// The limiter can be shared with other clients to cap their combined bandwidth.
+// A nil limiter disables the limit.
+func (c *ClientEphemeralfiles) SetRateLimiter(limiter *ratelimit.Limiter) {
+	c.limiter = limiter
+}
+
+// TransferTimeout returns the timeout of a transfer of size bytes limited to
+// rate bytes per second: base, or twice the time the transfer needs at rate
+// when longer, so that a large chunk sent at a low rate does not time out. A
+// rate of zero or less means unlimited.
+func TransferTimeout(base time.Duration, size, rate int64) time.Duration {
+	if rate <= 0 || size <= 0 {
+		return base
+	}
+	needed := float64(size) / float64(rate) * transferTimeoutMargin * float64(time.Second)
+	if needed >= math.MaxInt64 {
+		return time.Duration(math.MaxInt64)
+	}
+	return max(base, time.Duration(needed))
+}
+
+// transferTimeout returns the timeout of a transfer of size bytes within the
+// rate currently allowed by the rate limiter of the client.
+func (c *ClientEphemeralfiles) transferTimeout(base time.Duration, size int64) time.Duration {
+	if c.limiter == nil {
+		return base
+	}
+	return TransferTimeout(base, size, c.limiter.Rate())
+}
+
+// limitReader returns r limited by the rate limiter of the client, if any.
+func (c *ClientEphemeralfiles) limitReader(ctx context.Context, r io.Reader) io.Reader {
+	return c.limiter.Reader(ctx, r)
+}
+
 // HTTP utility methods to reduce duplication
 
-// addAuthHeader adds the Bearer token to the request.
+// addAuthHeader adds the Bearer token to the request, unless the client is
+// anonymous (shared links are downloaded without a token).
 func (c *ClientEphemeralfiles) addAuthHeader(req *http.Request) {
+	if c.token == "" {
+		return
+	}
 	req.Header.Set("Authorization", "Bearer "+c.token)
 }
 
@@ -3,9 +3,11 @@ package ephcli_test
 import (
 	"fmt"
 	"log/slog"
+	"math"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/ephemeralfiles/eph/pkg/ephcli"
 	"github.com/ephemeralfiles/eph/pkg/logger"
@@ -428,3 +430,36 @@ func TestClientEdgeCases(t *testing.T) {
 		assert.Contains(t, err.Error(), "500")
 	})
 }
+
+func TestTransferTimeout(t *testing.T) {
+	t.Parallel()
+
+	const rate50K = 50 * 1024
+	// A default chunk at 50K per second takes about 44 minutes
+	needed := time.Duration(float64(ephcli.DefaultChunkSize) / rate50K * float64(time.Second))
+	require.Greater(t, needed, 40*time.Minute)
+
+	tests := []struct {
+		name     string
+		size     int64
+		rate     int64
+		expected time.Duration
+	}{
+		{name: "unlimited", size: ephcli.DefaultChunkSize, expected: ephcli.ChunkUploadTimeout},
+		{name: "fast enough for the base timeout", size: ephcli.DefaultChunkSize, rate: 10 * 1024 * 1024,
+			expected: ephcli.ChunkUploadTimeout},
+		{name: "default chunk at 50K per second", size: ephcli.DefaultChunkSize, rate: rate50K, expected: 2 * needed},
+		{name: "unknown size", size: -1, rate: rate50K, expected: ephcli.ChunkUploadTimeout},
+		{name: "no overflow", size: 1 << 60, rate: 1, expected: time.Duration(math.MaxInt64)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			timeout := ephcli.TransferTimeout(ephcli.ChunkUploadTimeout, tt.size, tt.rate)
+			assert.InDelta(t, float64(tt.expected), float64(timeout), float64(time.Millisecond))
+			if tt.rate > 0 && tt.size > 0 {
+				assert.Greater(t, timeout, time.Duration(float64(tt.size)/float64(tt.rate)*float64(time.Second)))
+			}
+		})
+	}
+}
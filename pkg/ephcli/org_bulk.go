@@ -0,0 +1,183 @@
+package ephcli
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ephemeralfiles/eph/pkg/dto"
+)
+
+// DefaultBulkConcurrency is the number of parallel transfers of bulk operations.
+const DefaultBulkConcurrency = 4
+
+// OrganizationFileFilter selects the files of an organization for bulk operations.
+// The zero value selects every active file.
+type OrganizationFileFilter struct {
+	// Tags selects the files having all these tags.
+	Tags []string
+	// Owner selects the files uploaded by this user (email or ID).
+	Owner string
+	// Recent keeps only the given number of most recent files, when positive.
+	Recent int
+}
+
+// SelectOrganizationFiles returns the active files of an organization matching
+// filter, most recent first.
+func (c *ClientEphemeralfiles) SelectOrganizationFiles(
+	orgID string, filter OrganizationFileFilter,
+) ([]dto.OrganizationFile, error) {
+	var (
+		files []dto.OrganizationFile
+		err   error
+	)
+	if len(filter.Tags) > 0 {
+		files, err = c.ListAllOrganizationFilesByTags(orgID, filter.Tags)
+	} else {
+		files, err = c.ListAllOrganizationFiles(orgID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	files = slices.DeleteFunc(files, func(f dto.OrganizationFile) bool {
+		if filter.Owner != "" && !strings.EqualFold(f.OwnerEmail, filter.Owner) && f.OwnerID != filter.Owner {
+			return true
+		}
+		// Expired files cannot be downloaded anymore
+		expiration, err := time.Parse(time.RFC3339, f.ExpirationDate)
+		return err == nil && expiration.Before(now)
+	})
+	slices.SortStableFunc(files, func(a, b dto.OrganizationFile) int {
+		return cmp.Compare(b.UploadDateBegin, a.UploadDateBegin)
+	})
+	if filter.Recent > 0 && len(files) > filter.Recent {
+		files = files[:filter.Recent]
+	}
+	return files, nil
+}
+
+// BulkDownloadItem is a file of a bulk download and the path it is written to.
+type BulkDownloadItem struct {
+	File dto.OrganizationFile
+	// Target is the path the file is written to, empty when Skipped.
+	Target string
+	// Skipped is set when the target exists and the exist policy is ExistSkip.
+	Skipped bool
+}
+
+// PlanBulkDownload chooses the path of each file in the output directory of
+// the client, applying the exist policy to existing files. Files of the batch
+// sharing a name are always given distinct names, so that parallel downloads
+// never write to the same path.
+func (c *ClientEphemeralfiles) PlanBulkDownload(files []dto.OrganizationFile) ([]BulkDownloadItem, error) {
+	reserved := make(map[string]bool, len(files))
+	taken := func(path string) bool {
+		return reserved[path] || fileExists(path)
+	}
+
+	items := make([]BulkDownloadItem, 0, len(files))
+	for _, f := range files {
+		name, err := SanitizeFilename(f.Filename)
+		if err != nil {
+			name = f.ID
+		}
+		target := filepath.Join(c.outputDir, name)
+
+		err = nil
+		switch {
+		case reserved[target]:
+			target, err = nextFreeName(target, taken)
+		case fileExists(target) && c.existPolicy == ExistSkip:
+			items = append(items, BulkDownloadItem{File: f, Skipped: true})
+			continue
+		case fileExists(target) && c.existPolicy == ExistRename:
+			target, err = nextFreeName(target, taken)
+		}
+		if err != nil {
+			return nil, err
+		}
+		reserved[target] = true
+		items = append(items, BulkDownloadItem{File: f, Target: target})
+	}
+	return items, nil
+}
+
+// DownloadOrganizationFiles downloads the planned items with at most
+// concurrency downloads in progress, each with its own copy of the client and
+// without progress bar. Files are downloaded with E2E encryption, or without
+// encryption when clearTransfer is set. done, when set, is called as each download
+// ends, with ErrDownloadSkipped for skipped items; calls are serialized. It
+// returns the number of failed downloads.
+func (c *ClientEphemeralfiles) DownloadOrganizationFiles(
+	items []BulkDownloadItem, concurrency int, clearTransfer bool, done func(BulkDownloadItem, error),
+) (int, error) {
+	if c.outputDir != "" {
+		if err := os.MkdirAll(c.outputDir, outputDirPerm); err != nil {
+			return 0, fmt.Errorf("error creating output directory: %w", err)
+		}
+	}
+
+	var (
+		mu     sync.Mutex
+		failed int
+	)
+	forEachConcurrently(len(items), concurrency, func(i int) {
+		item := items[i]
+		var err error
+		if item.Skipped {
+			err = fmt.Errorf("%w: %s", ErrDownloadSkipped, item.File.Filename)
+		} else {
+			// Targets are already resolved: they are written as given
+			client := c.Clone()
+			client.DisableProgressBar()
+			client.SetOutputDir("")
+			client.SetExistPolicy(ExistOverwrite)
+			if clearTransfer {
+				err = client.DownloadOrganizationFile(item.File.ID, item.Target)
+			} else {
+				err = client.DownloadE2E(item.File.ID, item.Target)
+			}
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil && !item.Skipped {
+			failed++
+		}
+		if done != nil {
+			done(item, err)
+		}
+	})
+	return failed, nil
+}
+
+// forEachConcurrently calls fn for each index below n, with at most
+// concurrency calls in progress.
+func forEachConcurrently(n, concurrency int, fn func(i int)) {
+	if concurrency <= 0 {
+		concurrency = DefaultBulkConcurrency
+	}
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range min(concurrency, n) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				fn(i)
+			}
+		}()
+	}
+	for i := range n {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
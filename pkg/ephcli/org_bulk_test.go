@@ -0,0 +1,197 @@
+package ephcli_test
+
+import (
+	"errors"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ephemeralfiles/eph/pkg/dto"
+	"github.com/ephemeralfiles/eph/pkg/ephcli"
+	"github.com/ephemeralfiles/eph/pkg/mockserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSeededClient starts a seeded mock server and returns a client configured for it.
+func newSeededClient(t *testing.T) *ephcli.ClientEphemeralfiles {
+	t.Helper()
+	srv, err := mockserver.New(mockserver.Options{Seed: true})
+	require.NoError(t, err)
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+
+	client := ephcli.NewClient(mockserver.DevToken(mockserver.DefaultEmail, time.Hour))
+	client.SetEndpoint(ts.URL)
+	client.DisableProgressBar()
+	return client
+}
+
+// fileIDs returns the IDs of files.
+func fileIDs(files []dto.OrganizationFile) []string {
+	var ids []string
+	for _, f := range files {
+		ids = append(ids, f.ID)
+	}
+	return ids
+}
+
+func TestSelectOrganizationFiles(t *testing.T) {
+	t.Parallel()
+
+	client := newSeededClient(t)
+	tests := []struct {
+		name     string
+		filter   ephcli.OrganizationFileFilter
+		expected []string
+	}{
+		{
+			name: "active files, most recent first",
+			expected: []string{
+				"0c1d2e3f-0000-4000-8000-000000000013",
+				"0c1d2e3f-0000-4000-8000-000000000012",
+				"0c1d2e3f-0000-4000-8000-000000000011",
+			},
+		},
+		{
+			name:   "tags",
+			filter: ephcli.OrganizationFileFilter{Tags: []string{"invoice", "q1"}},
+			expected: []string{
+				"0c1d2e3f-0000-4000-8000-000000000012",
+				"0c1d2e3f-0000-4000-8000-000000000011",
+			},
+		},
+		{
+			name:     "recent",
+			filter:   ephcli.OrganizationFileFilter{Tags: []string{"invoice"}, Recent: 1},
+			expected: []string{"0c1d2e3f-0000-4000-8000-000000000012"},
+		},
+		{name: "expired files are excluded", filter: ephcli.OrganizationFileFilter{Tags: []string{"archive"}}},
+		{name: "other owner", filter: ephcli.OrganizationFileFilter{Owner: "someone@example.com"}},
+		{
+			name:     "owner",
+			filter:   ephcli.OrganizationFileFilter{Owner: strings.ToUpper(mockserver.DefaultEmail), Recent: 1},
+			expected: []string{"0c1d2e3f-0000-4000-8000-000000000013"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			files, err := client.SelectOrganizationFiles(mockserver.FixtureOrganizationID, tt.filter)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, fileIDs(files))
+		})
+	}
+}
+
+func TestPlanBulkDownload(t *testing.T) {
+	t.Parallel()
+
+	files := []dto.OrganizationFile{
+		{ID: "1", Filename: "report.pdf"},
+		{ID: "2", Filename: "report.pdf"},
+		{ID: "3", Filename: "../notes.txt"},
+		{ID: "4", Filename: ".."},
+	}
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("existing"), 0600))
+
+	targets := func(items []ephcli.BulkDownloadItem) []string {
+		var result []string
+		for _, item := range items {
+			if item.Skipped {
+				result = append(result, "skipped")
+				continue
+			}
+			rel, err := filepath.Rel(dir, item.Target)
+			require.NoError(t, err)
+			result = append(result, rel)
+		}
+		return result
+	}
+
+	client := ephcli.NewClient("token")
+	client.SetOutputDir(dir)
+	items, err := client.PlanBulkDownload(files)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"report.pdf", "report (1).pdf", "notes.txt", "4"}, targets(items))
+
+	client.SetExistPolicy(ephcli.ExistSkip)
+	items, err = client.PlanBulkDownload(files)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"report.pdf", "report (1).pdf", "skipped", "4"}, targets(items))
+
+	client.SetExistPolicy(ephcli.ExistRename)
+	items, err = client.PlanBulkDownload(files)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"report.pdf", "report (1).pdf", "notes (1).txt", "4"}, targets(items))
+}
+
+func TestDownloadOrganizationFiles(t *testing.T) {
+	t.Parallel()
+
+	client := newSeededClient(t)
+	files, err := client.SelectOrganizationFiles(mockserver.FixtureOrganizationID, ephcli.OrganizationFileFilter{})
+	require.NoError(t, err)
+	// The same file twice gets two names
+	files = append(files, files[0])
+
+	dir := filepath.Join(t.TempDir(), "out")
+	client.SetOutputDir(dir)
+	require.NoError(t, os.MkdirAll(dir, 0750))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "invoice-2026-01.pdf"), []byte("existing"), 0600))
+	client.SetExistPolicy(ephcli.ExistSkip)
+	items, err := client.PlanBulkDownload(files)
+	require.NoError(t, err)
+
+	var downloaded, skipped int
+	failed, err := client.DownloadOrganizationFiles(items, 2, false, func(_ ephcli.BulkDownloadItem, err error) {
+		switch {
+		case errors.Is(err, ephcli.ErrDownloadSkipped):
+			skipped++
+		case err == nil:
+			downloaded++
+		}
+	})
+	require.NoError(t, err)
+	assert.Zero(t, failed)
+	assert.Equal(t, 3, downloaded)
+	assert.Equal(t, 1, skipped)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	assert.ElementsMatch(t, []string{"report.csv", "report (1).csv", "invoice-2026-02.pdf", "invoice-2026-01.pdf"}, names)
+	existing, err := os.ReadFile(filepath.Join(dir, "invoice-2026-01.pdf"))
+	require.NoError(t, err)
+	assert.Equal(t, "existing", string(existing))
+	report, err := os.ReadFile(filepath.Join(dir, "report (1).csv"))
+	require.NoError(t, err)
+	assert.Contains(t, string(report), "report.csv fixture content")
+}
+
+func TestDownloadOrganizationFilesClear(t *testing.T) {
+	t.Parallel()
+
+	client := newSeededClient(t)
+	files, err := client.SelectOrganizationFiles(mockserver.FixtureOrganizationID,
+		ephcli.OrganizationFileFilter{Tags: []string{"reports"}})
+	require.NoError(t, err)
+	client.SetOutputDir(t.TempDir())
+	items, err := client.PlanBulkDownload(files)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+
+	failed, err := client.DownloadOrganizationFiles(items, 1, true, nil)
+	require.NoError(t, err)
+	assert.Zero(t, failed)
+	report, err := os.ReadFile(items[0].Target)
+	require.NoError(t, err)
+	assert.Contains(t, string(report), "report.csv fixture content")
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"hash"
 	"io"
 	"log/slog"
 	"mime/multipart"
@@ -12,6 +13,7 @@ import (
 	"net/url"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/ephemeralfiles/eph/pkg/dto"
 )
@@ -22,6 +24,39 @@ func (c *ClientEphemeralfiles) UploadOrganizationFile(
 	filepath string,
 	tags []string,
 ) (*dto.OrganizationFile, error) {
+	return c.UploadOrganizationFileWithOptions(filepath, UploadOptions{OrganizationID: orgID, Tags: tags})
+}
+
+// UploadOrganizationFileWithOptions uploads a file without encryption to the
+// organization of opts.
+func (c *ClientEphemeralfiles) UploadOrganizationFileWithOptions(
+	filepath string,
+	opts UploadOptions,
+) (*dto.OrganizationFile, error) {
+	started, sum := time.Now(), newChecksum()
+	file, err := c.uploadOrganizationFile(filepath, opts, sum)
+	transfer := Transfer{
+		Direction: TransferUpload, OrganizationID: opts.OrganizationID, Path: filepath,
+		Encryption: dto.EncryptionModeClear, Checksum: transferChecksum(sum, err),
+	}
+	if file != nil {
+		transfer.FileID = file.ID
+	}
+	c.recordTransfer(transfer, started, err)
+	return file, err
+}
+
+// uploadOrganizationFile uploads a file without encryption to the organization
+// of opts, writing its content to sum.
+func (c *ClientEphemeralfiles) uploadOrganizationFile(
+	filepath string,
+	opts UploadOptions,
+	sum hash.Hash,
+) (*dto.OrganizationFile, error) {
+	name, err := opts.remoteName(filepath)
+	if err != nil {
+		return nil, err
+	}
 	stat, err := c.validateAndGetFileInfo(filepath)
 	if err != nil {
 		return nil, err
@@ -34,9 +69,9 @@ func (c *ClientEphemeralfiles) UploadOrganizationFile(
 	pr, pw := io.Pipe()
 	writer := multipart.NewWriter(pw)
 
-	go c.createOrgMultipartForm(writer, pw, filepath, tags)
+	go c.createOrgMultipartForm(writer, pw, filepath, name, opts.formFields(), sum)
 
-	file, err := c.sendOrgUploadRequest(orgID, pr, writer)
+	file, err := c.sendOrgUploadRequest(opts.OrganizationID, pr, writer, stat.Size())
 	if err != nil {
 		return nil, err
 	}
@@ -44,19 +79,22 @@ func (c *ClientEphemeralfiles) UploadOrganizationFile(
 	return file, nil
 }
 
-// createOrgMultipartForm creates and populates the multipart form for organization upload.
+// createOrgMultipartForm creates and populates the multipart form for organization
+// upload. The content of the file is written to sum as it is sent.
 func (c *ClientEphemeralfiles) createOrgMultipartForm(
 	writer *multipart.Writer,
 	pw *io.PipeWriter,
 	filepath string,
-	tags []string,
+	name string,
+	fields map[string]string,
+	sum hash.Hash,
 ) {
 	defer func() {
 		_ = pw.Close()
 	}()
 
 	// Add file
-	part, err := writer.CreateFormFile("file", filepath)
+	part, err := writer.CreateFormFile("file", name)
 	if err != nil {
 		c.log.Debug("createOrgMultipartForm: CreateFormFile failed", slog.String("error", err.Error()))
 		pw.CloseWithError(err)
@@ -79,7 +117,8 @@ func (c *ClientEphemeralfiles) createOrgMultipartForm(
 		slog.String("filepath", filepath),
 		slog.Int64("size", stat.Size()))
 
-	bytesWritten, err := io.Copy(io.MultiWriter(part, c.bar), f)
+	bytesWritten, err := io.Copy(io.MultiWriter(part, c.bar),
+		io.TeeReader(c.limitReader(context.Background(), f), sum))
 	if err != nil {
 		c.log.Debug("createOrgMultipartForm: Copy failed", slog.String("error", err.Error()))
 		pw.CloseWithError(err)
@@ -89,13 +128,11 @@ func (c *ClientEphemeralfiles) createOrgMultipartForm(
 	c.log.Debug("createOrgMultipartForm: File copied",
 		slog.Int64("bytesWritten", bytesWritten))
 
-	// Add tags if provided
-	if len(tags) > 0 {
-		if err := writer.WriteField("tags", strings.Join(tags, ",")); err != nil {
-			c.log.Debug("createOrgMultipartForm: WriteField tags failed", slog.String("error", err.Error()))
-			pw.CloseWithError(err)
-			return
-		}
+	// Add tags and expiration if provided
+	if err := writeFormFields(writer, fields); err != nil {
+		c.log.Debug("createOrgMultipartForm: WriteField failed", slog.String("error", err.Error()))
+		pw.CloseWithError(err)
+		return
 	}
 
 	if err := writer.Close(); err != nil {
@@ -107,15 +144,17 @@ func (c *ClientEphemeralfiles) createOrgMultipartForm(
 	c.log.Debug("createOrgMultipartForm: Multipart form completed successfully")
 }
 
-// sendOrgUploadRequest creates and sends the organization upload HTTP request.
+// sendOrgUploadRequest creates and sends the organization upload HTTP request
+// of a file of size bytes.
 func (c *ClientEphemeralfiles) sendOrgUploadRequest(
 	orgID string,
 	pr *io.PipeReader,
 	writer *multipart.Writer,
+	size int64,
 ) (*dto.OrganizationFile, error) {
 	uploadURL := fmt.Sprintf("%s/%s/organizations/%s/files/upload", c.endpoint, apiVersion, orgID)
 
-	ctx, cancel := context.WithTimeout(context.Background(), ChunkUploadTimeout)
+	ctx, cancel := context.WithTimeout(context.Background(), c.transferTimeout(ChunkUploadTimeout, size))
 	defer cancel()
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, uploadURL, pr)
@@ -362,54 +401,66 @@ func (c *ClientEphemeralfiles) UpdateFileTags(
 
 // DownloadOrganizationFile downloads a file from an organization.
 func (c *ClientEphemeralfiles) DownloadOrganizationFile(fileID string, outputFile string) error {
+	started, sum := time.Now(), newChecksum()
+	filename, err := c.downloadOrganizationFile(fileID, outputFile, sum)
+	c.recordTransfer(Transfer{
+		Direction: TransferDownload, FileID: fileID, Path: filename, Encryption: dto.EncryptionModeClear,
+		Checksum: transferChecksum(sum, err),
+	}, started, err)
+	return err
+}
+
+// downloadOrganizationFile downloads a file from an organization, writing its
+// content to sum, and returns its path, empty if the download failed before the
+// path was known.
+func (c *ClientEphemeralfiles) downloadOrganizationFile(
+	fileID string, outputFile string, sum hash.Hash,
+) (string, error) {
 	// Organization files use the authenticated files endpoint
 	urlStr := fmt.Sprintf("%s/%s/files/%s/download", c.endpoint, apiVersion, fileID)
 
-	req, cancel, err := c.createRequestWithTimeout(http.MethodGet, urlStr, nil)
+	// The body is read within the request context: the timeout applies to each
+	// wait for data rather than to the whole transfer, whose length depends on
+	// the size of the file
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	idle := time.AfterFunc(DownloadIdleTimeout, cancel)
+	defer idle.Stop()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, urlStr, nil)
 	if err != nil {
-		return err
+		return "", fmt.Errorf("%w: %w", ErrCreatingRequest, err)
 	}
-	defer cancel()
 
-	resp, err := c.doRequestWithAuth(req)
+	c.addAuthHeader(req)
+	resp, err := c.sendDownloadRequest(req, fileID, outputFile)
 	if err != nil {
-		return err
+		return "", err
 	}
+	resp.Body = &idleTimeoutBody{ReadCloser: resp.Body, timer: idle, timeout: DownloadIdleTimeout}
 	defer func() {
 		_ = resp.Body.Close()
 	}()
 
-	if resp.StatusCode != http.StatusOK {
-		return parseError(resp)
-	}
-
 	// Get filename from Content-Disposition header or use output file
-	filename := c.getFileName(resp, outputFile)
-
-	// Create the output file
-	// #nosec G304 -- filename is derived from server response headers for downloads
-	f, err := os.Create(filename)
+	filename, err := c.getFileName(resp, outputFile)
 	if err != nil {
-		return fmt.Errorf("error creating file: %w", err)
+		return "", err
 	}
-	defer func() {
-		_ = f.Close()
-	}()
 
-	// Set up progress bar
-	totalSize := resp.ContentLength
-	c.InitProgressBar("downloading file...", totalSize)
-	defer c.CloseProgressBar()
+	return filename, c.saveResponseBody(resp, filename, fileID, sum)
+}
 
-	// Copy the file content
-	if !c.noProgressBar {
-		_, err = io.Copy(io.MultiWriter(f, c.bar), resp.Body)
-	} else {
-		_, err = io.Copy(f, resp.Body)
-	}
-	if err != nil {
-		return fmt.Errorf("error writing file: %w", err)
-	}
+// idleTimeoutBody is a response body whose timer, cancelling the request when
+// it fires, is restarted by each read: only a stalled transfer times out.
+type idleTimeoutBody struct {
+	io.ReadCloser
+	timer   *time.Timer
+	timeout time.Duration
+}
 
-	return nil
+// Read reads from the body and restarts the timer.
+func (b *idleTimeoutBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.timer.Reset(b.timeout)
+	return n, err
 }
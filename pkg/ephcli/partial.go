@@ -0,0 +1,276 @@
+package ephcli
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// PartialSuffix is appended to the final path of a download while it is in progress.
+	PartialSuffix = ".eph-partial"
+	// PartialJournalSuffix is appended to the final path of a resumable download to
+	// name the journal describing the progress of its partial file.
+	PartialJournalSuffix = ".eph-partial.json"
+)
+
+// partialState is the journal of a resumable download.
+type partialState struct {
+	// FileID is the ID of the downloaded file on the server.
+	FileID string `json:"fileId"`
+	// Size is the expected size of the complete file.
+	Size int64 `json:"size"`
+	// Encrypted is true for E2E downloads, which are resumed at part boundaries.
+	Encrypted bool `json:"encrypted"`
+	// Parts is the number of E2E parts completely written.
+	Parts int `json:"parts"`
+	// Offset is the number of bytes covered by Parts.
+	// Clear downloads resume from the size of the partial file instead.
+	Offset int64 `json:"offset"`
+}
+
+// partialFile is a download written next to its final path and renamed into place
+// once complete, so that an interrupted transfer never leaves a truncated file at
+// the final path nor destroys an existing copy.
+type partialFile struct {
+	*os.File
+	finalPath string
+	state     partialState
+	// sum is the checksum of the content of the file, written through writer.
+	sum hash.Hash
+	// keep is true when resume is enabled: the partial file and its journal are
+	// kept on failure so that the next run can continue the download.
+	keep bool
+}
+
+// SetResume enables or disables resumable downloads.
+// When enabled, an interrupted download keeps its partial file and the next
+// download of the same file to the same path only fetches the missing data.
+func (c *ClientEphemeralfiles) SetResume(resume bool) {
+	c.resume = resume
+}
+
+// openPartialFile opens the temporary file of a download to finalPath.
+// When resume is enabled and a journal matching want is found, the partial file is
+// reopened positioned after its complete data, which is written to sum;
+// otherwise it is created empty.
+func (c *ClientEphemeralfiles) openPartialFile(
+	finalPath string, want partialState, sum hash.Hash,
+) (*partialFile, error) {
+	p := &partialFile{finalPath: finalPath, state: want, sum: sum, keep: c.resume}
+	p.state.Parts, p.state.Offset = 0, 0
+
+	if c.resume && want.Size >= 0 {
+		if offset, parts, ok := resumePoint(finalPath, want); ok {
+			// #nosec G304 -- finalPath is sanitized or explicitly provided by the user
+			f, err := os.OpenFile(finalPath+PartialSuffix, os.O_RDWR, FilePermission)
+			if err == nil {
+				p.File = f
+				if err := p.truncate(offset); err != nil {
+					_ = f.Close()
+					return nil, err
+				}
+				if _, err := io.Copy(sum, io.NewSectionReader(f, 0, offset)); err != nil {
+					_ = f.Close()
+					return nil, fmt.Errorf("error reading temporary file: %w", err)
+				}
+				p.state.Parts, p.state.Offset = parts, offset
+				return p, nil
+			}
+		}
+	}
+
+	// #nosec G304 -- finalPath is sanitized or explicitly provided by the user
+	f, err := os.OpenFile(finalPath+PartialSuffix, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, FilePermission)
+	if err != nil {
+		return nil, fmt.Errorf("error creating temporary file: %w", err)
+	}
+	p.File = f
+	if err := p.saveJournal(); err != nil {
+		p.fail()
+		return nil, err
+	}
+	return p, nil
+}
+
+// resumePoint returns the offset and the number of complete parts a download
+// described by want can resume from. ok is false when there is nothing to resume.
+func resumePoint(finalPath string, want partialState) (int64, int, bool) {
+	st, ok := readJournal(finalPath)
+	if !ok {
+		return 0, 0, false
+	}
+	if st.FileID != want.FileID || st.Size != want.Size || st.Encrypted != want.Encrypted {
+		return 0, 0, false
+	}
+	info, err := os.Stat(finalPath + PartialSuffix)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	offset, parts := info.Size(), 0
+	if st.Encrypted {
+		// Data written after the last checkpoint belongs to an incomplete part
+		offset, parts = st.Offset, st.Parts
+		if info.Size() < offset {
+			return 0, 0, false
+		}
+	}
+	if offset <= 0 || offset > st.Size {
+		return 0, 0, false
+	}
+	return offset, parts, true
+}
+
+// readJournal reads the journal of the download to finalPath.
+func readJournal(finalPath string) (partialState, bool) {
+	var st partialState
+	// #nosec G304 -- finalPath is sanitized or explicitly provided by the user
+	data, err := os.ReadFile(finalPath + PartialJournalSuffix)
+	if err != nil {
+		return st, false
+	}
+	if err := json.Unmarshal(data, &st); err != nil {
+		return st, false
+	}
+	return st, true
+}
+
+// partialOffset returns the size of the partial clear download of fileID, to
+// outputFile or, when it is empty, to any file of the output directory, so that
+// only the missing bytes are requested. It is 0 when there is nothing to resume.
+func (c *ClientEphemeralfiles) partialOffset(fileID, outputFile string) int64 {
+	if !c.resume {
+		return 0
+	}
+	var journals []string
+	if outputFile != "" {
+		target := outputFile
+		if c.outputDir != "" && !filepath.IsAbs(target) {
+			target = filepath.Join(c.outputDir, target)
+		}
+		journals = []string{target + PartialJournalSuffix}
+	} else {
+		// The name of the file is only known from the response
+		journals, _ = filepath.Glob(filepath.Join(c.outputDir, "*"+PartialJournalSuffix))
+	}
+	for _, journal := range journals {
+		finalPath := strings.TrimSuffix(journal, PartialJournalSuffix)
+		st, ok := readJournal(finalPath)
+		if !ok || st.FileID != fileID || st.Encrypted {
+			continue
+		}
+		if offset, _, ok := resumePoint(finalPath, st); ok {
+			return offset
+		}
+	}
+	return 0
+}
+
+// truncate discards the data after offset and positions the file there.
+func (p *partialFile) truncate(offset int64) error {
+	if err := p.Truncate(offset); err != nil {
+		return fmt.Errorf("error truncating temporary file: %w", err)
+	}
+	if _, err := p.Seek(offset, io.SeekStart); err != nil {
+		return fmt.Errorf("%w: %w", ErrSeekingInFile, err)
+	}
+	return nil
+}
+
+// writer returns the writer appending data to the file and to its checksum.
+func (p *partialFile) writer() io.Writer {
+	return io.MultiWriter(p.File, p.sum)
+}
+
+// restart discards the content of the partial file.
+func (p *partialFile) restart() error {
+	p.state.Parts, p.state.Offset = 0, 0
+	p.sum.Reset()
+	if err := p.truncate(0); err != nil {
+		return err
+	}
+	return p.saveJournal()
+}
+
+// checkpoint records that parts E2E parts, covering offset bytes, are complete.
+func (p *partialFile) checkpoint(parts int, offset int64) error {
+	p.state.Parts, p.state.Offset = parts, offset
+	if !p.keep {
+		return nil
+	}
+	if err := p.Sync(); err != nil {
+		return fmt.Errorf("error syncing file: %w", err)
+	}
+	return p.saveJournal()
+}
+
+// saveJournal writes the journal of the download when resume is enabled.
+func (p *partialFile) saveJournal() error {
+	if !p.keep {
+		return nil
+	}
+	data, err := json.Marshal(p.state)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrMarshallingPayload, err)
+	}
+	if err := os.WriteFile(p.finalPath+PartialJournalSuffix, data, FilePermission); err != nil {
+		return fmt.Errorf("error writing download journal: %w", err)
+	}
+	return nil
+}
+
+// commit flushes the temporary file to disk, checks its size against expectedSize
+// (ignored when negative) and renames it to the final path.
+// The temporary file is removed if the content is wrong.
+func (p *partialFile) commit(expectedSize int64) error {
+	if err := p.Sync(); err != nil {
+		p.fail()
+		return fmt.Errorf("error syncing file: %w", err)
+	}
+	info, err := p.Stat()
+	if err != nil {
+		p.fail()
+		return fmt.Errorf("%w: %w", ErrGettingFileInfo, err)
+	}
+	if expectedSize >= 0 && info.Size() != expectedSize {
+		p.discard()
+		return fmt.Errorf("%w: got %d bytes, expected %d", ErrSizeMismatch, info.Size(), expectedSize)
+	}
+	if err := p.Close(); err != nil {
+		p.discard()
+		return fmt.Errorf("error closing file: %w", err)
+	}
+	if err := os.Rename(p.Name(), p.finalPath); err != nil {
+		p.discard()
+		return fmt.Errorf("error renaming file: %w", err)
+	}
+	p.removeJournal()
+	return nil
+}
+
+// fail closes the temporary file after an error, leaving the final path untouched.
+// The partial file is kept for a later resume when resume is enabled.
+func (p *partialFile) fail() {
+	if p.keep {
+		_ = p.Close()
+		return
+	}
+	p.discard()
+}
+
+// discard closes and removes the temporary file and its journal.
+func (p *partialFile) discard() {
+	_ = p.Close()
+	_ = os.Remove(p.Name())
+	p.removeJournal()
+}
+
+// removeJournal removes the journal of the download, if any.
+func (p *partialFile) removeJournal() {
+	_ = os.Remove(p.finalPath + PartialJournalSuffix)
+}
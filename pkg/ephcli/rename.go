@@ -0,0 +1,51 @@
+package ephcli
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ephemeralfiles/eph/pkg/dto"
+)
+
+// RenameFileEndpoint returns the API endpoint URL for renaming a file.
+func (c *ClientEphemeralfiles) RenameFileEndpoint(fileID string) string {
+	return fmt.Sprintf("%s/%s/files/%s/name", c.endpoint, apiVersion, fileID)
+}
+
+// RenameFile changes the name of an uploaded file. The content of the file is
+// not modified.
+func (c *ClientEphemeralfiles) RenameFile(fileID, name string) (*dto.File, error) {
+	if err := ValidateRemoteName(name); err != nil {
+		return nil, err
+	}
+	payload, err := json.Marshal(map[string]string{"filename": name})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMarshallingPayload, err)
+	}
+
+	req, cancel, err := c.createRequestWithTimeout(http.MethodPut, c.RenameFileEndpoint(fileID),
+		strings.NewReader(string(payload)))
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.doRequestWithAuth(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	var file dto.File
+	if err := json.NewDecoder(resp.Body).Decode(&file); err != nil {
+		return nil, fmt.Errorf("failed to decode file response: %w", err)
+	}
+
+	return &file, nil
+}
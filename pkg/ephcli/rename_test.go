@@ -0,0 +1,136 @@
+package ephcli_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ephemeralfiles/eph/pkg/ephcli"
+	"github.com/ephemeralfiles/eph/pkg/mockserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var formFilenameRegexp = regexp.MustCompile(`filename="([^"]*)"`)
+
+// newFilenameRecorder starts a mock server recording the file names of the
+// uploaded multipart forms, and returns a client configured for it.
+func newFilenameRecorder(t *testing.T) (*ephcli.ClientEphemeralfiles, func() []string) {
+	t.Helper()
+	srv, err := mockserver.New(mockserver.Options{})
+	require.NoError(t, err)
+
+	var (
+		mu    sync.Mutex
+		names []string
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+			body, err := io.ReadAll(r.Body)
+			if err == nil {
+				if m := formFilenameRegexp.FindSubmatch(body); m != nil {
+					mu.Lock()
+					names = append(names, string(m[1]))
+					mu.Unlock()
+				}
+				r.Body = io.NopCloser(bytes.NewReader(body))
+			}
+		}
+		srv.Handler().ServeHTTP(w, r)
+	}))
+	t.Cleanup(ts.Close)
+
+	client := ephcli.NewClient(mockserver.DevToken(mockserver.DefaultEmail, time.Hour))
+	client.SetEndpoint(ts.URL)
+	client.DisableProgressBar()
+	return client, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), names...)
+	}
+}
+
+func TestUploadRemoteName(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	src := filepath.Join(dir, "secret", "project", "report.pdf")
+	require.NoError(t, os.MkdirAll(filepath.Dir(src), 0700))
+	require.NoError(t, os.WriteFile(src, []byte("report"), 0600))
+
+	tests := []struct {
+		name     string
+		opts     ephcli.UploadOptions
+		expected string
+	}{
+		{name: "base name by default", expected: "report.pdf"},
+		{name: "name override", opts: ephcli.UploadOptions{Name: "q3.pdf"}, expected: "q3.pdf"},
+		{name: "keep path", opts: ephcli.UploadOptions{KeepPath: true}, expected: filepath.ToSlash(src)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			client, names := newFilenameRecorder(t)
+			_, err := client.UploadWithOptions(src, tt.opts)
+			require.NoError(t, err)
+			_, err = client.UploadE2EWithOptions(src, tt.opts)
+			require.NoError(t, err)
+			assert.Equal(t, []string{tt.expected, tt.expected}, names())
+		})
+	}
+
+	t.Run("invalid name", func(t *testing.T) {
+		t.Parallel()
+
+		client, names := newFilenameRecorder(t)
+		_, err := client.UploadWithOptions(src, ephcli.UploadOptions{Name: "../report.pdf"})
+		require.ErrorIs(t, err, ephcli.ErrInvalidFilename)
+		_, err = client.UploadE2EWithOptions(src, ephcli.UploadOptions{Name: "q3/report.pdf"})
+		require.ErrorIs(t, err, ephcli.ErrInvalidFilename)
+		assert.Empty(t, names(), "nothing is uploaded")
+	})
+}
+
+func TestValidateRemoteName(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, ephcli.ValidateRemoteName("report 2026.pdf"))
+	for _, invalid := range []string{"", " ", "..", "a/b", `a\b`, "a\nb", strings.Repeat("a", 300)} {
+		require.ErrorIs(t, ephcli.ValidateRemoteName(invalid), ephcli.ErrInvalidFilename, invalid)
+	}
+}
+
+func TestRenameFile(t *testing.T) {
+	t.Parallel()
+
+	srv, err := mockserver.New(mockserver.Options{Seed: true})
+	require.NoError(t, err)
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+
+	client := ephcli.NewClient(mockserver.DevToken(mockserver.DefaultEmail, time.Hour))
+	client.SetEndpoint(ts.URL)
+
+	const fileID = "0c1d2e3f-0000-4000-8000-000000000002"
+	file, err := client.RenameFile(fileID, "meeting-notes.md")
+	require.NoError(t, err)
+	assert.Equal(t, "meeting-notes.md", file.FileName)
+
+	info, err := client.GetFileInfo(fileID)
+	require.NoError(t, err)
+	assert.Equal(t, "meeting-notes.md", info.Filename)
+
+	_, err = client.RenameFile(fileID, "dir/notes.md")
+	require.ErrorIs(t, err, ephcli.ErrInvalidFilename)
+	_, err = client.RenameFile("00000000-0000-4000-8000-000000000000", "notes.md")
+	require.Error(t, err)
+}
@@ -0,0 +1,114 @@
+package ephcli
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ephemeralfiles/eph/pkg/dto"
+)
+
+// ShareOptions holds the optional restrictions of a share link.
+type ShareOptions struct {
+	// Password must be given to download the file when set.
+	Password string
+	// MaxDownloads is the number of downloads after which the link stops
+	// working. Zero means unlimited.
+	MaxDownloads int
+	// ExpiresAt is the expiration of the link. It defaults to the expiration
+	// of the file.
+	ExpiresAt time.Time
+}
+
+// sharePayload is the body of a share link creation request.
+type sharePayload struct {
+	Password       string `json:"password,omitempty"`
+	MaxDownloads   int    `json:"max_downloads,omitempty"`
+	ExpirationDate string `json:"expiration_date,omitempty"`
+}
+
+// SharesEndpoint returns the API endpoint URL for the share links of the caller.
+func (c *ClientEphemeralfiles) SharesEndpoint() string {
+	return fmt.Sprintf("%s/%s/shares", c.endpoint, apiVersion)
+}
+
+// FileSharesEndpoint returns the API endpoint URL for creating a share link to a file.
+func (c *ClientEphemeralfiles) FileSharesEndpoint(fileID string) string {
+	return fmt.Sprintf("%s/%s/files/%s/shares", c.endpoint, apiVersion, fileID)
+}
+
+// CreateShareLink creates a public download link to a file.
+func (c *ClientEphemeralfiles) CreateShareLink(fileID string, opts ShareOptions) (*dto.ShareLink, error) {
+	payload := sharePayload{Password: opts.Password, MaxDownloads: opts.MaxDownloads}
+	if !opts.ExpiresAt.IsZero() {
+		payload.ExpirationDate = formatExpiration(opts.ExpiresAt)
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMarshallingPayload, err)
+	}
+
+	var link dto.ShareLink
+	if err := c.doShareRequest(http.MethodPost, c.FileSharesEndpoint(fileID), string(body), &link); err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// ListShareLinks lists the share links created by the caller.
+func (c *ClientEphemeralfiles) ListShareLinks() ([]dto.ShareLink, error) {
+	var links []dto.ShareLink
+	if err := c.doShareRequest(http.MethodGet, c.SharesEndpoint(), "", &links); err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+// ListOrganizationShareLinks lists the share links to the files of an organization.
+func (c *ClientEphemeralfiles) ListOrganizationShareLinks(orgID string) ([]dto.ShareLink, error) {
+	urlStr := fmt.Sprintf("%s/%s/organizations/%s/shares", c.endpoint, apiVersion, orgID)
+	var links []dto.ShareLink
+	if err := c.doShareRequest(http.MethodGet, urlStr, "", &links); err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+// RevokeShareLink deletes a share link. The file itself is kept.
+func (c *ClientEphemeralfiles) RevokeShareLink(shareID string) error {
+	return c.doShareRequest(http.MethodDelete, c.SharesEndpoint()+"/"+shareID, "", nil)
+}
+
+// doShareRequest sends an authenticated request with an optional JSON body and
+// decodes the response into result when it is not nil.
+func (c *ClientEphemeralfiles) doShareRequest(method, urlStr, body string, result any) error {
+	req, cancel, err := c.createRequestWithTimeout(method, urlStr, strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer cancel()
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.doRequestWithAuth(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			c.log.Debug("Warning: failed to close response body", slog.String("error", closeErr.Error()))
+		}
+	}()
+
+	if result == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("%w: %w", ErrDecodingResponse, err)
+	}
+	return nil
+}
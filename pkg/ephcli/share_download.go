@@ -0,0 +1,176 @@
+package ephcli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ephemeralfiles/eph/pkg/dto"
+)
+
+// sharePasswordHeader is the header carrying the password of a share link.
+const sharePasswordHeader = "X-Share-Password"
+
+var (
+	// ErrInvalidShareURL is returned when a URL is not a share link.
+	ErrInvalidShareURL = errors.New("invalid share link, expected https://host/s/TOKEN")
+	// ErrInvalidSharePassword is returned when the password of a share link is missing or wrong.
+	ErrInvalidSharePassword = errors.New("invalid share link password")
+	// ErrShareLinkGone is returned when a share link expired or reached its
+	// download limit.
+	ErrShareLinkGone = errors.New("share link no longer available")
+)
+
+// ParseShareURL splits a share link into the endpoint of the API serving it and
+// the token of the link.
+func ParseShareURL(shareURL string) (string, string, error) {
+	u, err := url.Parse(strings.TrimSpace(shareURL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", "", fmt.Errorf("%w: %q", ErrInvalidShareURL, shareURL)
+	}
+	idx := strings.LastIndex(u.Path, "/s/")
+	if idx < 0 {
+		return "", "", fmt.Errorf("%w: %q", ErrInvalidShareURL, shareURL)
+	}
+	token := strings.TrimSuffix(u.Path[idx+len("/s/"):], "/")
+	if token == "" || strings.Contains(token, "/") {
+		return "", "", fmt.Errorf("%w: %q", ErrInvalidShareURL, shareURL)
+	}
+	return u.Scheme + "://" + u.Host + u.Path[:idx], token, nil
+}
+
+// PublicShareEndpoint returns the API endpoint URL of a share link, which does
+// not require authentication.
+func (c *ClientEphemeralfiles) PublicShareEndpoint(token string) string {
+	return fmt.Sprintf("%s/%s/shares/public/%s", c.endpoint, apiVersion, url.PathEscape(token))
+}
+
+// GetSharedFile returns the information of the file shared by a link.
+func (c *ClientEphemeralfiles) GetSharedFile(token string) (*dto.SharedFile, error) {
+	req, cancel, err := c.createRequestWithTimeout(http.MethodGet, c.PublicShareEndpoint(token), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	c.addAuthHeader(req)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSendingRequest, err)
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			c.log.Debug("Warning: failed to close response body", slog.String("error", closeErr.Error()))
+		}
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return nil, shareError(resp)
+	}
+
+	var file dto.SharedFile
+	if err := json.NewDecoder(resp.Body).Decode(&file); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDecodingResponse, err)
+	}
+	return &file, nil
+}
+
+// CreateSharedDownloadTransaction creates an E2E download transaction for a
+// share link and returns the transaction ID and public key.
+func (c *ClientEphemeralfiles) CreateSharedDownloadTransaction(token, password string) (string, string, error) {
+	req, cancel, err := c.createRequestWithTimeout(http.MethodPost, c.PublicShareEndpoint(token)+"/download", nil)
+	if err != nil {
+		return "", "", err
+	}
+	defer cancel()
+	c.addAuthHeader(req)
+	if password != "" {
+		req.Header.Set(sharePasswordHeader, password)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", "", fmt.Errorf("%w: %w", ErrSendingRequest, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized:
+		return "", "", ErrInvalidSharePassword
+	default:
+		return "", "", shareError(resp)
+	}
+
+	transactionID := resp.Header.Get("X-Transaction-Id")
+	if transactionID == "" {
+		return "", "", fmt.Errorf("%w: %w", ErrReadingResponse, ErrMissingHeaderTransactionID)
+	}
+	publicKey := resp.Header.Get("X-File-Public-Key")
+	if publicKey == "" {
+		return "", "", fmt.Errorf("%w: %w", ErrReadingResponse, ErrMissingHeaderPublicKey)
+	}
+	return transactionID, publicKey, nil
+}
+
+// shareError returns the error of a failed request to a share link, reporting
+// links that expired or reached their download limit with ErrShareLinkGone.
+func shareError(resp *http.Response) error {
+	if resp.StatusCode == http.StatusGone {
+		return fmt.Errorf("%w: %w", ErrShareLinkGone, parseError(resp))
+	}
+	return parseError(resp)
+}
+
+// DownloadShared downloads and decrypts the file shared by a link. sharedFile is
+// the information of the link returned by GetSharedFile, fetched when nil. The
+// password is only needed when the link is protected. Each call counts as a
+// download of the link.
+func (c *ClientEphemeralfiles) DownloadShared(
+	token string, sharedFile *dto.SharedFile, password, outputPath string,
+) error {
+	started, sum := time.Now(), newChecksum()
+	outputFilePath, err := c.downloadShared(token, sharedFile, password, outputPath, sum)
+	c.recordTransfer(Transfer{
+		Direction: TransferDownload, Path: outputFilePath, Encryption: dto.EncryptionModeE2E,
+		Checksum: transferChecksum(sum, err),
+	}, started, err)
+	return err
+}
+
+// downloadShared downloads and decrypts the file shared by a link, writing its
+// content to sum, and returns its path, empty if the download failed before the
+// path was known.
+func (c *ClientEphemeralfiles) downloadShared(
+	token string, sharedFile *dto.SharedFile, password, outputPath string, sum hash.Hash,
+) (string, error) {
+	if sharedFile == nil {
+		var err error
+		if sharedFile, err = c.GetSharedFile(token); err != nil {
+			return "", err
+		}
+	}
+	fileInfo := &dto.InfoFile{Filename: sharedFile.Filename, Size: sharedFile.Size, NbParts: sharedFile.NbParts}
+	c.logFileInfo(fileInfo)
+
+	outputFilePath, err := c.resolveOutputPath(outputPath, fileInfo.Filename, token)
+	if err != nil {
+		return "", err
+	}
+
+	transactionID, keyBundle, err := c.negotiateDownloadTransaction(func() (string, string, error) {
+		return c.CreateSharedDownloadTransaction(token, password)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return outputFilePath, c.downloadAllParts(token, fileInfo, transactionID, keyBundle.AESKey, outputFilePath, sum)
+}
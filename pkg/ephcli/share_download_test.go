@@ -0,0 +1,95 @@
+package ephcli_test
+
+import (
+	"crypto/rand"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ephemeralfiles/eph/pkg/ephcli"
+	"github.com/ephemeralfiles/eph/pkg/mockserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseShareURL(t *testing.T) {
+	t.Parallel()
+
+	endpoint, token, err := ephcli.ParseShareURL("https://api.ephemeralfiles.com/s/6b0f6074")
+	require.NoError(t, err)
+	assert.Equal(t, "https://api.ephemeralfiles.com", endpoint)
+	assert.Equal(t, "6b0f6074", token)
+
+	endpoint, token, err = ephcli.ParseShareURL("http://localhost:8080/eph/s/abc/")
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:8080/eph", endpoint)
+	assert.Equal(t, "abc", token)
+
+	for _, invalid := range []string{"", "6b0f6074", "ftp://host/s/abc", "https://host/files/abc", "https://host/s/", "https://host/s/a/b"} {
+		_, _, err := ephcli.ParseShareURL(invalid)
+		require.ErrorIs(t, err, ephcli.ErrInvalidShareURL, invalid)
+	}
+}
+
+func TestDownloadShared(t *testing.T) {
+	t.Parallel()
+
+	srv, err := mockserver.New(mockserver.Options{})
+	require.NoError(t, err)
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+
+	owner := ephcli.NewClient(mockserver.DevToken(mockserver.DefaultEmail, time.Hour))
+	owner.SetEndpoint(ts.URL)
+	owner.DisableProgressBar()
+	owner.SetChunkSize(64 * 1024)
+
+	dir := t.TempDir()
+	src := filepath.Join(dir, "archive.bin")
+	content := make([]byte, 200*1024)
+	_, err = rand.Read(content)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(src, content, 0600))
+	uploaded, err := owner.UploadE2EWithOptions(src, ephcli.UploadOptions{})
+	require.NoError(t, err)
+	fileID := uploaded.FileID
+
+	link, err := owner.CreateShareLink(fileID, ephcli.ShareOptions{Password: "secret", MaxDownloads: 1})
+	require.NoError(t, err)
+	endpoint, token, err := ephcli.ParseShareURL(link.URL)
+	require.NoError(t, err)
+
+	// The anonymous client has no token
+	anonymous := ephcli.NewClient("")
+	anonymous.SetEndpoint(endpoint)
+	anonymous.DisableProgressBar()
+	anonymous.SetOutputDir(filepath.Join(dir, "out"))
+
+	shared, err := anonymous.GetSharedFile(token)
+	require.NoError(t, err)
+	assert.Equal(t, "archive.bin", shared.Filename)
+	assert.Equal(t, int64(len(content)), shared.Size)
+	assert.Equal(t, 4, shared.NbParts)
+	assert.True(t, shared.PasswordRequired)
+
+	err = anonymous.DownloadShared(token, shared, "wrong", "")
+	require.ErrorIs(t, err, ephcli.ErrInvalidSharePassword)
+
+	require.NoError(t, anonymous.DownloadShared(token, nil, "secret", ""))
+	downloaded, err := os.ReadFile(filepath.Join(dir, "out", "archive.bin"))
+	require.NoError(t, err)
+	assert.Equal(t, content, downloaded)
+
+	// The link allowed a single download
+	_, err = anonymous.GetSharedFile(token)
+	require.ErrorIs(t, err, ephcli.ErrShareLinkGone)
+	err = anonymous.DownloadShared(token, shared, "secret", "")
+	require.ErrorIs(t, err, ephcli.ErrShareLinkGone)
+	require.NotErrorIs(t, err, ephcli.ErrInvalidSharePassword)
+	links, err := owner.ListShareLinks()
+	require.NoError(t, err)
+	require.Len(t, links, 1)
+	assert.Equal(t, 1, links[0].DownloadCount)
+}
@@ -0,0 +1,67 @@
+package ephcli_test
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ephemeralfiles/eph/pkg/ephcli"
+	"github.com/ephemeralfiles/eph/pkg/mockserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShareLinks(t *testing.T) {
+	t.Parallel()
+
+	srv, err := mockserver.New(mockserver.Options{Seed: true})
+	require.NoError(t, err)
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+
+	client := ephcli.NewClient(mockserver.DevToken(mockserver.DefaultEmail, time.Hour))
+	client.SetEndpoint(ts.URL)
+	client.DisableProgressBar()
+
+	src := filepath.Join(t.TempDir(), "photo.jpg")
+	require.NoError(t, os.WriteFile(src, []byte("photo"), 0600))
+	uploaded, err := client.UploadE2EWithOptions(src, ephcli.UploadOptions{OrganizationID: mockserver.FixtureOrganizationID})
+	require.NoError(t, err)
+	fileID := uploaded.FileID
+
+	expiresAt := time.Now().Add(2 * time.Hour).UTC().Truncate(time.Second)
+	link, err := client.CreateShareLink(fileID, ephcli.ShareOptions{
+		Password:     "secret",
+		MaxDownloads: 3,
+		ExpiresAt:    expiresAt,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, fileID, link.FileID)
+	assert.Equal(t, "photo.jpg", link.Filename)
+	assert.True(t, strings.HasPrefix(link.URL, ts.URL+"/s/"), link.URL)
+	assert.True(t, link.PasswordRequired)
+	assert.Equal(t, 3, link.MaxDownloads)
+	assert.True(t, expiresAt.Equal(link.ExpirationDate))
+
+	_, err = client.CreateShareLink("missing-file", ephcli.ShareOptions{})
+	require.Error(t, err)
+
+	links, err := client.ListShareLinks()
+	require.NoError(t, err)
+	require.Len(t, links, 1)
+	assert.Equal(t, link.ID, links[0].ID)
+
+	orgLinks, err := client.ListOrganizationShareLinks(mockserver.FixtureOrganizationID)
+	require.NoError(t, err)
+	require.Len(t, orgLinks, 1)
+	assert.Equal(t, mockserver.FixtureOrganizationID, orgLinks[0].OrganizationID)
+
+	require.NoError(t, client.RevokeShareLink(link.ID))
+	require.Error(t, client.RevokeShareLink(link.ID))
+	links, err = client.ListShareLinks()
+	require.NoError(t, err)
+	assert.Empty(t, links)
+}
@@ -0,0 +1,163 @@
+package ephcli
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ephemeralfiles/eph/pkg/dto"
+)
+
+// organizationFilesPageSize is the page size used to list all the files of an organization.
+const organizationFilesPageSize = 100
+
+var (
+	// ErrInvalidTagRename is returned when a tag rename is not of the form "old=new".
+	ErrInvalidTagRename = errors.New("invalid tag rename, expected old=new")
+	// ErrInvalidTagWhere is returned when a file selection is not of the form
+	// "tags=TAG1,TAG2".
+	ErrInvalidTagWhere = errors.New("invalid selection, expected tags=TAG1,TAG2")
+)
+
+// TagOperation is a change applied to the tags of a file.
+type TagOperation int
+
+const (
+	// TagAdd adds tags to the existing ones.
+	TagAdd TagOperation = iota
+	// TagRemove removes tags from the existing ones.
+	TagRemove
+	// TagSet replaces the existing tags.
+	TagSet
+)
+
+// ParseTags splits a comma-separated list of tags, trimming spaces and dropping
+// empty and duplicate entries.
+func ParseTags(list string) []string {
+	var tags []string
+	for _, tag := range strings.Split(list, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag != "" && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// ParseTagRename parses a tag rename of the form "old=new".
+func ParseTagRename(rename string) (string, string, error) {
+	oldTag, newTag, ok := strings.Cut(rename, "=")
+	oldTag, newTag = strings.TrimSpace(oldTag), strings.TrimSpace(newTag)
+	if !ok || oldTag == "" || newTag == "" || strings.Contains(newTag, ",") {
+		return "", "", fmt.Errorf("%w: %q", ErrInvalidTagRename, rename)
+	}
+	return oldTag, newTag, nil
+}
+
+// ParseTagWhere parses a file selection of the form "tags=TAG1,TAG2" and
+// returns its tags.
+func ParseTagWhere(where string) ([]string, error) {
+	key, value, ok := strings.Cut(where, "=")
+	if !ok || strings.TrimSpace(key) != "tags" {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidTagWhere, where)
+	}
+	return ParseTags(value), nil
+}
+
+// ApplyTagOperation returns the tags of a file after applying op with tags.
+// The order of the existing tags is preserved and duplicates are dropped.
+func ApplyTagOperation(current []string, op TagOperation, tags []string) []string {
+	result := []string{}
+	switch op {
+	case TagSet:
+		return append(result, ParseTags(strings.Join(tags, ","))...)
+	case TagAdd:
+		result = append(result, ParseTags(strings.Join(current, ","))...)
+		for _, tag := range tags {
+			if !slices.Contains(result, tag) {
+				result = append(result, tag)
+			}
+		}
+	case TagRemove:
+		for _, tag := range ParseTags(strings.Join(current, ",")) {
+			if !slices.Contains(tags, tag) {
+				result = append(result, tag)
+			}
+		}
+	}
+	return result
+}
+
+// RenameTag returns current with oldTag replaced by newTag.
+func RenameTag(current []string, oldTag, newTag string) []string {
+	result := []string{}
+	for _, tag := range current {
+		if tag == oldTag {
+			tag = newTag
+		}
+		if !slices.Contains(result, tag) {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
+
+// SameTags reports whether a and b hold the same tags, ignoring order.
+func SameTags(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, tag := range a {
+		if !slices.Contains(b, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// ListAllOrganizationFiles lists all the files of an organization, page by page.
+func (c *ClientEphemeralfiles) ListAllOrganizationFiles(orgID string) ([]dto.OrganizationFile, error) {
+	return listAllPages(func(limit, offset int) ([]dto.OrganizationFile, error) {
+		return c.ListOrganizationFiles(orgID, limit, offset)
+	})
+}
+
+// ListAllOrganizationFilesByTags lists all the files of an organization having
+// all the given tags, page by page.
+func (c *ClientEphemeralfiles) ListAllOrganizationFilesByTags(
+	orgID string, tags []string,
+) ([]dto.OrganizationFile, error) {
+	return listAllPages(func(limit, offset int) ([]dto.OrganizationFile, error) {
+		return c.GetOrganizationFilesByTags(orgID, tags, limit, offset)
+	})
+}
+
+// listAllPages calls list with increasing offsets until the last page. The
+// offset advances by the number of files returned, as servers may cap the page
+// size below the requested limit: a page shorter than the previous ones, or
+// empty, is the last. Listing also stops when a page only repeats files
+// already listed, as with servers ignoring the offset.
+func listAllPages(list func(limit, offset int) ([]dto.OrganizationFile, error)) ([]dto.OrganizationFile, error) {
+	var all []dto.OrganizationFile
+	seen := map[string]bool{}
+	pageSize := 0
+	for {
+		files, err := list(organizationFilesPageSize, len(all))
+		if err != nil {
+			return nil, err
+		}
+		added := 0
+		for _, f := range files {
+			if !seen[f.ID] {
+				seen[f.ID] = true
+				all = append(all, f)
+				added++
+			}
+		}
+		if added == 0 || len(files) < pageSize {
+			return all, nil
+		}
+		pageSize = max(pageSize, len(files))
+	}
+}
@@ -0,0 +1,130 @@
+package ephcli_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/ephemeralfiles/eph/pkg/dto"
+	"github.com/ephemeralfiles/eph/pkg/ephcli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTags(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{"invoice", "q1"}, ephcli.ParseTags(" invoice, q1 ,,invoice"))
+	assert.Empty(t, ephcli.ParseTags(""))
+}
+
+func TestParseTagRename(t *testing.T) {
+	t.Parallel()
+
+	oldTag, newTag, err := ephcli.ParseTagRename("2024-q1 = q1-2024")
+	require.NoError(t, err)
+	assert.Equal(t, "2024-q1", oldTag)
+	assert.Equal(t, "q1-2024", newTag)
+
+	for _, invalid := range []string{"old", "=new", "old=", "old=a,b"} {
+		_, _, err := ephcli.ParseTagRename(invalid)
+		require.ErrorIs(t, err, ephcli.ErrInvalidTagRename, invalid)
+	}
+}
+
+func TestParseTagWhere(t *testing.T) {
+	t.Parallel()
+
+	tags, err := ephcli.ParseTagWhere(" tags = draft, q1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"draft", "q1"}, tags)
+
+	for _, invalid := range []string{"draft", "owner=me"} {
+		_, err := ephcli.ParseTagWhere(invalid)
+		require.ErrorIs(t, err, ephcli.ErrInvalidTagWhere, invalid)
+	}
+}
+
+func TestApplyTagOperation(t *testing.T) {
+	t.Parallel()
+
+	current := []string{"invoice", "draft"}
+	tests := []struct {
+		name     string
+		op       ephcli.TagOperation
+		tags     []string
+		expected []string
+	}{
+		{name: "add", op: ephcli.TagAdd, tags: []string{"q1", "invoice"}, expected: []string{"invoice", "draft", "q1"}},
+		{name: "remove", op: ephcli.TagRemove, tags: []string{"draft", "missing"}, expected: []string{"invoice"}},
+		{name: "set", op: ephcli.TagSet, tags: []string{"final"}, expected: []string{"final"}},
+		{name: "set empty", op: ephcli.TagSet, tags: nil, expected: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.expected, ephcli.ApplyTagOperation(current, tt.op, tt.tags))
+		})
+	}
+	assert.Equal(t, []string{"invoice", "draft"}, current, "the current tags are not modified")
+}
+
+func TestRenameTag(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{"q1-2024", "invoice"}, ephcli.RenameTag([]string{"2024-q1", "invoice"}, "2024-q1", "q1-2024"))
+	// Renaming to an existing tag merges them
+	assert.Equal(t, []string{"invoice"}, ephcli.RenameTag([]string{"facture", "invoice"}, "facture", "invoice"))
+	assert.True(t, ephcli.SameTags([]string{"a", "b"}, []string{"b", "a"}))
+	assert.False(t, ephcli.SameTags([]string{"a", "b"}, []string{"a"}))
+}
+
+func TestListAllOrganizationFiles(t *testing.T) {
+	t.Parallel()
+
+	const total = 230
+	tests := []struct {
+		name         string
+		maxLimit     int
+		ignoreOffset bool
+		offsets      []int
+		listed       int
+	}{
+		{name: "full pages", maxLimit: 100, offsets: []int{0, 100, 200}, listed: total},
+		{name: "pages capped by the server", maxLimit: 80, offsets: []int{0, 80, 160}, listed: total},
+		{name: "server ignoring the offset", maxLimit: 100, ignoreOffset: true, offsets: []int{0, 100}, listed: 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var offsets []int
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+				offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+				offsets = append(offsets, offset)
+				if tt.ignoreOffset {
+					offset = 0
+				}
+				files := []dto.OrganizationFile{}
+				for i := offset; i < min(offset+min(limit, tt.maxLimit), total); i++ {
+					files = append(files, dto.OrganizationFile{ID: fmt.Sprintf("file-%d", i)})
+				}
+				w.Header().Set("Content-Type", "application/json")
+				_ = json.NewEncoder(w).Encode(files)
+			}))
+			t.Cleanup(ts.Close)
+
+			client := ephcli.NewClient("token")
+			client.SetEndpoint(ts.URL)
+			files, err := client.ListAllOrganizationFiles("org-1")
+			require.NoError(t, err)
+			require.Len(t, files, tt.listed)
+			assert.Equal(t, fmt.Sprintf("file-%d", tt.listed-1), files[tt.listed-1].ID)
+			assert.Equal(t, tt.offsets, offsets)
+		})
+	}
+}
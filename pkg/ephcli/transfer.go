@@ -0,0 +1,78 @@
+package ephcli
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// TransferUpload is the direction of an upload.
+	TransferUpload = "upload"
+	// TransferDownload is the direction of a download.
+	TransferDownload = "download"
+)
+
+// Transfer describes an upload or a download, reported to the TransferRecorder
+// of the client once it ends.
+type Transfer struct {
+	// Direction is TransferUpload or TransferDownload.
+	Direction string
+	// FileID is the ID of the file on the server, empty when unknown (failed
+	// uploads, shared links).
+	FileID string
+	// OrganizationID is the organization of the file, empty for the box.
+	OrganizationID string
+	// Path is the absolute path of the local file, empty when the transfer
+	// failed before it was known or for uploads from a URL.
+	Path string
+	// Source is the URL of an upload from a URL.
+	Source string
+	// Encryption is dto.EncryptionModeE2E or dto.EncryptionModeClear.
+	Encryption string
+	// Size is the size of the local file once transferred, 0 when unknown.
+	Size int64
+	// Checksum is the checksum of the transferred data, computed while it was
+	// sent or received, empty when the transfer failed.
+	Checksum string
+	// Started is when the transfer started.
+	Started time.Time
+	// Duration is how long the transfer took.
+	Duration time.Duration
+	// Err is the error of a failed transfer, nil on success.
+	Err error
+}
+
+// TransferRecorder is called at the end of each upload and download, except
+// downloads skipped because the target exists.
+type TransferRecorder func(Transfer)
+
+// SetTransferRecorder sets the function called at the end of each upload and
+// download. A nil recorder disables the recording.
+func (c *ClientEphemeralfiles) SetTransferRecorder(recorder TransferRecorder) {
+	c.recorder = recorder
+}
+
+// recordTransfer completes t with its timing, error and local size, and reports
+// it to the recorder of the client.
+func (c *ClientEphemeralfiles) recordTransfer(t Transfer, started time.Time, err error) {
+	if c.recorder == nil || errors.Is(err, ErrDownloadSkipped) {
+		return
+	}
+	t.Started = started
+	t.Duration = time.Since(started)
+	t.Err = err
+	if t.Path != "" {
+		if abs, absErr := filepath.Abs(t.Path); absErr == nil {
+			t.Path = abs
+		}
+		// A failed download may leave a previous file at its path
+		if err == nil || t.Direction == TransferUpload {
+			if info, statErr := os.Stat(t.Path); statErr == nil && info.Mode().IsRegular() {
+				t.Size = info.Size()
+			}
+		}
+	}
+	c.recorder(t)
+}
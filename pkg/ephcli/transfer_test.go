@@ -0,0 +1,78 @@
+package ephcli_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ephemeralfiles/eph/pkg/dto"
+	"github.com/ephemeralfiles/eph/pkg/ephcli"
+	"github.com/ephemeralfiles/eph/pkg/mockserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransferRecorder(t *testing.T) {
+	t.Parallel()
+
+	client := newSeededClient(t)
+	var transfers []ephcli.Transfer
+	client.SetTransferRecorder(func(transfer ephcli.Transfer) {
+		transfers = append(transfers, transfer)
+	})
+
+	dir := t.TempDir()
+	source := filepath.Join(dir, "notes.txt")
+	require.NoError(t, os.WriteFile(source, []byte("some notes"), 0600))
+
+	// An E2E upload then its download
+	uploaded, err := client.UploadE2EWithOptions(source, ephcli.UploadOptions{})
+	require.NoError(t, err)
+	fileID := uploaded.FileID
+	target := filepath.Join(dir, "copy.txt")
+	require.NoError(t, client.DownloadE2E(fileID, target))
+
+	// A clear upload to an organization is recorded once
+	_, err = client.UploadWithOptions(source, ephcli.UploadOptions{OrganizationID: mockserver.FixtureOrganizationID})
+	require.NoError(t, err)
+
+	// A failed download is recorded with its error, a skipped one is not
+	require.Error(t, client.DownloadE2E("00000000-0000-4000-8000-000000000000", filepath.Join(dir, "missing")))
+	client.SetExistPolicy(ephcli.ExistSkip)
+	require.ErrorIs(t, client.DownloadE2E(fileID, target), ephcli.ErrDownloadSkipped)
+
+	require.Len(t, transfers, 4)
+	checksum, err := ephcli.FileChecksum(source)
+	require.NoError(t, err)
+
+	upload := transfers[0]
+	assert.Equal(t, ephcli.TransferUpload, upload.Direction)
+	assert.Equal(t, fileID, upload.FileID)
+	assert.Equal(t, source, upload.Path)
+	assert.Equal(t, dto.EncryptionModeE2E, upload.Encryption)
+	assert.Equal(t, int64(len("some notes")), upload.Size)
+	assert.Equal(t, checksum, upload.Checksum)
+	assert.Equal(t, checksum, uploaded.Checksum, "the result carries the checksum of the upload")
+	assert.False(t, upload.Started.IsZero())
+	require.NoError(t, upload.Err)
+
+	download := transfers[1]
+	assert.Equal(t, ephcli.TransferDownload, download.Direction)
+	assert.Equal(t, fileID, download.FileID)
+	assert.Equal(t, target, download.Path)
+	assert.Equal(t, int64(len("some notes")), download.Size)
+	assert.Equal(t, checksum, download.Checksum)
+	require.NoError(t, download.Err)
+
+	orgUpload := transfers[2]
+	assert.Equal(t, mockserver.FixtureOrganizationID, orgUpload.OrganizationID)
+	assert.Equal(t, dto.EncryptionModeClear, orgUpload.Encryption)
+	assert.NotEmpty(t, orgUpload.FileID)
+	assert.Equal(t, checksum, orgUpload.Checksum)
+
+	failed := transfers[3]
+	assert.Equal(t, ephcli.TransferDownload, failed.Direction)
+	require.Error(t, failed.Err)
+	assert.Zero(t, failed.Size)
+	assert.Empty(t, failed.Checksum)
+}
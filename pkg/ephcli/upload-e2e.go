@@ -8,19 +8,19 @@ import (
 	"crypto/rand"
 	"errors"
 	"fmt"
+	"hash"
 	"io"
 	"log/slog"
 	"mime/multipart"
 	"net/http"
 	"os"
+	"path/filepath"
+	"time"
 
+	"github.com/ephemeralfiles/eph/pkg/dto"
 	"github.com/schollz/progressbar/v3"
 )
 
-const (
-	chunkSize = 128 * 1024 * 1024 // 128MB chunks
-)
-
 // progressReader wraps an io.Reader and updates a progress bar as bytes are read.
 type progressReader struct {
 	reader io.Reader
@@ -63,26 +63,47 @@ func (c *ClientEphemeralfiles) GetPublicKey() (string, string, string, error) {
 
 // GetPublicKeyWithHeaders retrieves the server's public key with optional organization context.
 func (c *ClientEphemeralfiles) GetPublicKeyWithHeaders(orgID string, tags []string) (string, string, string, error) {
+	session, err := c.initUpload(UploadOptions{OrganizationID: orgID, Tags: tags})
+	if err != nil {
+		return "", "", "", err
+	}
+	return session.transactionID, session.fileID, session.publicKey, nil
+}
+
+// uploadSession is an E2E upload transaction created by the server.
+type uploadSession struct {
+	transactionID string
+	fileID        string
+	publicKey     string
+	limits        chunkLimits
+}
+
+// initUpload creates a new E2E upload transaction with the organization, tags
+// and expiration of opts.
+func (c *ClientEphemeralfiles) initUpload(opts UploadOptions) (*uploadSession, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), DefaultAPIRequestTimeout)
 	defer cancel()
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.GetPublicKeyEndpoint(), nil)
 	if err != nil {
-		return "", "", "", fmt.Errorf("error creating request: %w", err)
+		return nil, fmt.Errorf("error creating request: %w", err)
 	}
 	// Set headers
 	req.Header.Set("Authorization", "Bearer "+c.token)
 
 	// Add organization headers if provided
-	if orgID != "" {
-		req.Header.Set("X-Organization-Id", orgID)
+	if opts.OrganizationID != "" {
+		req.Header.Set("X-Organization-Id", opts.OrganizationID)
+	}
+	if len(opts.Tags) > 0 {
+		req.Header.Set("X-File-Tags", joinTags(opts.Tags))
 	}
-	if len(tags) > 0 {
-		req.Header.Set("X-File-Tags", joinTags(tags))
+	if !opts.ExpiresAt.IsZero() {
+		req.Header.Set("X-Expiration-Date", formatExpiration(opts.ExpiresAt))
 	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return "", "", "", fmt.Errorf("error sending request: %w", err)
+		return nil, fmt.Errorf("error sending request: %w", err)
 	}
 	defer func() {
 		if closeErr := resp.Body.Close(); closeErr != nil {
@@ -91,132 +112,196 @@ func (c *ClientEphemeralfiles) GetPublicKeyWithHeaders(orgID string, tags []stri
 	}()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", "", "", fmt.Errorf("%w: %d", ErrUnexpectedStatusCode, resp.StatusCode)
+		return nil, fmt.Errorf("%w: %d", ErrUnexpectedStatusCode, resp.StatusCode)
 	}
 
-	// Get Header X-File-Id from Header
-	fileID := resp.Header.Get("X-File-Id")
-	if fileID == "" {
-		return "", "", "", fmt.Errorf("error reading response: %w", err)
+	session := &uploadSession{
+		fileID:        resp.Header.Get("X-File-Id"),
+		publicKey:     resp.Header.Get("X-File-Public-Key"),
+		transactionID: resp.Header.Get("X-Upload-Id"),
+		limits:        chunkLimitsFromHeaders(resp.Header),
 	}
-	// Get Header X-File-Public-Key from Header
-	publicKey := resp.Header.Get("X-File-Public-Key")
-	if publicKey == "" {
-		return "", "", "", fmt.Errorf("error reading response: %w", err)
+	if session.fileID == "" {
+		return nil, fmt.Errorf("error reading response: %w", ErrMissingHeaderFileID)
 	}
-	transactionID := resp.Header.Get("X-Upload-Id")
-	if transactionID == "" {
-		return "", "", "", fmt.Errorf("error reading response: %w", err)
+	if session.publicKey == "" {
+		return nil, fmt.Errorf("error reading response: %w", ErrMissingHeaderPublicKey)
+	}
+	if session.transactionID == "" {
+		return nil, fmt.Errorf("error reading response: %w", ErrMissingHeaderUploadID)
 	}
 
-	c.log.Debug("GetPublicKey", slog.String("X-File-Public-Key", publicKey))
-	c.log.Debug("GetPublicKey", slog.String("X-File-Id", fileID))
-	c.log.Debug("GetPublicKey", slog.String("X-Upload-Id", transactionID))
-	return transactionID, fileID, publicKey, nil
+	c.log.Debug("GetPublicKey", slog.String("X-File-Public-Key", session.publicKey))
+	c.log.Debug("GetPublicKey", slog.String("X-File-Id", session.fileID))
+	c.log.Debug("GetPublicKey", slog.String("X-Upload-Id", session.transactionID))
+	c.log.Debug("GetPublicKey",
+		slog.Int64("minChunkSize", session.limits.min),
+		slog.Int64("maxChunkSize", session.limits.max))
+	return session, nil
 }
 
 // UploadFileInChunks uploads a file in encrypted chunks for E2E encryption.
 func (c *ClientEphemeralfiles) UploadFileInChunks(aeskey []byte, filePath, targetURL string) error {
+	_, err := c.uploadFileInChunks(aeskey, filePath, filepath.Base(filePath), targetURL, chunkLimits{}, io.Discard)
+	return err
+}
+
+// uploadFileInChunks uploads a file in encrypted chunks sized within the limits of the server,
+// under the given name, writing its content to sum. It returns the number of bytes uploaded.
+func (c *ClientEphemeralfiles) uploadFileInChunks(
+	aeskey []byte, filePath, name, targetURL string, limits chunkLimits, sum io.Writer,
+) (int64, error) {
 	c.log.Debug("UploadFileInChunks", slog.String("aeskey", string(aeskey)))
 	c.log.Debug("UploadFileInChunks", slog.String("filePath", filePath))
 	c.log.Debug("UploadFileInChunks", slog.String("targetURL", targetURL))
-	
+
 	file, fileSize, err := c.openFileForUpload(filePath)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	defer func() {
 		_ = file.Close()
 	}()
 
+	return c.uploadStreamInChunks(aeskey, io.TeeReader(file, sum), fileSize, name, targetURL, limits)
+}
+
+// uploadStreamInChunks reads the size bytes of r sequentially and uploads them
+// in encrypted chunks sized within the limits of the server. It returns the
+// number of bytes uploaded.
+func (c *ClientEphemeralfiles) uploadStreamInChunks(
+	aeskey []byte, r io.Reader, size int64, name, targetURL string, limits chunkLimits,
+) (int64, error) {
 	// Create progress bar
-	c.InitProgressBar("uploading file...", fileSize)
+	c.InitProgressBar("uploading file...", size)
 	defer c.CloseProgressBar()
 
-	// Upload file in chunks
-	for start := int64(0); start < fileSize; start += chunkSize {
-		end := c.calculateChunkEnd(start, fileSize)
-		if err := c.uploadSingleChunk(file, aeskey, targetURL, start, end, fileSize); err != nil {
-			return err
+	sizer := c.newChunkSizer(limits)
+	for start := int64(0); start < size; {
+		chunk := make([]byte, min(sizer.next(), size-start))
+		n, err := io.ReadFull(r, chunk)
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return 0, fmt.Errorf("%w: %d bytes read, %d expected", ErrSourceTruncated, start+int64(n), size)
+		}
+		if err != nil {
+			return 0, fmt.Errorf("%w: %w", ErrReadingChunk, err)
+		}
+
+		end := start + int64(n) - 1
+		chunkStart := time.Now()
+		if err := c.uploadSingleChunk(chunk, name, aeskey, targetURL, start, end, size); err != nil {
+			return 0, err
 		}
 		// Progress is now tracked automatically by progressReader in sendChunkRequest
+		sizer.observe(int64(n), time.Since(chunkStart))
+		start = end + 1
 	}
-	return nil
+	return size, nil
 }
 
 // UploadE2E uploads a file using end-to-end encryption.
-func (c *ClientEphemeralfiles) UploadE2E(fileToUpload string) error {
-	transactionID, fileID, pubkey, err := c.GetPublicKey()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error getting public key: %s\n", err.Error())
-		os.Exit(1)
-	}
-	c.log.Debug("UploadE2E", slog.String("fileID", fileID))
-	c.log.Debug("UploadE2E", slog.String("pubkey", pubkey))
+func (c *ClientEphemeralfiles) UploadE2E(fileToUpload string) (*UploadResult, error) {
+	return c.UploadE2EWithOptions(fileToUpload, UploadOptions{})
+}
 
-	// Generate and encrypt AES key using shared utility
-	keyBundle, err := GenerateAndEncryptAESKey(pubkey)
+// UploadOrganizationFileE2E uploads a file to an organization using end-to-end encryption.
+func (c *ClientEphemeralfiles) UploadOrganizationFileE2E(
+	orgID string, fileToUpload string, tags []string,
+) (string, error) {
+	result, err := c.UploadE2EWithOptions(fileToUpload, UploadOptions{OrganizationID: orgID, Tags: tags})
+	return result.id(), err
+}
+
+// UploadE2EWithOptions uploads a file using end-to-end encryption.
+func (c *ClientEphemeralfiles) UploadE2EWithOptions(fileToUpload string, opts UploadOptions) (*UploadResult, error) {
+	started, sum := time.Now(), newChecksum()
+	result, err := c.uploadE2E(fileToUpload, opts, sum)
+	checksum := transferChecksum(sum, err)
+	if result != nil {
+		result.Checksum = checksum
+	}
+	c.recordTransfer(Transfer{
+		Direction: TransferUpload, FileID: result.id(), OrganizationID: opts.OrganizationID, Path: fileToUpload,
+		Encryption: dto.EncryptionModeE2E, Checksum: checksum,
+	}, started, err)
+	return result, err
+}
+
+// uploadE2E uploads a file using end-to-end encryption, writing its content to sum.
+func (c *ClientEphemeralfiles) uploadE2E(
+	fileToUpload string, opts UploadOptions, sum hash.Hash,
+) (*UploadResult, error) {
+	name, err := opts.remoteName(fileToUpload)
 	if err != nil {
-		return fmt.Errorf("error generating and encrypting AES key: %w", err)
+		return nil, err
 	}
-
-	c.log.Debug("UploadE2E", slog.String("aesKey", string(keyBundle.AESKey)))
-	c.log.Debug("UploadE2E", slog.String("hexString", keyBundle.HexString))
-	c.log.Debug("UploadE2E", slog.String("encryptedAESKey", keyBundle.EncryptedAESKey))
 	c.log.Debug("UploadE2E", slog.String("fileToUpload", fileToUpload))
-
-	// Send the encrypted AES key to the server using shared utility
-	err = c.SendAESKeyToEndpoint(c.SendAESKeyEndpoint(transactionID), keyBundle.EncryptedAESKey)
+	session, aesKey, err := c.startE2EUpload(opts)
 	if err != nil {
-		return fmt.Errorf("error sending AES key: %w", err)
+		return nil, err
 	}
 
 	// Upload the file
-	err = c.UploadFileInChunks(keyBundle.AESKey, fileToUpload, c.UploadE2EEndpoint(transactionID))
+	size, err := c.uploadFileInChunks(aesKey, fileToUpload, name, c.UploadE2EEndpoint(session.transactionID),
+		session.limits, sum)
 	if err != nil {
-		return fmt.Errorf("error uploading file: %w", err)
+		return nil, fmt.Errorf("error uploading file: %w", err)
 	}
-	return nil
+
+	return c.e2eUploadResult(session.fileID, name, size, opts), nil
 }
 
-// UploadOrganizationFileE2E uploads a file to an organization using end-to-end encryption.
-func (c *ClientEphemeralfiles) UploadOrganizationFileE2E(
-	orgID string, fileToUpload string, tags []string,
-) (string, error) {
-	transactionID, fileID, pubkey, err := c.GetPublicKeyWithHeaders(orgID, tags)
+// e2eUploadResult returns the result of an E2E upload. The server does not
+// return the file: when no expiration was requested, the one set by the server
+// is fetched, and left empty if it cannot be.
+func (c *ClientEphemeralfiles) e2eUploadResult(fileID, name string, size int64, opts UploadOptions) *UploadResult {
+	result := &UploadResult{
+		FileID:         fileID,
+		Name:           name,
+		Size:           size,
+		ExpiresAt:      opts.ExpiresAt,
+		OrganizationID: opts.OrganizationID,
+		Encryption:     dto.EncryptionModeE2E,
+	}
+	if result.ExpiresAt.IsZero() {
+		info, err := c.GetFileInfo(fileID)
+		if err != nil {
+			c.log.Debug("cannot fetch the expiration of the uploaded file", slog.String("error", err.Error()))
+		} else {
+			result.ExpiresAt = info.ExpirationDate
+		}
+	}
+	return result
+}
+
+// startE2EUpload creates an E2E upload transaction and sends it a new AES key,
+// which is returned to encrypt the chunks.
+func (c *ClientEphemeralfiles) startE2EUpload(opts UploadOptions) (*uploadSession, []byte, error) {
+	session, err := c.initUpload(opts)
 	if err != nil {
-		return "", fmt.Errorf("error getting public key: %w", err)
+		return nil, nil, fmt.Errorf("error getting public key: %w", err)
 	}
-	c.log.Debug("UploadOrganizationFileE2E", slog.String("fileID", fileID), slog.String("orgID", orgID))
-	c.log.Debug("UploadOrganizationFileE2E", slog.String("pubkey", pubkey))
+	c.log.Debug("UploadE2E", slog.String("fileID", session.fileID), slog.String("orgID", opts.OrganizationID))
+	c.log.Debug("UploadE2E", slog.String("pubkey", session.publicKey))
 
 	// Generate and encrypt AES key using shared utility
-	keyBundle, err := GenerateAndEncryptAESKey(pubkey)
+	keyBundle, err := GenerateAndEncryptAESKey(session.publicKey)
 	if err != nil {
-		return "", fmt.Errorf("error generating and encrypting AES key: %w", err)
+		return nil, nil, fmt.Errorf("error generating and encrypting AES key: %w", err)
 	}
 
-	c.log.Debug("UploadOrganizationFileE2E", slog.String("aesKey", string(keyBundle.AESKey)))
-	c.log.Debug("UploadOrganizationFileE2E", slog.String("hexString", keyBundle.HexString))
-	c.log.Debug("UploadOrganizationFileE2E", slog.String("encryptedAESKey", keyBundle.EncryptedAESKey))
-	c.log.Debug("UploadOrganizationFileE2E", slog.String("fileToUpload", fileToUpload))
+	c.log.Debug("UploadE2E", slog.String("aesKey", string(keyBundle.AESKey)))
+	c.log.Debug("UploadE2E", slog.String("hexString", keyBundle.HexString))
+	c.log.Debug("UploadE2E", slog.String("encryptedAESKey", keyBundle.EncryptedAESKey))
 
 	// Send the encrypted AES key to the server using shared utility
-	err = c.SendAESKeyToEndpoint(c.SendAESKeyEndpoint(transactionID), keyBundle.EncryptedAESKey)
+	err = c.SendAESKeyToEndpoint(c.SendAESKeyEndpoint(session.transactionID), keyBundle.EncryptedAESKey)
 	if err != nil {
-		return "", fmt.Errorf("error sending AES key: %w", err)
+		return nil, nil, fmt.Errorf("error sending AES key: %w", err)
 	}
-
-	// Upload the file
-	err = c.UploadFileInChunks(keyBundle.AESKey, fileToUpload, c.UploadE2EEndpoint(transactionID))
-	if err != nil {
-		return "", fmt.Errorf("error uploading file: %w", err)
-	}
-
-	return fileID, nil
+	return session, keyBundle.AESKey, nil
 }
 
-
 // EncryptAES encrypts plaintext using AES encryption with the provided key.
 func EncryptAES(key []byte, plaintext []byte) ([]byte, error) {
 	// Create new cipher block
@@ -257,48 +342,28 @@ func (c *ClientEphemeralfiles) openFileForUpload(filePath string) (*os.File, int
 	return file, fileInfo.Size(), nil
 }
 
-// calculateChunkEnd calculates the end position for a chunk.
-func (c *ClientEphemeralfiles) calculateChunkEnd(start, fileSize int64) int64 {
-	end := start + chunkSize - 1
-	if end >= fileSize {
-		return fileSize - 1
-	}
-	return end
-}
-
-// uploadSingleChunk uploads a single encrypted chunk.
+// uploadSingleChunk encrypts and uploads a single chunk holding the bytes start
+// to end of a file of fileSize bytes.
 func (c *ClientEphemeralfiles) uploadSingleChunk(
-	file *os.File, aeskey []byte, targetURL string, start, end, fileSize int64,
+	chunk []byte, name string, aeskey []byte, targetURL string, start, end, fileSize int64,
 ) error {
-	// Read chunk
-	chunkSize := end - start + 1
-	chunk := make([]byte, chunkSize)
-	bytesRead, err := file.ReadAt(chunk, start)
-	if err != nil && !errors.Is(err, io.EOF) {
-		return fmt.Errorf("error reading chunk: %w", err)
-	}
-
 	c.log.Debug("Read chunk",
 		slog.Int64("start", start),
 		slog.Int64("end", end),
-		slog.Int("expectedSize", int(chunkSize)),
-		slog.Int("actualBytesRead", bytesRead))
-
-	// Use only the bytes actually read
-	actualChunk := chunk[:bytesRead]
+		slog.Int("size", len(chunk)))
 
 	// Encrypt chunk
-	encryptedChunk, err := EncryptAES(aeskey, actualChunk)
+	encryptedChunk, err := EncryptAES(aeskey, chunk)
 	if err != nil {
 		return fmt.Errorf("error encrypting chunk: %w", err)
 	}
 
 	c.log.Debug("Encrypted chunk",
-		slog.Int("plaintextSize", bytesRead),
+		slog.Int("plaintextSize", len(chunk)),
 		slog.Int("encryptedSize", len(encryptedChunk)))
 
 	// Create multipart form
-	body, contentType, err := c.createChunkForm(encryptedChunk, file)
+	body, contentType, err := c.createChunkForm(encryptedChunk, name)
 	if err != nil {
 		return err
 	}
@@ -308,16 +373,11 @@ func (c *ClientEphemeralfiles) uploadSingleChunk(
 }
 
 // createChunkForm creates multipart form for chunk upload.
-func (c *ClientEphemeralfiles) createChunkForm(encryptedChunk []byte, file *os.File) (*bytes.Buffer, string, error) {
+func (c *ClientEphemeralfiles) createChunkForm(encryptedChunk []byte, name string) (*bytes.Buffer, string, error) {
 	body := &bytes.Buffer{}
 	writer := multipart.NewWriter(body)
 
-	fileInfo, err := file.Stat()
-	if err != nil {
-		return nil, "", fmt.Errorf("error getting file info: %w", err)
-	}
-
-	part, err := writer.CreateFormFile("uploadfile", fileInfo.Name())
+	part, err := writer.CreateFormFile("uploadfile", name)
 	if err != nil {
 		return nil, "", fmt.Errorf("error creating form file: %w", err)
 	}
@@ -339,12 +399,12 @@ func (c *ClientEphemeralfiles) createChunkForm(encryptedChunk []byte, file *os.F
 func (c *ClientEphemeralfiles) sendChunkRequest(
 	targetURL string, body *bytes.Buffer, contentType string, start, end, fileSize int64,
 ) error {
-	ctx, cancel := context.WithTimeout(context.Background(), ChunkUploadTimeout)
+	ctx, cancel := context.WithTimeout(context.Background(), c.transferTimeout(ChunkUploadTimeout, int64(body.Len())))
 	defer cancel()
 
 	// Wrap body with progress reader for byte-level progress tracking
 	progressBody := &progressReader{
-		reader: body,
+		reader: c.limitReader(ctx, body),
 		bar:    c.bar,
 	}
 
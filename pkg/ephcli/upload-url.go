@@ -0,0 +1,128 @@
+package ephcli
+
+import (
+	"context"
+	"fmt"
+	"hash"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path"
+	"time"
+
+	"github.com/ephemeralfiles/eph/pkg/dto"
+)
+
+// UploadE2EFromURL fetches sourceURL and streams its body into an end-to-end
+// encrypted upload, without writing it to disk. The size of the body is taken
+// from the Content-Length of the response, which every chunk announces:
+// ErrSourceSizeUnknown is returned without it. The file is named after
+// opts.Name, the Content-Disposition of the response or the last element of the
+// URL path, in that order.
+func (c *ClientEphemeralfiles) UploadE2EFromURL(sourceURL string, opts UploadOptions) (*UploadResult, error) {
+	started, sum := time.Now(), newChecksum()
+	result, err := c.uploadE2EFromURL(sourceURL, opts, sum)
+	transfer := Transfer{
+		Direction: TransferUpload, OrganizationID: opts.OrganizationID, Source: sourceURL,
+		Encryption: dto.EncryptionModeE2E, Checksum: transferChecksum(sum, err),
+	}
+	if result != nil {
+		transfer.FileID, transfer.Size = result.FileID, result.Size
+		result.Checksum = transfer.Checksum
+	}
+	c.recordTransfer(transfer, started, err)
+	return result, err
+}
+
+// uploadE2EFromURL uploads the body of sourceURL using end-to-end encryption,
+// writing it to sum.
+func (c *ClientEphemeralfiles) uploadE2EFromURL(
+	sourceURL string, opts UploadOptions, sum hash.Hash,
+) (*UploadResult, error) {
+	resp, err := c.openSourceURL(sourceURL)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	name, err := sourceName(resp, opts)
+	if err != nil {
+		return nil, err
+	}
+	c.log.Debug("UploadE2EFromURL",
+		slog.String("sourceURL", sourceURL),
+		slog.String("name", name),
+		slog.Int64("contentLength", resp.ContentLength))
+
+	switch {
+	case resp.ContentLength < 0:
+		// Every chunk announces the total size, which is only known from the header
+		return nil, fmt.Errorf("%w: %s", ErrSourceSizeUnknown, sourceURL)
+	case resp.ContentLength == 0:
+		return nil, fmt.Errorf("%w: %s", ErrEmptyUpload, sourceURL)
+	}
+
+	session, aesKey, err := c.startE2EUpload(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	size, err := c.uploadStreamInChunks(aesKey, io.TeeReader(resp.Body, sum), resp.ContentLength, name,
+		c.UploadE2EEndpoint(session.transactionID), session.limits)
+	if err != nil {
+		return nil, fmt.Errorf("error uploading file: %w", err)
+	}
+
+	return c.e2eUploadResult(session.fileID, name, size, opts), nil
+}
+
+// openSourceURL sends a GET request to sourceURL and returns the response once
+// its status is checked. The token of the client is never sent to the source.
+func (c *ClientEphemeralfiles) openSourceURL(sourceURL string) (*http.Response, error) {
+	u, err := url.Parse(sourceURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSourceURL, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("%w: %q, expected an http or https URL", ErrInvalidSourceURL, sourceURL)
+	}
+
+	// The body is streamed for the whole upload: no timeout applies
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCreatingRequest, err)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching source URL: %w", err)
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("%w: %s", ErrSourceStatus, resp.Status)
+	}
+	return resp, nil
+}
+
+// sourceName returns the name of a file uploaded from the response of a source URL.
+func sourceName(resp *http.Response, opts UploadOptions) (string, error) {
+	if opts.Name != "" {
+		if err := ValidateRemoteName(opts.Name); err != nil {
+			return "", err
+		}
+		return opts.Name, nil
+	}
+	if disposition := resp.Header.Get("Content-Disposition"); disposition != "" {
+		if name, err := ParseContentDispositionFilename(disposition); err == nil {
+			return name, nil
+		}
+	}
+	// The URL of the request is the final one, after redirects
+	base := path.Base(resp.Request.URL.Path)
+	if base == "/" || base == "." {
+		return "", fmt.Errorf("%w: no file name in %s, set one with --name", ErrInvalidFilename, resp.Request.URL)
+	}
+	return SanitizeFilename(base)
+}
@@ -1,25 +1,151 @@
 package ephcli
 
 import (
+	"cmp"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"hash"
 	"io"
 	"log/slog"
+	"maps"
 	"mime/multipart"
 	"net/http"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ephemeralfiles/eph/pkg/dto"
 )
 
+// UploadOptions holds the optional settings of an upload.
+type UploadOptions struct {
+	// OrganizationID uploads the file to an organization when set.
+	OrganizationID string
+	// Tags are the tags of an organization file.
+	Tags []string
+	// ExpiresAt replaces the default retention of the file when set.
+	ExpiresAt time.Time
+	// Name is the name of the file on the server. It defaults to the base
+	// name of the local file.
+	Name string
+	// KeepPath sends the local path as given instead of its base name, when
+	// Name is not set.
+	KeepPath bool
+}
+
+// UploadResult describes an uploaded file.
+type UploadResult struct {
+	FileID string `json:"file_id" yaml:"file_id"`
+	Name   string `json:"name" yaml:"name"`
+	Size   int64  `json:"size" yaml:"size"`
+	// ExpiresAt is the expiration of the file, zero when the server did not report it.
+	ExpiresAt      time.Time `json:"expiration_date,omitzero" yaml:"expiration_date,omitempty"`
+	OrganizationID string    `json:"organization_id,omitempty" yaml:"organization_id,omitempty"`
+	// Encryption is dto.EncryptionModeE2E or dto.EncryptionModeClear.
+	Encryption string `json:"encryption" yaml:"encryption"`
+	// Checksum is the checksum of the uploaded data, computed while it was sent.
+	Checksum string `json:"-" yaml:"-"`
+}
+
+// id returns the ID of the uploaded file, or an empty string for a nil result.
+func (r *UploadResult) id() string {
+	if r == nil {
+		return ""
+	}
+	return r.FileID
+}
+
+// organizationUploadResult returns the result of the upload of an organization file.
+func organizationUploadResult(file *dto.OrganizationFile) *UploadResult {
+	result := &UploadResult{
+		FileID:         file.ID,
+		Name:           file.Filename,
+		Size:           file.Size,
+		OrganizationID: file.OrganizationID,
+		Encryption:     dto.EncryptionModeClear,
+	}
+	// The expiration is informative: an unknown format leaves it empty
+	if expiresAt, err := time.Parse(time.RFC3339, file.ExpirationDate); err == nil {
+		result.ExpiresAt = expiresAt
+	}
+	return result
+}
+
+// remoteName returns the name under which the file at localPath is uploaded.
+func (opts UploadOptions) remoteName(localPath string) (string, error) {
+	switch {
+	case opts.Name != "":
+		if err := ValidateRemoteName(opts.Name); err != nil {
+			return "", err
+		}
+		return opts.Name, nil
+	case opts.KeepPath:
+		return filepath.ToSlash(localPath), nil
+	default:
+		return filepath.Base(localPath), nil
+	}
+}
+
+// formFields returns the multipart form fields sending opts with a clear upload.
+func (opts UploadOptions) formFields() map[string]string {
+	fields := make(map[string]string)
+	if len(opts.Tags) > 0 {
+		fields["tags"] = strings.Join(opts.Tags, ",")
+	}
+	if !opts.ExpiresAt.IsZero() {
+		fields["expiration_date"] = formatExpiration(opts.ExpiresAt)
+	}
+	return fields
+}
+
 // UploadEndpoint returns the API endpoint URL for file uploads.
 func (c *ClientEphemeralfiles) UploadEndpoint() string {
 	return fmt.Sprintf("%s/%s/upload/clear", c.endpoint, apiVersion)
 }
 
 // Upload uploads a file to the ephemeralfiles service.
-func (c *ClientEphemeralfiles) Upload(fileToUpload string) error {
+func (c *ClientEphemeralfiles) Upload(fileToUpload string) (*UploadResult, error) {
+	return c.UploadWithOptions(fileToUpload, UploadOptions{})
+}
+
+// UploadWithOptions uploads a file without encryption. When opts.OrganizationID
+// is set, the file is uploaded to the organization.
+func (c *ClientEphemeralfiles) UploadWithOptions(fileToUpload string, opts UploadOptions) (*UploadResult, error) {
+	started, sum := time.Now(), newChecksum()
+	result, err := c.upload(fileToUpload, opts, sum)
+	checksum := transferChecksum(sum, err)
+	if result != nil {
+		result.Checksum = checksum
+	}
+	c.recordTransfer(Transfer{
+		Direction: TransferUpload, FileID: result.id(), OrganizationID: opts.OrganizationID, Path: fileToUpload,
+		Encryption: dto.EncryptionModeClear, Checksum: checksum,
+	}, started, err)
+	return result, err
+}
+
+// upload uploads a file without encryption, to the box or to the organization of
+// opts, writing its content to sum.
+func (c *ClientEphemeralfiles) upload(fileToUpload string, opts UploadOptions, sum hash.Hash) (*UploadResult, error) {
+	if opts.OrganizationID != "" {
+		file, err := c.uploadOrganizationFile(fileToUpload, opts, sum)
+		if err != nil {
+			return nil, err
+		}
+		return organizationUploadResult(file), nil
+	}
+
+	name, err := opts.remoteName(fileToUpload)
+	if err != nil {
+		return nil, err
+	}
 	stat, err := c.validateAndGetFileInfo(fileToUpload)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	c.InitProgressBar("uploading file...", stat.Size())
@@ -29,9 +155,24 @@ func (c *ClientEphemeralfiles) Upload(fileToUpload string) error {
 	pr, pw := io.Pipe()
 	writer := multipart.NewWriter(pw)
 
-	go c.createMultipartForm(writer, pw, fileToUpload)
+	go c.createMultipartForm(writer, pw, fileToUpload, name, opts.formFields(), sum)
 
-	return c.sendUploadRequest(pr, writer)
+	file, err := c.sendUploadRequest(pr, writer)
+	if err != nil {
+		return nil, err
+	}
+	// Older servers answer without the file: the local values apply
+	result := &UploadResult{
+		FileID:     file.FileID,
+		Name:       cmp.Or(file.FileName, name),
+		Size:       file.Size,
+		ExpiresAt:  file.ExpirationDate,
+		Encryption: dto.EncryptionModeClear,
+	}
+	if result.Size == 0 {
+		result.Size = stat.Size()
+	}
+	return result, nil
 }
 
 // validateAndGetFileInfo validates file existence and returns file info.
@@ -46,13 +187,21 @@ func (c *ClientEphemeralfiles) validateAndGetFileInfo(fileToUpload string) (os.F
 	return stat, nil
 }
 
-// createMultipartForm creates and populates the multipart form.
-func (c *ClientEphemeralfiles) createMultipartForm(writer *multipart.Writer, pw *io.PipeWriter, fileToUpload string) {
+// createMultipartForm creates and populates the multipart form. The content of
+// the file is written to sum as it is sent.
+func (c *ClientEphemeralfiles) createMultipartForm(
+	writer *multipart.Writer,
+	pw *io.PipeWriter,
+	fileToUpload string,
+	name string,
+	fields map[string]string,
+	sum hash.Hash,
+) {
 	defer func() {
 		_ = pw.Close()
 	}()
 
-	part, err := writer.CreateFormFile("uploadfile", fileToUpload)
+	part, err := writer.CreateFormFile("uploadfile", name)
 	if err != nil {
 		pw.CloseWithError(err)
 		return
@@ -68,7 +217,13 @@ func (c *ClientEphemeralfiles) createMultipartForm(writer *multipart.Writer, pw
 		_ = f.Close()
 	}()
 
-	if _, err := io.Copy(io.MultiWriter(part, c.bar), f); err != nil {
+	body := io.TeeReader(c.limitReader(context.Background(), f), sum)
+	if _, err := io.Copy(io.MultiWriter(part, c.bar), body); err != nil {
+		pw.CloseWithError(err)
+		return
+	}
+
+	if err := writeFormFields(writer, fields); err != nil {
 		pw.CloseWithError(err)
 		return
 	}
@@ -76,12 +231,23 @@ func (c *ClientEphemeralfiles) createMultipartForm(writer *multipart.Writer, pw
 	_ = writer.Close()
 }
 
-// sendUploadRequest creates and sends the upload HTTP request.
-func (c *ClientEphemeralfiles) sendUploadRequest(pr *io.PipeReader, writer *multipart.Writer) error {
+// writeFormFields writes fields to a multipart form, in a stable order.
+func writeFormFields(writer *multipart.Writer, fields map[string]string) error {
+	for _, name := range slices.Sorted(maps.Keys(fields)) {
+		if err := writer.WriteField(name, fields[name]); err != nil {
+			return fmt.Errorf("error writing field %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// sendUploadRequest creates and sends the upload HTTP request, and returns the
+// uploaded file (empty when the server does not describe it).
+func (c *ClientEphemeralfiles) sendUploadRequest(pr *io.PipeReader, writer *multipart.Writer) (*dto.File, error) {
 	ctx := context.Background()
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.UploadEndpoint(), pr)
 	if err != nil {
-		return fmt.Errorf("error creating request: %w", err)
+		return nil, fmt.Errorf("error creating request: %w", err)
 	}
 
 	req.Header.Add("Authorization", "Bearer "+c.token)
@@ -89,7 +255,7 @@ func (c *ClientEphemeralfiles) sendUploadRequest(pr *io.PipeReader, writer *mult
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("error sending request: %w", err)
+		return nil, fmt.Errorf("error sending request: %w", err)
 	}
 
 	defer func() {
@@ -99,7 +265,12 @@ func (c *ClientEphemeralfiles) sendUploadRequest(pr *io.PipeReader, writer *mult
 	}()
 
 	if resp.StatusCode != http.StatusOK {
-		return parseError(resp)
+		return nil, parseError(resp)
 	}
-	return nil
+
+	var file dto.File
+	if err := json.NewDecoder(resp.Body).Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: %w", ErrDecodingResponse, err)
+	}
+	return &file, nil
 }
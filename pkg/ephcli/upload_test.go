@@ -1,6 +1,7 @@
 package ephcli_test
 
 import (
+	"cmp"
 	"crypto/rand"
 	"io"
 	"net/http"
@@ -8,8 +9,11 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/ephemeralfiles/eph/pkg/dto"
 	"github.com/ephemeralfiles/eph/pkg/ephcli"
+	"github.com/ephemeralfiles/eph/pkg/mockserver"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -88,7 +92,7 @@ func TestUpload(t *testing.T) {
 		client.DisableProgressBar()
 
 		// Perform upload
-		err = client.Upload(testFile)
+		_, err = client.Upload(testFile)
 		assert.NoError(t, err)
 	})
 
@@ -98,7 +102,7 @@ func TestUpload(t *testing.T) {
 		client := ephcli.NewClient("test-token")
 		client.DisableProgressBar()
 
-		err := client.Upload("nonexistent-file.txt")
+		_, err := client.Upload("nonexistent-file.txt")
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "nonexistent-file.txt")
 	})
@@ -123,7 +127,7 @@ func TestUpload(t *testing.T) {
 		client.SetEndpoint(ts.URL)
 		client.DisableProgressBar()
 
-		err = client.Upload(testFile)
+		_, err = client.Upload(testFile)
 		assert.Error(t, err)
 	})
 
@@ -169,7 +173,7 @@ func TestUpload(t *testing.T) {
 		client.SetEndpoint(ts.URL)
 		client.DisableProgressBar()
 
-		err = client.Upload(testFile)
+		_, err = client.Upload(testFile)
 		assert.NoError(t, err)
 		assert.Equal(t, int64(1024*1024), receivedSize)
 	})
@@ -188,7 +192,7 @@ func TestUpload(t *testing.T) {
 		client.SetEndpoint("http://invalid-host-that-does-not-exist.local")
 		client.DisableProgressBar()
 
-		err = client.Upload(testFile)
+		_, err = client.Upload(testFile)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "error sending request")
 	})
@@ -221,7 +225,7 @@ func TestValidateAndGetFileInfo(t *testing.T) {
 		client.DisableProgressBar()
 
 		// This should succeed, indicating file validation passed
-		err = client.Upload(testFile)
+		_, err = client.Upload(testFile)
 		assert.NoError(t, err)
 	})
 
@@ -231,7 +235,7 @@ func TestValidateAndGetFileInfo(t *testing.T) {
 		client := ephcli.NewClient("test-token")
 		client.DisableProgressBar()
 
-		err := client.Upload("/path/that/does/not/exist.txt")
+		_, err := client.Upload("/path/that/does/not/exist.txt")
 		assert.Error(t, err)
 		// The error should mention the file not being found
 		assert.Contains(t, err.Error(), "/path/that/does/not/exist.txt")
@@ -245,7 +249,7 @@ func TestValidateAndGetFileInfo(t *testing.T) {
 		client := ephcli.NewClient("test-token")
 		client.DisableProgressBar()
 
-		err := client.Upload(tempDir)
+		_, err := client.Upload(tempDir)
 		assert.Error(t, err)
 	})
 
@@ -275,7 +279,7 @@ func TestValidateAndGetFileInfo(t *testing.T) {
 		client := ephcli.NewClient("test-token")
 		client.DisableProgressBar()
 
-		err = client.Upload(testFile)
+		_, err = client.Upload(testFile)
 		assert.Error(t, err)
 	})
 }
@@ -327,7 +331,7 @@ func TestCreateMultipartForm(t *testing.T) {
 		client.SetEndpoint(ts.URL)
 		client.DisableProgressBar()
 
-		err = client.Upload(testFile)
+		_, err = client.Upload(testFile)
 		require.NoError(t, err)
 
 		// Verify the multipart form was created correctly
@@ -363,7 +367,7 @@ func TestSendUploadRequest(t *testing.T) {
 		client.SetEndpoint(ts.URL)
 		client.DisableProgressBar()
 
-		err = client.Upload(testFile)
+		_, err = client.Upload(testFile)
 		require.NoError(t, err)
 
 		// Verify request properties
@@ -407,7 +411,7 @@ func TestSendUploadRequest(t *testing.T) {
 				client.SetEndpoint(ts.URL)
 				client.DisableProgressBar()
 
-				err := client.Upload(testFile)
+				_, err := client.Upload(testFile)
 
 				if statusCode == http.StatusOK {
 					assert.NoError(t, err)
@@ -453,7 +457,7 @@ func TestUploadEdgeCases(t *testing.T) {
 		client.SetEndpoint(ts.URL)
 		client.DisableProgressBar()
 
-		err = client.Upload(testFile)
+		_, err = client.Upload(testFile)
 		assert.NoError(t, err)
 	})
 
@@ -484,7 +488,7 @@ func TestUploadEdgeCases(t *testing.T) {
 		client.SetEndpoint(ts.URL)
 		client.DisableProgressBar()
 
-		err = client.Upload(testFile)
+		_, err = client.Upload(testFile)
 		require.NoError(t, err)
 		assert.Equal(t, filepath.Base(testFile), receivedFilename)
 	})
@@ -507,7 +511,56 @@ func TestUploadEdgeCases(t *testing.T) {
 		client.SetEndpoint(ts.URL)
 		// Progress bar enabled (default)
 
-		err = client.Upload(testFile)
+		_, err = client.Upload(testFile)
 		assert.NoError(t, err)
 	})
-}
\ No newline at end of file
+}
+
+func TestUploadResult(t *testing.T) {
+	t.Parallel()
+
+	client := newSeededClient(t)
+	src := filepath.Join(t.TempDir(), "notes.txt")
+	require.NoError(t, os.WriteFile(src, []byte("some notes"), 0600))
+	expiresAt := time.Now().Add(3 * time.Hour).UTC().Truncate(time.Second)
+
+	tests := []struct {
+		name       string
+		upload     func(string, ephcli.UploadOptions) (*ephcli.UploadResult, error)
+		opts       ephcli.UploadOptions
+		encryption string
+	}{
+		{name: "E2E", upload: client.Clone().UploadE2EWithOptions, encryption: dto.EncryptionModeE2E},
+		{
+			name:       "E2E with expiration",
+			upload:     client.Clone().UploadE2EWithOptions,
+			opts:       ephcli.UploadOptions{ExpiresAt: expiresAt, Name: "renamed.txt"},
+			encryption: dto.EncryptionModeE2E,
+		},
+		{name: "clear", upload: client.Clone().UploadWithOptions, encryption: dto.EncryptionModeClear},
+		{
+			name:       "clear to an organization",
+			upload:     client.Clone().UploadWithOptions,
+			opts:       ephcli.UploadOptions{OrganizationID: mockserver.FixtureOrganizationID},
+			encryption: dto.EncryptionModeClear,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := tt.upload(src, tt.opts)
+			require.NoError(t, err)
+			assert.NotEmpty(t, result.FileID)
+			assert.Equal(t, cmp.Or(tt.opts.Name, "notes.txt"), result.Name)
+			assert.Equal(t, int64(len("some notes")), result.Size)
+			assert.Equal(t, tt.opts.OrganizationID, result.OrganizationID)
+			assert.Equal(t, tt.encryption, result.Encryption)
+			if tt.opts.ExpiresAt.IsZero() {
+				assert.False(t, result.ExpiresAt.IsZero(), "the expiration of the server is reported")
+			} else {
+				assert.True(t, tt.opts.ExpiresAt.Equal(result.ExpiresAt))
+			}
+		})
+	}
+}
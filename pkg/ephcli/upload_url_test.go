@@ -0,0 +1,143 @@
+package ephcli_test
+
+import (
+	"crypto/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ephemeralfiles/eph/pkg/ephcli"
+	"github.com/ephemeralfiles/eph/pkg/mockserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSourceOrigin starts an origin server serving content at /exports/report.bin,
+// with a Content-Length header unless chunked is set. It reports whether any
+// request carried an Authorization header.
+func newSourceOrigin(t *testing.T, content []byte, chunked bool) (*httptest.Server, *atomic.Bool) {
+	t.Helper()
+	var sawAuth atomic.Bool
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			sawAuth.Store(true)
+		}
+		switch r.URL.Path {
+		case "/exports/report.bin":
+		case "/download":
+			w.Header().Set("Content-Disposition", `attachment; filename="quarterly.bin"`)
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		if !chunked {
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+			_, _ = w.Write(content)
+			return
+		}
+		// Without Content-Length, flushing makes the response chunked
+		for i := 0; i < len(content); i += 10 * 1024 {
+			_, _ = w.Write(content[i:min(i+10*1024, len(content))])
+			w.(http.Flusher).Flush()
+		}
+	}))
+	t.Cleanup(ts.Close)
+	return ts, &sawAuth
+}
+
+func TestUploadE2EFromURL(t *testing.T) {
+	t.Parallel()
+
+	content := make([]byte, 200*1024)
+	_, err := rand.Read(content)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		path     string
+		opts     ephcli.UploadOptions
+		expected string
+	}{
+		{name: "known size", path: "/exports/report.bin", expected: "report.bin"},
+		{name: "content disposition", path: "/download", expected: "quarterly.bin"},
+		{name: "name override", path: "/download", opts: ephcli.UploadOptions{Name: "q3.bin"}, expected: "q3.bin"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			origin, sawAuth := newSourceOrigin(t, content, false)
+			rec := newChunkRecorder(t, mockserver.Options{})
+			rec.client.SetChunkSize(64 * 1024)
+
+			result, err := rec.client.UploadE2EFromURL(origin.URL+tt.path, tt.opts)
+			require.NoError(t, err)
+			assert.False(t, sawAuth.Load(), "the token is not sent to the origin")
+			assert.Equal(t, []int64{64 * 1024, 64 * 1024, 64 * 1024, 8 * 1024}, rec.sizes)
+			assert.Equal(t, tt.expected, result.Name)
+			assert.Equal(t, int64(len(content)), result.Size)
+			fileID := result.FileID
+
+			info, err := rec.client.GetFileInfo(fileID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, info.Filename)
+			assert.Equal(t, int64(len(content)), info.Size)
+
+			out := filepath.Join(t.TempDir(), "downloaded.bin")
+			require.NoError(t, rec.client.DownloadE2E(fileID, out))
+			downloaded, err := os.ReadFile(out)
+			require.NoError(t, err)
+			assert.Equal(t, content, downloaded)
+		})
+	}
+}
+
+func TestUploadE2EFromURLErrors(t *testing.T) {
+	t.Parallel()
+
+	srv, err := mockserver.New(mockserver.Options{})
+	require.NoError(t, err)
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+	client := ephcli.NewClient(mockserver.DevToken(mockserver.DefaultEmail, time.Hour))
+	client.SetEndpoint(ts.URL)
+	client.DisableProgressBar()
+
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/empty.txt":
+			w.Header().Set("Content-Length", "0")
+		case "/truncated.bin":
+			// The connection is closed before the announced size is sent
+			w.Header().Set("Content-Length", "1000")
+			_, _ = w.Write([]byte("short"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(origin.Close)
+
+	_, err = client.UploadE2EFromURL("ftp://example.com/file.bin", ephcli.UploadOptions{})
+	require.ErrorIs(t, err, ephcli.ErrInvalidSourceURL)
+	_, err = client.UploadE2EFromURL(origin.URL+"/missing.bin", ephcli.UploadOptions{})
+	require.ErrorIs(t, err, ephcli.ErrSourceStatus)
+	_, err = client.UploadE2EFromURL(origin.URL+"/", ephcli.UploadOptions{})
+	require.ErrorIs(t, err, ephcli.ErrSourceStatus)
+	_, err = client.UploadE2EFromURL(origin.URL+"/empty.txt", ephcli.UploadOptions{})
+	require.ErrorIs(t, err, ephcli.ErrEmptyUpload)
+	_, err = client.UploadE2EFromURL(origin.URL+"/truncated.bin", ephcli.UploadOptions{})
+	require.ErrorIs(t, err, ephcli.ErrSourceTruncated)
+
+	// Without Content-Length, the body would have to be written to disk
+	chunked, _ := newSourceOrigin(t, make([]byte, 64*1024), true)
+	_, err = client.UploadE2EFromURL(chunked.URL+"/exports/report.bin", ephcli.UploadOptions{})
+	require.ErrorIs(t, err, ephcli.ErrSourceSizeUnknown)
+	files, err := client.Fetch()
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
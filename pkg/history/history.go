@@ -0,0 +1,262 @@
+// Package history records the uploads and downloads of eph in a local file,
+// one JSON entry per line, so that file IDs and transfer results can be looked
+// up after the fact.
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ephemeralfiles/eph/pkg/config"
+	"github.com/ephemeralfiles/eph/pkg/ephcli"
+	"github.com/ephemeralfiles/eph/pkg/units"
+)
+
+const (
+	// FileName is the name of the history file in the configuration directory.
+	FileName = "history.jsonl"
+	// ResultOK is the result of a successful transfer.
+	ResultOK = "ok"
+	// ResultError is the result of a failed transfer.
+	ResultError = "error"
+
+	// historyFilePerm is the permission of the history file.
+	historyFilePerm = 0600
+	// historyDirPerm is the permission of the directory of the history file.
+	historyDirPerm = 0700
+	// maxLineSize is the size of the longest entry read from the history file.
+	maxLineSize = 1024 * 1024
+)
+
+var (
+	// ErrInvalidSince is returned when a start time is neither a duration nor a date.
+	ErrInvalidSince = errors.New("invalid time, expected a duration (24h, 7d) or a date (2026-12-31)")
+	// ErrNoUpload is returned when the history holds no successful upload to look up.
+	ErrNoUpload = errors.New("no upload recorded in history")
+)
+
+// Entry is a recorded transfer.
+type Entry struct {
+	Time      time.Time `json:"time"`
+	Direction string    `json:"direction"`
+	// Profile is the name of the configuration used for the transfer.
+	Profile        string `json:"profile,omitempty"`
+	OrganizationID string `json:"organization_id,omitempty"`
+	Path           string `json:"path,omitempty"`
+	// Source is the URL of an upload from a URL.
+	Source     string `json:"source,omitempty"`
+	FileID     string `json:"file_id,omitempty"`
+	Size       int64  `json:"size"`
+	Checksum   string `json:"checksum,omitempty"`
+	Encryption string `json:"encryption,omitempty"`
+	// DurationMS is the duration of the transfer in milliseconds.
+	DurationMS int64  `json:"duration_ms"`
+	Result     string `json:"result"`
+	Error      string `json:"error,omitempty"`
+}
+
+// NewEntry returns the entry of a transfer made with the configuration profile.
+// The checksum is the one computed during the transfer, set for successful transfers.
+func NewEntry(t ephcli.Transfer, profile string) Entry {
+	entry := Entry{
+		Time:           t.Started,
+		Direction:      t.Direction,
+		Profile:        profile,
+		OrganizationID: t.OrganizationID,
+		Path:           t.Path,
+		Source:         t.Source,
+		FileID:         t.FileID,
+		Size:           t.Size,
+		Checksum:       t.Checksum,
+		Encryption:     t.Encryption,
+		DurationMS:     t.Duration.Milliseconds(),
+		Result:         ResultOK,
+	}
+	if t.Err != nil {
+		entry.Result = ResultError
+		entry.Error = t.Err.Error()
+		entry.Checksum = ""
+	}
+	return entry
+}
+
+// Duration returns the duration of the transfer.
+func (e Entry) Duration() time.Duration {
+	return time.Duration(e.DurationMS) * time.Millisecond
+}
+
+// Failed reports whether the transfer failed.
+func (e Entry) Failed() bool {
+	return e.Result != ResultOK
+}
+
+// DefaultPath returns the path of the history file in the configuration directory.
+func DefaultPath() string {
+	return filepath.Join(config.DefautConfigDir(), FileName)
+}
+
+// Store is a history file. Entries are appended, so that concurrent eph
+// processes never overwrite each other.
+type Store struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewStore returns the store of the history file at path.
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// Path returns the path of the history file.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Append adds an entry to the history file, creating it if needed. It is safe
+// for concurrent use.
+func (s *Store) Append(entry Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("error encoding history entry: %w", err)
+	}
+	data = append(data, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(s.path), historyDirPerm); err != nil {
+		return fmt.Errorf("error creating history directory: %w", err)
+	}
+	// #nosec G304 -- path is the history file of the configuration directory
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, historyFilePerm)
+	if err != nil {
+		return fmt.Errorf("error opening history: %w", err)
+	}
+	// A single write of a line is atomic with O_APPEND
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("error writing history: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("error writing history: %w", err)
+	}
+	return nil
+}
+
+// Entries returns the entries of the history file, oldest first. A missing
+// file is an empty history; unreadable lines are ignored.
+func (s *Store) Entries() ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// #nosec G304 -- path is the history file of the configuration directory
+	f, err := os.Open(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading history: %w", err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxLineSize)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// A line truncated by a crash
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading history: %w", err)
+	}
+	return entries, nil
+}
+
+// Clear removes the history file.
+func (s *Store) Clear() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.Remove(s.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("error removing history: %w", err)
+	}
+	return nil
+}
+
+// Filter selects entries of the history. Zero fields match every entry.
+type Filter struct {
+	// Direction is "upload" or "download".
+	Direction string
+	// Profile is the name of the configuration of the transfers.
+	Profile string
+	// Result is ResultOK or ResultError.
+	Result string
+	// Search is matched, case insensitively, against the path, source, file ID
+	// and error of the entries.
+	Search string
+	// Since drops the entries older than this time.
+	Since time.Time
+	// Limit keeps the most recent entries only.
+	Limit int
+}
+
+// Match reports whether entry is selected by the filter, ignoring Limit.
+func (f Filter) Match(entry Entry) bool {
+	switch {
+	case f.Direction != "" && entry.Direction != f.Direction,
+		f.Profile != "" && entry.Profile != f.Profile,
+		f.Result != "" && entry.Result != f.Result,
+		!f.Since.IsZero() && entry.Time.Before(f.Since):
+		return false
+	}
+	if f.Search == "" {
+		return true
+	}
+	search := strings.ToLower(f.Search)
+	for _, field := range []string{entry.Path, entry.Source, entry.FileID, entry.Error} {
+		if strings.Contains(strings.ToLower(field), search) {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply returns the entries selected by the filter, most recent first.
+func (f Filter) Apply(entries []Entry) []Entry {
+	var selected []Entry
+	for _, entry := range slices.Backward(entries) {
+		if !f.Match(entry) {
+			continue
+		}
+		selected = append(selected, entry)
+		if f.Limit > 0 && len(selected) == f.Limit {
+			break
+		}
+	}
+	return selected
+}
+
+// ParseSince parses the start of a period of the history: a duration before now
+// ("24h", "7d") or a date ("2026-12-31", meaning the start of that day in the
+// local time zone).
+func ParseSince(value string, now time.Time) (time.Time, error) {
+	if d, err := units.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	if day, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		return day, nil
+	}
+	return time.Time{}, fmt.Errorf("%w: %q", ErrInvalidSince, value)
+}
@@ -0,0 +1,172 @@
+package history_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ephemeralfiles/eph/pkg/ephcli"
+	"github.com/ephemeralfiles/eph/pkg/history"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEntry(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	started := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	entry := history.NewEntry(ephcli.Transfer{
+		Direction: ephcli.TransferUpload,
+		FileID:    "file-1",
+		Path:      path,
+		Size:      5,
+		Checksum:  "sha256:2a0e5e1f",
+		Started:   started,
+		Duration:  1500 * time.Millisecond,
+	}, "default")
+	assert.Equal(t, started, entry.Time)
+	assert.Equal(t, "default", entry.Profile)
+	assert.Equal(t, history.ResultOK, entry.Result)
+	assert.Equal(t, 1500*time.Millisecond, entry.Duration())
+	assert.Equal(t, "sha256:2a0e5e1f", entry.Checksum)
+	assert.False(t, entry.Failed())
+
+	failed := history.NewEntry(ephcli.Transfer{
+		Direction: ephcli.TransferDownload,
+		Path:      path,
+		Checksum:  "sha256:2a0e5e1f",
+		Err:       errors.New("file not found"),
+	}, "work")
+	assert.Equal(t, history.ResultError, failed.Result)
+	assert.Equal(t, "file not found", failed.Error)
+	assert.Empty(t, failed.Checksum)
+	assert.True(t, failed.Failed())
+}
+
+func TestStore(t *testing.T) {
+	t.Parallel()
+
+	store := history.NewStore(filepath.Join(t.TempDir(), "eph", history.FileName))
+
+	// A missing file is an empty history
+	entries, err := store.Entries()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, store.Append(history.Entry{Direction: ephcli.TransferUpload, Size: int64(i)}))
+		}()
+	}
+	wg.Wait()
+
+	// A truncated line is ignored
+	f, err := os.OpenFile(store.Path(), os.O_APPEND|os.O_WRONLY, 0600)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"direction":"up`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	entries, err = store.Entries()
+	require.NoError(t, err)
+	assert.Len(t, entries, 10)
+
+	info, err := os.Stat(store.Path())
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	require.NoError(t, store.Clear())
+	entries, err = store.Entries()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestFilter(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	entries := []history.Entry{
+		{Time: now.Add(-48 * time.Hour), Direction: "upload", Profile: "default", Path: "/tmp/Report.pdf",
+			FileID: "id-1", Result: history.ResultOK},
+		{Time: now.Add(-2 * time.Hour), Direction: "download", Profile: "default", Path: "/tmp/report.pdf",
+			FileID: "id-1", Result: history.ResultOK},
+		{Time: now.Add(-time.Hour), Direction: "upload", Profile: "work", Path: "/tmp/photo.jpg",
+			Result: history.ResultError, Error: "quota exceeded"},
+		{Time: now, Direction: "upload", Profile: "default", Source: "https://example.com/data.csv",
+			FileID: "id-3", Result: history.ResultOK},
+	}
+
+	paths := func(selected []history.Entry) []string {
+		var result []string
+		for _, e := range selected {
+			result = append(result, e.Path+e.Source)
+		}
+		return result
+	}
+
+	tests := []struct {
+		name     string
+		filter   history.Filter
+		expected []string
+	}{
+		{
+			name:     "all, most recent first",
+			expected: []string{"https://example.com/data.csv", "/tmp/photo.jpg", "/tmp/report.pdf", "/tmp/Report.pdf"},
+		},
+		{
+			name:     "direction and profile",
+			filter:   history.Filter{Direction: "upload", Profile: "default"},
+			expected: []string{"https://example.com/data.csv", "/tmp/Report.pdf"},
+		},
+		{
+			name:     "failed",
+			filter:   history.Filter{Result: history.ResultError},
+			expected: []string{"/tmp/photo.jpg"},
+		},
+		{
+			name:     "search is case insensitive",
+			filter:   history.Filter{Search: "REPORT"},
+			expected: []string{"/tmp/report.pdf", "/tmp/Report.pdf"},
+		},
+		{
+			name:     "search error",
+			filter:   history.Filter{Search: "quota"},
+			expected: []string{"/tmp/photo.jpg"},
+		},
+		{
+			name:     "since and limit",
+			filter:   history.Filter{Since: now.Add(-3 * time.Hour), Limit: 2},
+			expected: []string{"https://example.com/data.csv", "/tmp/photo.jpg"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.expected, paths(tt.filter.Apply(entries)))
+		})
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 5, 10, 12, 0, 0, 0, time.Local)
+	since, err := history.ParseSince("7d", now)
+	require.NoError(t, err)
+	assert.Equal(t, now.AddDate(0, 0, -7), since)
+
+	since, err = history.ParseSince("2026-05-01", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 5, 1, 0, 0, 0, 0, time.Local), since)
+
+	_, err = history.ParseSince("last week", now)
+	require.ErrorIs(t, err, history.ErrInvalidSince)
+}
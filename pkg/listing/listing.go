@@ -0,0 +1,135 @@
+// Package listing filters and sorts the files listed by the commands on the
+// client side, whatever their DTO: personal files and organization files go
+// through the same options.
+package listing
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Sort keys.
+const (
+	SortName    = "name"
+	SortSize    = "size"
+	SortExpires = "expires"
+)
+
+var (
+	// ErrInvalidSort is returned for an unknown sort key.
+	ErrInvalidSort = errors.New("invalid sort key, expected name, size or expires")
+	// ErrInvalidGlob is returned for a malformed name pattern.
+	ErrInvalidGlob = errors.New("invalid name pattern")
+)
+
+// File is the view of a listed file the options work on.
+type File struct {
+	Name      string
+	Size      int64
+	ExpiresAt time.Time
+}
+
+// Options filters and sorts files. The zero value keeps every file in the
+// order of the server.
+type Options struct {
+	// NameGlob keeps the files whose name matches this shell pattern, e.g. *.pdf.
+	NameGlob string
+	// LargerThan keeps the files larger than this number of bytes, when positive.
+	LargerThan int64
+	// ExpiresWithin keeps the files expiring within this duration from now,
+	// when positive.
+	ExpiresWithin time.Duration
+	// Sort is the key files are sorted by: SortName, SortSize or SortExpires.
+	Sort string
+	// Reverse reverses the order of the files.
+	Reverse bool
+}
+
+// IsZero reports whether the options keep every file in the order of the server.
+func (o Options) IsZero() bool {
+	return o == Options{}
+}
+
+// Validate checks the sort key and the name pattern.
+func (o Options) Validate() error {
+	switch o.Sort {
+	case "", SortName, SortSize, SortExpires:
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidSort, o.Sort)
+	}
+	if _, err := path.Match(o.NameGlob, ""); err != nil {
+		return fmt.Errorf("%w %q: %w", ErrInvalidGlob, o.NameGlob, err)
+	}
+	return nil
+}
+
+// Match reports whether f is kept by the filters of the options at time now.
+func (o Options) Match(f File, now time.Time) bool {
+	if o.NameGlob != "" {
+		if matched, _ := path.Match(o.NameGlob, f.Name); !matched {
+			return false
+		}
+	}
+	if o.LargerThan > 0 && f.Size <= o.LargerThan {
+		return false
+	}
+	if o.ExpiresWithin > 0 {
+		if f.ExpiresAt.IsZero() || f.ExpiresAt.Before(now) || f.ExpiresAt.After(now.Add(o.ExpiresWithin)) {
+			return false
+		}
+	}
+	return true
+}
+
+// Apply returns the items kept by the filters of the options at time now, in
+// the requested order; file returns the view of an item. Items comparing equal
+// keep the order of the server.
+func Apply[T any](items []T, file func(T) File, opts Options, now time.Time) []T {
+	selected := make([]T, 0, len(items))
+	for _, item := range items {
+		if opts.Match(file(item), now) {
+			selected = append(selected, item)
+		}
+	}
+
+	if compare := compareFunc(opts.Sort); compare != nil {
+		slices.SortStableFunc(selected, func(a, b T) int {
+			return compare(file(a), file(b))
+		})
+	}
+	if opts.Reverse {
+		slices.Reverse(selected)
+	}
+	return selected
+}
+
+// Page returns the items after the first offset ones, at most limit when
+// positive.
+func Page[T any](items []T, offset, limit int) []T {
+	items = items[min(max(offset, 0), len(items)):]
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
+	return items
+}
+
+// compareFunc returns the comparison of files of a sort key, nil for none.
+func compareFunc(key string) func(a, b File) int {
+	switch key {
+	case SortName:
+		return func(a, b File) int {
+			return cmp.Or(cmp.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name)), cmp.Compare(a.Name, b.Name))
+		}
+	case SortSize:
+		return func(a, b File) int { return cmp.Compare(a.Size, b.Size) }
+	case SortExpires:
+		return func(a, b File) int { return a.ExpiresAt.Compare(b.ExpiresAt) }
+	default:
+		return nil
+	}
+}
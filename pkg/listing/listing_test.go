@@ -0,0 +1,114 @@
+package listing_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ephemeralfiles/eph/pkg/listing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type file struct {
+	name    string
+	size    int64
+	expires time.Time
+}
+
+func TestApply(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	files := []file{
+		{name: "report.pdf", size: 3 << 30, expires: now.Add(48 * time.Hour)},
+		{name: "notes.md", size: 1600, expires: now.Add(2 * time.Hour)},
+		{name: "Archive.zip", size: 2 << 30, expires: now.Add(12 * time.Hour)},
+		{name: "invoice.pdf", size: 1600, expires: now.Add(-time.Hour)},
+	}
+	view := func(f file) listing.File {
+		return listing.File{Name: f.name, Size: f.size, ExpiresAt: f.expires}
+	}
+	names := func(selected []file) []string {
+		var result []string
+		for _, f := range selected {
+			result = append(result, f.name)
+		}
+		return result
+	}
+
+	tests := []struct {
+		name     string
+		opts     listing.Options
+		expected []string
+	}{
+		{
+			name:     "server order",
+			expected: []string{"report.pdf", "notes.md", "Archive.zip", "invoice.pdf"},
+		},
+		{
+			name:     "reverse",
+			opts:     listing.Options{Reverse: true},
+			expected: []string{"invoice.pdf", "Archive.zip", "notes.md", "report.pdf"},
+		},
+		{
+			name:     "sort by name ignores case",
+			opts:     listing.Options{Sort: listing.SortName},
+			expected: []string{"Archive.zip", "invoice.pdf", "notes.md", "report.pdf"},
+		},
+		{
+			name:     "sort by size is stable",
+			opts:     listing.Options{Sort: listing.SortSize},
+			expected: []string{"notes.md", "invoice.pdf", "Archive.zip", "report.pdf"},
+		},
+		{
+			name:     "sort by expiration, reversed",
+			opts:     listing.Options{Sort: listing.SortExpires, Reverse: true},
+			expected: []string{"report.pdf", "Archive.zip", "notes.md", "invoice.pdf"},
+		},
+		{
+			name:     "name glob",
+			opts:     listing.Options{NameGlob: "*.pdf"},
+			expected: []string{"report.pdf", "invoice.pdf"},
+		},
+		{
+			name:     "larger than",
+			opts:     listing.Options{LargerThan: 1 << 30, Sort: listing.SortSize},
+			expected: []string{"Archive.zip", "report.pdf"},
+		},
+		{
+			name:     "expires within excludes expired files",
+			opts:     listing.Options{ExpiresWithin: 24 * time.Hour},
+			expected: []string{"notes.md", "Archive.zip"},
+		},
+		{
+			name: "no match",
+			opts: listing.Options{NameGlob: "*.txt"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.expected, names(listing.Apply(files, view, tt.opts, now)))
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, listing.Options{Sort: listing.SortExpires, NameGlob: "*.pdf"}.Validate())
+	require.ErrorIs(t, listing.Options{Sort: "date"}.Validate(), listing.ErrInvalidSort)
+	require.ErrorIs(t, listing.Options{NameGlob: "[a-"}.Validate(), listing.ErrInvalidGlob)
+	assert.True(t, listing.Options{}.IsZero())
+	assert.False(t, listing.Options{Reverse: true}.IsZero())
+}
+
+func TestPage(t *testing.T) {
+	t.Parallel()
+
+	items := []int{1, 2, 3, 4, 5}
+	assert.Equal(t, []int{3, 4}, listing.Page(items, 2, 2))
+	assert.Equal(t, []int{4, 5}, listing.Page(items, 3, 10))
+	assert.Equal(t, items, listing.Page(items, 0, 0))
+	assert.Empty(t, listing.Page(items, 7, 2))
+}
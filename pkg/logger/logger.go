@@ -2,34 +2,52 @@
 package logger
 
 import (
+	"io"
 	"log/slog"
 	"os"
 )
 
+// Log formats of NewFormattedLogger.
+const (
+	// FormatText writes logs as key=value pairs.
+	FormatText = "text"
+	// FormatJSON writes logs as JSON objects, one per line.
+	FormatJSON = "json"
+)
+
 // NewLogger creates a new logger
 // logLevel is the level of logging
 // Possible values of logLevel are: "debug", "info", "warn", "error"
 // Default value is "info".
 func NewLogger(logLevel string) *slog.Logger {
-	var level slog.Level
+	return NewFormattedLogger(os.Stdout, logLevel, FormatText)
+}
+
+// NewFormattedLogger creates a logger writing to w in format, FormatText or
+// FormatJSON (FormatText by default). logLevel is as in NewLogger.
+func NewFormattedLogger(w io.Writer, logLevel string, format string) *slog.Logger {
+	opts := &slog.HandlerOptions{
+		Level:     parseLevel(logLevel),
+		AddSource: false,
+	}
+	if format == FormatJSON {
+		return slog.New(slog.NewJSONHandler(w, opts))
+	}
+	return slog.New(slog.NewTextHandler(w, opts))
+}
+
+// parseLevel returns the level named logLevel, or slog.LevelInfo.
+func parseLevel(logLevel string) slog.Level {
 	switch logLevel {
 	case "debug":
-		level = slog.LevelDebug
-	case "info":
-		level = slog.LevelInfo
+		return slog.LevelDebug
 	case "warn":
-		level = slog.LevelWarn
+		return slog.LevelWarn
 	case "error":
-		level = slog.LevelError
+		return slog.LevelError
 	default:
-		level = slog.LevelInfo
+		return slog.LevelInfo
 	}
-	logHandler := slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
-		Level:     level,
-		AddSource: false,
-	})
-	logger := slog.New(logHandler)
-	return logger
 }
 
 // NoLogger creates a logger that does not log anything.
@@ -1,12 +1,15 @@
 package logger_test
 
 import (
+	"bytes"
+	"encoding/json"
 	"log/slog"
 	"sync"
 	"testing"
 
 	"github.com/ephemeralfiles/eph/pkg/logger"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewLogger(t *testing.T) {
@@ -262,3 +265,21 @@ func TestLoggerEdgeCases(t *testing.T) {
 		}
 	})
 }
+
+func TestNewFormattedLogger(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	log := logger.NewFormattedLogger(&buf, "info", logger.FormatJSON)
+	log.Debug("hidden")
+	log.Info("file uploaded", slog.String("file", "scan.pdf"))
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "file uploaded", entry["msg"])
+	assert.Equal(t, "scan.pdf", entry["file"])
+
+	buf.Reset()
+	logger.NewFormattedLogger(&buf, "debug", logger.FormatText).Debug("shown", slog.Int("n", 1))
+	assert.Contains(t, buf.String(), "msg=shown n=1")
+}
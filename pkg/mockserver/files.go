@@ -0,0 +1,343 @@
+package mockserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ephemeralfiles/eph/pkg/dto"
+	"github.com/ephemeralfiles/eph/pkg/ephcli"
+)
+
+var (
+	errMissingFilePart   = errors.New("missing file part")
+	errInvalidExpiration = errors.New("invalid expiration date")
+)
+
+// handleListFiles returns the personal files of the caller.
+func (s *Server) handleListFiles(w http.ResponseWriter, _ *http.Request, u user) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	files := dto.FileList{}
+	for _, f := range s.state.Files {
+		if f.Complete && f.OrganizationID == "" && f.OwnerEmail == u.email {
+			files = append(files, f.toFile())
+		}
+	}
+	writeJSON(w, http.StatusOK, files)
+}
+
+// handleDeleteFile deletes a personal or organization file.
+func (s *Server) handleDeleteFile(w http.ResponseWriter, r *http.Request, _ user) {
+	fileID := r.PathValue("id")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	idx := slices.IndexFunc(s.state.Files, func(f *StoredFile) bool { return f.ID == fileID })
+	if idx < 0 {
+		writeError(w, http.StatusNotFound, "file not found")
+		return
+	}
+	s.state.Files = slices.Delete(s.state.Files, idx, idx+1)
+	s.state.Shares = slices.DeleteFunc(s.state.Shares, func(share *StoredShare) bool { return share.FileID == fileID })
+	delete(s.blobs, fileID)
+	if s.dataDir != "" {
+		_ = removeBlob(s.dataDir, fileID)
+	}
+	s.persistLocked()
+	writeJSON(w, http.StatusOK, map[string]string{"message": "file deleted"})
+}
+
+// handleFileSubresource dispatches GET /files/info/{id} and GET /files/{id}/download,
+// which cannot be registered as separate patterns because they overlap.
+func (s *Server) handleFileSubresource(w http.ResponseWriter, r *http.Request, _ user) {
+	first, second := r.PathValue("first"), r.PathValue("second")
+	switch {
+	case first == "info":
+		s.handleFileInfo(w, second)
+	case second == "download":
+		s.serveFileContent(w, r, first)
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+// handleFileInfo returns the information used by E2E downloads.
+func (s *Server) handleFileInfo(w http.ResponseWriter, fileID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f := s.findFileLocked(fileID)
+	if f == nil || !f.Complete {
+		writeError(w, http.StatusNotFound, "file not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, f.toInfoFile(s.blobs[f.ID]))
+}
+
+// handleUpdateTags replaces the tags of a file.
+func (s *Server) handleUpdateTags(w http.ResponseWriter, r *http.Request, _ user) {
+	var payload struct {
+		Tags []string `json:"tags"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f := s.findFileLocked(r.PathValue("id"))
+	if f == nil {
+		writeError(w, http.StatusNotFound, "file not found")
+		return
+	}
+	f.Tags = normalizeTags(payload.Tags)
+	s.persistLocked()
+	writeJSON(w, http.StatusOK, f.toOrganizationFile())
+}
+
+// handleUpdateExpiration changes the expiration date of a file.
+func (s *Server) handleUpdateExpiration(w http.ResponseWriter, r *http.Request, _ user) {
+	var payload struct {
+		ExpirationDate string `json:"expiration_date"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expiresAt, err := parseRequestedExpiration(payload.ExpirationDate, s.now())
+	if err != nil || expiresAt.IsZero() {
+		writeError(w, http.StatusBadRequest, errInvalidExpiration.Error())
+		return
+	}
+	f := s.findFileLocked(r.PathValue("id"))
+	if f == nil {
+		writeError(w, http.StatusNotFound, "file not found")
+		return
+	}
+	f.ExpirationDate = expiresAt
+	s.persistLocked()
+	writeJSON(w, http.StatusOK, f.toFile())
+}
+
+// handleRenameFile changes the name of a file.
+func (s *Server) handleRenameFile(w http.ResponseWriter, r *http.Request, _ user) {
+	var payload struct {
+		Filename string `json:"filename"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+	if payload.Filename == "" || strings.ContainsAny(payload.Filename, `/\`) {
+		writeError(w, http.StatusBadRequest, "invalid filename")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f := s.findFileLocked(r.PathValue("id"))
+	if f == nil {
+		writeError(w, http.StatusNotFound, "file not found")
+		return
+	}
+	f.Filename = payload.Filename
+	s.persistLocked()
+	writeJSON(w, http.StatusOK, f.toFile())
+}
+
+// handleBox returns the usage of the personal box of the caller.
+func (s *Server) handleBox(w http.ResponseWriter, _ *http.Request, u user) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var used int64
+	for _, f := range s.state.Files {
+		if f.OrganizationID == "" && f.OwnerEmail == u.email {
+			used += f.Size
+		}
+	}
+	usedMb := used / bytesInMB
+	writeJSON(w, http.StatusOK, ephcli.Box{
+		CapacityMb:  DefaultBoxCapacityMb,
+		UsedMb:      usedMb,
+		RemainingMb: DefaultBoxCapacityMb - usedMb,
+	})
+}
+
+// handleClearUpload stores a personal file uploaded without encryption.
+func (s *Server) handleClearUpload(w http.ResponseWriter, r *http.Request, u user) {
+	form, err := readUploadForm(r, "uploadfile")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expiresAt, err := parseRequestedExpiration(form.fields["expiration_date"], s.now())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	f := s.newFileLocked(u, form.filename, "", nil)
+	setExpiration(f, expiresAt)
+	s.completeClearFileLocked(f, form.content)
+	writeJSON(w, http.StatusOK, f.toFile())
+}
+
+// handleClearDownload serves a file without encryption.
+func (s *Server) handleClearDownload(w http.ResponseWriter, r *http.Request, _ user) {
+	s.serveFileContent(w, r, r.PathValue("id"))
+}
+
+// serveFileContent writes the content of a file, honouring Range requests.
+func (s *Server) serveFileContent(w http.ResponseWriter, r *http.Request, fileID string) {
+	s.mu.Lock()
+	f := s.findFileLocked(fileID)
+	var (
+		content  []byte
+		filename string
+	)
+	if f != nil && f.Complete {
+		content = s.blobs[f.ID]
+		filename = f.Filename
+	}
+	uploadDate := s.now()
+	if f != nil {
+		uploadDate = f.UploadDate
+	}
+	s.mu.Unlock()
+
+	if content == nil {
+		writeError(w, http.StatusNotFound, "file not found")
+		return
+	}
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	w.Header().Set("Content-Type", "application/octet-stream")
+	http.ServeContent(w, r, filename, uploadDate, bytes.NewReader(content))
+}
+
+// newFileLocked registers a new, incomplete file.
+// The caller must hold s.mu.
+func (s *Server) newFileLocked(u user, filename, orgID string, tags []string) *StoredFile {
+	now := s.now().UTC().Truncate(time.Second)
+	if filename != "" {
+		filename = filepath.Base(filename)
+	}
+	f := &StoredFile{
+		ID:             newID(),
+		Filename:       filename,
+		OwnerID:        u.id,
+		OwnerEmail:     u.email,
+		OrganizationID: orgID,
+		Tags:           normalizeTags(tags),
+		UploadDate:     now,
+		ExpirationDate: now.Add(s.retentionLocked(orgID)),
+	}
+	s.state.Files = append(s.state.Files, f)
+	return f
+}
+
+// parseRequestedExpiration parses the expiration date requested by a client,
+// returning the zero time when none is requested.
+func parseRequestedExpiration(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil || !t.After(now) {
+		return time.Time{}, fmt.Errorf("%w: %q", errInvalidExpiration, value)
+	}
+	return t.UTC().Truncate(time.Second), nil
+}
+
+// setExpiration replaces the default expiration date of f when expiresAt is set.
+func setExpiration(f *StoredFile, expiresAt time.Time) {
+	if !expiresAt.IsZero() {
+		f.ExpirationDate = expiresAt
+	}
+}
+
+// completeClearFileLocked stores the content of a file uploaded in a single request.
+// The caller must hold s.mu.
+func (s *Server) completeClearFileLocked(f *StoredFile, content []byte) {
+	f.Size = int64(len(content))
+	f.Parts = splitParts(f.Size, s.partSize)
+	f.Complete = true
+	s.storeBlobLocked(f.ID, content)
+	s.persistLocked()
+}
+
+// uploadForm is the content of a multipart upload.
+type uploadForm struct {
+	filename string
+	content  []byte
+	fields   map[string]string
+}
+
+// readUploadForm reads a multipart upload, returning the file part named fileField
+// and the other form fields.
+func readUploadForm(r *http.Request, fileField string) (*uploadForm, error) {
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, fmt.Errorf("invalid multipart form: %w", err)
+	}
+	form := &uploadForm{fields: make(map[string]string)}
+	found := false
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid multipart form: %w", err)
+		}
+		if part.FormName() == fileField {
+			form.filename = part.FileName()
+			form.content, err = io.ReadAll(part)
+			found = true
+		} else {
+			var value []byte
+			value, err = io.ReadAll(io.LimitReader(part, maxFormMemMB*bytesInMB))
+			form.fields[part.FormName()] = string(value)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading form: %w", err)
+		}
+	}
+	if !found {
+		return nil, errMissingFilePart
+	}
+	return form, nil
+}
+
+// normalizeTags trims and deduplicates tags, dropping empty ones.
+func normalizeTags(tags []string) []string {
+	var result []string
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag != "" && !slices.Contains(result, tag) {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
@@ -0,0 +1,113 @@
+package mockserver
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ephemeralfiles/eph/pkg/dto"
+)
+
+// Fixture identifiers, stable so that scripts and tests can reference them.
+const (
+	FixtureOrganizationID      = "7b3f6a52-1c7e-4b61-9a55-6f0d2c1e0a01"
+	FixtureOrganizationName    = "eph1"
+	FixtureOrganizationID2     = "7b3f6a52-1c7e-4b61-9a55-6f0d2c1e0a02"
+	FixtureOrganizationName2   = "eph2"
+	fixtureStorageLimitGB      = 10
+	fixtureStorageLimitGB2     = 5
+	fixtureRetentionDays       = 30
+	fixtureRetentionDays2      = 7
+	fixtureExpiredSinceHours   = 48
+	fixtureContentRepeatFactor = 64
+)
+
+// fixtureFile describes a seeded file.
+type fixtureFile struct {
+	id       string
+	filename string
+	orgID    string
+	tags     []string
+	expired  bool
+}
+
+// fixtureFiles are the files created by the seed.
+var fixtureFiles = []fixtureFile{
+	{id: "0c1d2e3f-0000-4000-8000-000000000001", filename: "welcome.txt"},
+	{id: "0c1d2e3f-0000-4000-8000-000000000002", filename: "notes.md"},
+	{
+		id: "0c1d2e3f-0000-4000-8000-000000000011", filename: "invoice-2026-01.pdf",
+		orgID: FixtureOrganizationID, tags: []string{"invoice", "q1"},
+	},
+	{
+		id: "0c1d2e3f-0000-4000-8000-000000000012", filename: "invoice-2026-02.pdf",
+		orgID: FixtureOrganizationID, tags: []string{"invoice", "q1"},
+	},
+	{
+		id: "0c1d2e3f-0000-4000-8000-000000000013", filename: "report.csv",
+		orgID: FixtureOrganizationID, tags: []string{"reports"},
+	},
+	{
+		id: "0c1d2e3f-0000-4000-8000-000000000014", filename: "old-export.zip",
+		orgID: FixtureOrganizationID, tags: []string{"archive"}, expired: true,
+	},
+	{
+		id: "0c1d2e3f-0000-4000-8000-000000000021", filename: "design.png",
+		orgID: FixtureOrganizationID2, tags: []string{"design"},
+	},
+}
+
+// seed fills an empty state with the fixtures.
+func (s *Server) seed() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now().UTC().Truncate(time.Second)
+	s.state.Organizations = []dto.Organization{
+		{
+			ID:                   FixtureOrganizationID,
+			Name:                 FixtureOrganizationName,
+			DefaultRetentionDays: fixtureRetentionDays,
+			CreatedAt:            now.Format(time.RFC3339),
+			UpdatedAt:            now.Format(time.RFC3339),
+			SubscriptionActive:   true,
+			UserRole:             "Admin",
+			StorageLimitGB:       fixtureStorageLimitGB,
+		},
+		{
+			ID:                   FixtureOrganizationID2,
+			Name:                 FixtureOrganizationName2,
+			DefaultRetentionDays: fixtureRetentionDays2,
+			CreatedAt:            now.Format(time.RFC3339),
+			UpdatedAt:            now.Format(time.RFC3339),
+			SubscriptionActive:   true,
+			UserRole:             "Member",
+			StorageLimitGB:       fixtureStorageLimitGB2,
+		},
+	}
+
+	owner := userFromToken("")
+	for i, fx := range fixtureFiles {
+		content := []byte(strings.Repeat(fmt.Sprintf("%s fixture content\n", fx.filename), fixtureContentRepeatFactor))
+		uploadDate := now.Add(-time.Duration(len(fixtureFiles)-i) * time.Minute)
+		expiration := uploadDate.Add(s.retentionLocked(fx.orgID))
+		if fx.expired {
+			expiration = now.Add(-fixtureExpiredSinceHours * time.Hour)
+		}
+		s.state.Files = append(s.state.Files, &StoredFile{
+			ID:             fx.id,
+			Filename:       fx.filename,
+			Size:           int64(len(content)),
+			OwnerID:        owner.id,
+			OwnerEmail:     owner.email,
+			OrganizationID: fx.orgID,
+			Tags:           fx.tags,
+			Parts:          splitParts(int64(len(content)), s.partSize),
+			Complete:       true,
+			UploadDate:     uploadDate,
+			ExpirationDate: expiration,
+		})
+		s.storeBlobLocked(fx.id, content)
+	}
+	s.persistLocked()
+}
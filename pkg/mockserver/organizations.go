@@ -0,0 +1,295 @@
+package mockserver
+
+import (
+	"cmp"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ephemeralfiles/eph/pkg/dto"
+)
+
+const (
+	defaultListLimit = 100
+	hoursInDay       = 24
+)
+
+// findOrganizationLocked returns the organization with the given ID.
+// The caller must hold s.mu.
+func (s *Server) findOrganizationLocked(orgID string) *dto.Organization {
+	for i := range s.state.Organizations {
+		if s.state.Organizations[i].ID == orgID {
+			return &s.state.Organizations[i]
+		}
+	}
+	return nil
+}
+
+// retentionLocked returns the retention applied to new files of an organization,
+// or the personal retention when orgID is empty.
+// The caller must hold s.mu.
+func (s *Server) retentionLocked(orgID string) time.Duration {
+	if org := s.findOrganizationLocked(orgID); org != nil && org.DefaultRetentionDays > 0 {
+		return time.Duration(org.DefaultRetentionDays) * hoursInDay * time.Hour
+	}
+	return DefaultRetention
+}
+
+// organizationFilesLocked returns the complete files of an organization, most recent first.
+// The caller must hold s.mu.
+func (s *Server) organizationFilesLocked(orgID string) []*StoredFile {
+	var files []*StoredFile
+	for _, f := range s.state.Files {
+		if f.Complete && f.OrganizationID == orgID {
+			files = append(files, f)
+		}
+	}
+	slices.SortStableFunc(files, func(a, b *StoredFile) int {
+		return b.UploadDate.Compare(a.UploadDate)
+	})
+	return files
+}
+
+// usedBytesLocked returns the storage used by an organization.
+// The caller must hold s.mu.
+func (s *Server) usedBytesLocked(orgID string) int64 {
+	var used int64
+	for _, f := range s.organizationFilesLocked(orgID) {
+		used += f.Size
+	}
+	return used
+}
+
+// organizationLocked returns a copy of the organization with its usage filled in.
+// The caller must hold s.mu.
+func (s *Server) organizationLocked(org *dto.Organization) dto.Organization {
+	result := *org
+	result.UsedStorageGB = float64(s.usedBytesLocked(org.ID)) / bytesInGB
+	return result
+}
+
+// organizationFromRequest resolves the organization of the request path,
+// writing a 404 when it does not exist. The caller must hold s.mu.
+func (s *Server) organizationFromRequest(w http.ResponseWriter, r *http.Request) *dto.Organization {
+	org := s.findOrganizationLocked(r.PathValue("id"))
+	if org == nil {
+		writeError(w, http.StatusNotFound, "organization not found")
+	}
+	return org
+}
+
+// queryInt returns an integer query parameter or its default value.
+func queryInt(r *http.Request, name string, def int) int {
+	value, err := strconv.Atoi(r.URL.Query().Get(name))
+	if err != nil || value < 0 {
+		return def
+	}
+	return value
+}
+
+// paginate converts files to DTOs, applying the limit and offset of the request.
+func paginate(r *http.Request, files []*StoredFile) []dto.OrganizationFile {
+	limit := queryInt(r, "limit", defaultListLimit)
+	offset := min(queryInt(r, "offset", 0), len(files))
+	end := min(offset+limit, len(files))
+
+	result := []dto.OrganizationFile{}
+	for _, f := range files[offset:end] {
+		result = append(result, f.toOrganizationFile())
+	}
+	return result
+}
+
+// handleListOrganizations returns every organization.
+func (s *Server) handleListOrganizations(w http.ResponseWriter, _ *http.Request, _ user) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	orgs := []dto.Organization{}
+	for i := range s.state.Organizations {
+		orgs = append(orgs, s.organizationLocked(&s.state.Organizations[i]))
+	}
+	writeJSON(w, http.StatusOK, orgs)
+}
+
+// handleGetOrganization returns one organization.
+func (s *Server) handleGetOrganization(w http.ResponseWriter, r *http.Request, _ user) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if org := s.organizationFromRequest(w, r); org != nil {
+		writeJSON(w, http.StatusOK, s.organizationLocked(org))
+	}
+}
+
+// handleOrganizationStorage returns the storage usage of an organization.
+func (s *Server) handleOrganizationStorage(w http.ResponseWriter, r *http.Request, _ user) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	org := s.organizationFromRequest(w, r)
+	if org == nil {
+		return
+	}
+	used := float64(s.usedBytesLocked(org.ID)) / bytesInGB
+	var usage float64
+	if org.StorageLimitGB > 0 {
+		usage = used / org.StorageLimitGB * percent
+	}
+	writeJSON(w, http.StatusOK, dto.OrganizationStorage{
+		ID:             org.ID,
+		Name:           org.Name,
+		StorageLimitGB: org.StorageLimitGB,
+		UsedStorageGB:  used,
+		UsagePercent:   usage,
+		IsFull:         org.StorageLimitGB > 0 && used >= org.StorageLimitGB,
+	})
+}
+
+// handleOrganizationStats returns the statistics of an organization.
+func (s *Server) handleOrganizationStats(w http.ResponseWriter, r *http.Request, _ user) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	org := s.organizationFromRequest(w, r)
+	if org == nil {
+		return
+	}
+	stats := dto.OrganizationStats{OrganizationID: org.ID, MemberCount: 1}
+	members := map[string]bool{}
+	now := s.now()
+	for _, f := range s.organizationFilesLocked(org.ID) {
+		stats.FileCount++
+		stats.TotalSizeGB += float64(f.Size) / bytesInGB
+		members[f.OwnerID] = true
+		if f.isExpired(now) {
+			stats.ExpiredFiles++
+		} else {
+			stats.ActiveFiles++
+		}
+	}
+	stats.MemberCount = max(stats.MemberCount, int64(len(members)))
+	writeJSON(w, http.StatusOK, stats)
+}
+
+// handleOrganizationTags returns the most used tags of an organization.
+func (s *Server) handleOrganizationTags(w http.ResponseWriter, r *http.Request, _ user) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	org := s.organizationFromRequest(w, r)
+	if org == nil {
+		return
+	}
+	counts := map[string]int64{}
+	for _, f := range s.organizationFilesLocked(org.ID) {
+		for _, tag := range f.Tags {
+			counts[tag]++
+		}
+	}
+	tags := []dto.TagCount{}
+	for tag, count := range counts {
+		tags = append(tags, dto.TagCount{Tag: tag, Count: count})
+	}
+	slices.SortFunc(tags, func(a, b dto.TagCount) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), cmp.Compare(a.Tag, b.Tag))
+	})
+	tags = tags[:min(len(tags), queryInt(r, "limit", defaultListLimit))]
+	writeJSON(w, http.StatusOK, tags)
+}
+
+// handleOrganizationFiles lists the files of an organization.
+func (s *Server) handleOrganizationFiles(w http.ResponseWriter, r *http.Request, _ user) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if org := s.organizationFromRequest(w, r); org != nil {
+		writeJSON(w, http.StatusOK, paginate(r, s.organizationFilesLocked(org.ID)))
+	}
+}
+
+// handleOrganizationFilesByTags lists the files of an organization carrying all the given tags.
+func (s *Server) handleOrganizationFilesByTags(w http.ResponseWriter, r *http.Request, _ user) {
+	tags := normalizeTags(strings.Split(r.URL.Query().Get("tags"), ","))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	org := s.organizationFromRequest(w, r)
+	if org == nil {
+		return
+	}
+	var files []*StoredFile
+	for _, f := range s.organizationFilesLocked(org.ID) {
+		if f.hasTags(tags) {
+			files = append(files, f)
+		}
+	}
+	writeJSON(w, http.StatusOK, paginate(r, files))
+}
+
+// handleOrganizationRecentFiles lists the most recent active files of an organization.
+func (s *Server) handleOrganizationRecentFiles(w http.ResponseWriter, r *http.Request, _ user) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	org := s.organizationFromRequest(w, r)
+	if org == nil {
+		return
+	}
+	now := s.now()
+	var files []*StoredFile
+	for _, f := range s.organizationFilesLocked(org.ID) {
+		if !f.isExpired(now) {
+			files = append(files, f)
+		}
+	}
+	writeJSON(w, http.StatusOK, paginate(r, files))
+}
+
+// handleOrganizationExpiredFiles lists the expired files of an organization.
+func (s *Server) handleOrganizationExpiredFiles(w http.ResponseWriter, r *http.Request, _ user) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	org := s.organizationFromRequest(w, r)
+	if org == nil {
+		return
+	}
+	now := s.now()
+	var files []*StoredFile
+	for _, f := range s.organizationFilesLocked(org.ID) {
+		if f.isExpired(now) {
+			files = append(files, f)
+		}
+	}
+	writeJSON(w, http.StatusOK, paginate(r, files))
+}
+
+// handleOrganizationUpload stores an organization file uploaded without encryption.
+func (s *Server) handleOrganizationUpload(w http.ResponseWriter, r *http.Request, u user) {
+	form, err := readUploadForm(r, "file")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	org := s.organizationFromRequest(w, r)
+	if org == nil {
+		return
+	}
+	expiresAt, err := parseRequestedExpiration(form.fields["expiration_date"], s.now())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	f := s.newFileLocked(u, form.filename, org.ID, strings.Split(form.fields["tags"], ","))
+	setExpiration(f, expiresAt)
+	s.completeClearFileLocked(f, form.content)
+	writeJSON(w, http.StatusCreated, f.toOrganizationFile())
+}
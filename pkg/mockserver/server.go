@@ -0,0 +1,281 @@
+// Package mockserver provides an in-memory implementation of the ephemeralfiles API
+// surface used by the eph client. It is a development and test stand-in for the real
+// service: it performs the same E2E key exchange, stores file contents in memory and
+// can optionally persist its state to a directory.
+package mockserver
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ephemeralfiles/eph/pkg/ephcli"
+	"github.com/ephemeralfiles/eph/pkg/logger"
+)
+
+const (
+	// DefaultPartSize is the size of the parts served for files uploaded in clear.
+	DefaultPartSize = 128 * 1024 * 1024
+	// DefaultRetention is the retention applied to personal files.
+	DefaultRetention = 7 * 24 * time.Hour
+	// DefaultBoxCapacityMb is the capacity of the personal box.
+	DefaultBoxCapacityMb = 5120
+	// DefaultEmail is the user email used when the token does not carry one.
+	DefaultEmail = "dev@localhost"
+
+	rsaKeySize   = 2048
+	bytesInMB    = 1024 * 1024
+	bytesInGB    = 1024 * 1024 * 1024
+	percent      = 100
+	uuidSize     = 16
+	maxFormMemMB = 32
+)
+
+// Options configures the mock server.
+type Options struct {
+	// DataDir is the directory where the state is persisted. Empty means in memory only.
+	DataDir string
+	// Seed loads the fixtures when the server starts with an empty state.
+	Seed bool
+	// Logger receives the request logs. Defaults to no logging.
+	Logger *slog.Logger
+	// PartSize is the size of the E2E parts served for files uploaded in clear.
+	// Defaults to DefaultPartSize.
+	PartSize int64
+	// MaxChunkSize is advertised in the X-Max-Chunk-Size header when an E2E upload
+	// starts; larger chunks are rejected. Zero means no limit.
+	MaxChunkSize int64
+}
+
+// transaction is an E2E upload or download in progress.
+type transaction struct {
+	fileID string
+	aesKey []byte
+	// shared is set for downloads started from a share link, which do not
+	// require authentication.
+	shared bool
+}
+
+// Server is an in-memory ephemeralfiles API.
+type Server struct {
+	mu        sync.Mutex
+	state     *State
+	blobs     map[string][]byte
+	uploads   map[string]*transaction
+	downloads map[string]*transaction
+	key       *rsa.PrivateKey
+	publicKey string
+	dataDir   string
+	partSize  int64
+	maxChunk  int64
+	log       *slog.Logger
+	mux       *http.ServeMux
+	now       func() time.Time
+}
+
+// New creates a mock server, loading the persisted state from opts.DataDir if any.
+func New(opts Options) (*Server, error) {
+	key, err := rsa.GenerateKey(rand.Reader, rsaKeySize)
+	if err != nil {
+		return nil, fmt.Errorf("error generating RSA key: %w", err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling public key: %w", err)
+	}
+
+	s := &Server{
+		state:     &State{},
+		blobs:     make(map[string][]byte),
+		uploads:   make(map[string]*transaction),
+		downloads: make(map[string]*transaction),
+		key:       key,
+		publicKey: base64.StdEncoding.EncodeToString(der),
+		dataDir:   opts.DataDir,
+		partSize:  opts.PartSize,
+		maxChunk:  opts.MaxChunkSize,
+		log:       opts.Logger,
+		now:       time.Now,
+	}
+	if s.log == nil {
+		s.log = logger.NoLogger()
+	}
+	if s.partSize <= 0 {
+		s.partSize = DefaultPartSize
+	}
+
+	if s.dataDir != "" {
+		s.state, s.blobs, err = loadState(s.dataDir)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if opts.Seed && len(s.state.Organizations) == 0 && len(s.state.Files) == 0 {
+		s.seed()
+	}
+
+	s.routes()
+	return s, nil
+}
+
+// Handler returns the HTTP handler serving the API.
+func (s *Server) Handler() http.Handler {
+	return s.mux
+}
+
+// routes registers the API endpoints.
+func (s *Server) routes() {
+	s.mux = http.NewServeMux()
+	api := "/api/v1"
+
+	// Personal files
+	s.mux.HandleFunc("GET "+api+"/files", s.auth(s.handleListFiles))
+	s.mux.HandleFunc("DELETE "+api+"/files/{id}", s.auth(s.handleDeleteFile))
+	s.mux.HandleFunc("GET "+api+"/files/{first}/{second}", s.auth(s.handleFileSubresource))
+	s.mux.HandleFunc("PUT "+api+"/files/{id}/tags", s.auth(s.handleUpdateTags))
+	s.mux.HandleFunc("PUT "+api+"/files/{id}/expiration", s.auth(s.handleUpdateExpiration))
+	s.mux.HandleFunc("PUT "+api+"/files/{id}/name", s.auth(s.handleRenameFile))
+	s.mux.HandleFunc("POST "+api+"/files/{id}/shares", s.auth(s.handleCreateShare))
+	s.mux.HandleFunc("GET "+api+"/box/{email}/default", s.auth(s.handleBox))
+
+	// Share links
+	s.mux.HandleFunc("GET "+api+"/shares", s.auth(s.handleListShares))
+	s.mux.HandleFunc("DELETE "+api+"/shares/{id}", s.auth(s.handleRevokeShare))
+	s.mux.HandleFunc("GET "+api+"/shares/public/{token}", s.handleSharedFile)
+	s.mux.HandleFunc("POST "+api+"/shares/public/{token}/download", s.handleSharedDownloadInit)
+
+	// Clear transfers
+	s.mux.HandleFunc("POST "+api+"/upload/clear", s.auth(s.handleClearUpload))
+	s.mux.HandleFunc("GET "+api+"/download/clear/{id}", s.auth(s.handleClearDownload))
+
+	// E2E transfers
+	s.mux.HandleFunc("POST "+api+"/upload/encrypted/init", s.auth(s.handleUploadInit))
+	s.mux.HandleFunc("POST "+api+"/upload/encrypted/{tx}/key", s.auth(s.handleUploadKey))
+	s.mux.HandleFunc("POST "+api+"/upload/encrypted/{tx}/chunks", s.auth(s.handleUploadChunk))
+	s.mux.HandleFunc("POST "+api+"/download/encrypted/{id}/init", s.auth(s.handleDownloadInit))
+	s.mux.HandleFunc("POST "+api+"/download/encrypted/{tx}/key", s.authOrSharedDownload(s.handleDownloadKey))
+	s.mux.HandleFunc("GET "+api+"/download/encrypted/{tx}/chunks/{part}", s.authOrSharedDownload(s.handleDownloadChunk))
+
+	// Organizations
+	s.mux.HandleFunc("GET "+api+"/organizations", s.auth(s.handleListOrganizations))
+	s.mux.HandleFunc("GET "+api+"/organizations/{id}", s.auth(s.handleGetOrganization))
+	s.mux.HandleFunc("GET "+api+"/organizations/{id}/storage", s.auth(s.handleOrganizationStorage))
+	s.mux.HandleFunc("GET "+api+"/organizations/{id}/stats", s.auth(s.handleOrganizationStats))
+	s.mux.HandleFunc("GET "+api+"/organizations/{id}/tags", s.auth(s.handleOrganizationTags))
+	s.mux.HandleFunc("GET "+api+"/organizations/{id}/files", s.auth(s.handleOrganizationFiles))
+	s.mux.HandleFunc("GET "+api+"/organizations/{id}/files/tags", s.auth(s.handleOrganizationFilesByTags))
+	s.mux.HandleFunc("GET "+api+"/organizations/{id}/files/recent", s.auth(s.handleOrganizationRecentFiles))
+	s.mux.HandleFunc("GET "+api+"/organizations/{id}/files/expired", s.auth(s.handleOrganizationExpiredFiles))
+	s.mux.HandleFunc("POST "+api+"/organizations/{id}/files/upload", s.auth(s.handleOrganizationUpload))
+	s.mux.HandleFunc("GET "+api+"/organizations/{id}/shares", s.auth(s.handleOrganizationShares))
+}
+
+// user is the identity of the caller.
+type user struct {
+	id    string
+	email string
+}
+
+// userHandler is an HTTP handler receiving the authenticated user.
+type userHandler func(w http.ResponseWriter, r *http.Request, u user)
+
+// auth rejects requests without a bearer token and resolves the caller identity.
+// Any token is accepted; the email is read from the token payload when present.
+func (s *Server) auth(next userHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.log.Debug("request", slog.String("method", r.Method), slog.String("path", r.URL.Path))
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			writeError(w, http.StatusUnauthorized, "missing or invalid token")
+			return
+		}
+		next(w, r, userFromToken(token))
+	}
+}
+
+// userFromToken builds the caller identity from the token.
+func userFromToken(token string) user {
+	email, _, err := ephcli.Whoami(token)
+	if err != nil || email == "" {
+		email = DefaultEmail
+	}
+	return user{id: "user-" + email, email: email}
+}
+
+// writeJSON writes a JSON response with the given status.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an error in the format returned by the API.
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg, "message": msg})
+}
+
+// newID returns a random UUID (version 4).
+func newID() string {
+	b := make([]byte, uuidSize)
+	_, _ = rand.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40 //nolint:mnd // UUID version 4
+	b[8] = (b[8] & 0x3f) | 0x80 //nolint:mnd // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// persistLocked saves the state if a data directory is configured.
+// The caller must hold s.mu.
+func (s *Server) persistLocked() {
+	if s.dataDir == "" {
+		return
+	}
+	if err := saveState(s.dataDir, s.state); err != nil {
+		s.log.Error("error saving state", slog.String("error", err.Error()))
+	}
+}
+
+// storeBlobLocked keeps the content of a completed file and persists it.
+// The caller must hold s.mu.
+func (s *Server) storeBlobLocked(fileID string, content []byte) {
+	s.blobs[fileID] = content
+	if s.dataDir == "" {
+		return
+	}
+	if err := saveBlob(s.dataDir, fileID, content); err != nil {
+		s.log.Error("error saving blob", slog.String("error", err.Error()))
+	}
+}
+
+// findFileLocked returns the file with the given ID.
+// The caller must hold s.mu.
+func (s *Server) findFileLocked(fileID string) *StoredFile {
+	for _, f := range s.state.Files {
+		if f.ID == fileID {
+			return f
+		}
+	}
+	return nil
+}
+
+// Save persists the whole state, blobs included, to the data directory.
+func (s *Server) Save() error {
+	if s.dataDir == "" {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, content := range s.blobs {
+		if err := saveBlob(s.dataDir, id, content); err != nil {
+			return err
+		}
+	}
+	return saveState(s.dataDir, s.state)
+}
@@ -0,0 +1,185 @@
+package mockserver_test
+
+import (
+	"crypto/rand"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ephemeralfiles/eph/pkg/ephcli"
+	"github.com/ephemeralfiles/eph/pkg/mockserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestServer starts a mock server and returns its URL.
+func newTestServer(t *testing.T, opts mockserver.Options) string {
+	t.Helper()
+	srv, err := mockserver.New(opts)
+	require.NoError(t, err)
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+	return ts.URL
+}
+
+// newTestClient starts a mock server and returns a client configured for it.
+func newTestClient(t *testing.T, opts mockserver.Options) *ephcli.ClientEphemeralfiles {
+	t.Helper()
+	client := ephcli.NewClient(mockserver.DevToken(mockserver.DefaultEmail, time.Hour))
+	client.SetEndpoint(newTestServer(t, opts))
+	client.DisableProgressBar()
+	return client
+}
+
+// writeRandomFile creates a file of the given size with random content.
+func writeRandomFile(t *testing.T, path string, size int) []byte {
+	t.Helper()
+	content := make([]byte, size)
+	_, err := rand.Read(content)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, content, 0600))
+	return content
+}
+
+func TestPersonalFilesRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	client := newTestClient(t, mockserver.Options{})
+	dir := t.TempDir()
+	src := filepath.Join(dir, "source.bin")
+	content := writeRandomFile(t, src, 3*1024*1024)
+
+	t.Run("E2E upload and download", func(t *testing.T) {
+		_, err := client.UploadE2E(src)
+		require.NoError(t, err)
+
+		files, err := client.Fetch()
+		require.NoError(t, err)
+		require.Len(t, files, 1)
+		assert.Equal(t, "source.bin", files[0].FileName)
+		assert.Equal(t, int64(len(content)), files[0].Size)
+
+		out := filepath.Join(dir, "e2e.bin")
+		require.NoError(t, client.DownloadE2E(files[0].FileID, out))
+		downloaded, err := os.ReadFile(out)
+		require.NoError(t, err)
+		assert.Equal(t, content, downloaded)
+
+		out = filepath.Join(dir, "clear.bin")
+		require.NoError(t, client.Download(files[0].FileID, out))
+		downloaded, err = os.ReadFile(out)
+		require.NoError(t, err)
+		assert.Equal(t, content, downloaded)
+	})
+
+	t.Run("clear upload and remove", func(t *testing.T) {
+		_, err := client.Upload(src)
+		require.NoError(t, err)
+		files, err := client.Fetch()
+		require.NoError(t, err)
+		require.Len(t, files, 2)
+
+		for _, f := range files {
+			require.NoError(t, client.Remove(f.FileID))
+		}
+		files, err = client.Fetch()
+		require.NoError(t, err)
+		assert.Empty(t, files)
+	})
+
+	t.Run("box usage", func(t *testing.T) {
+		box, err := client.GetBoxInfos()
+		require.NoError(t, err)
+		assert.Equal(t, int64(mockserver.DefaultBoxCapacityMb), box.CapacityMb)
+	})
+}
+
+func TestOrganizationFiles(t *testing.T) {
+	t.Parallel()
+
+	client := newTestClient(t, mockserver.Options{Seed: true})
+
+	org, err := client.GetOrganizationByName(mockserver.FixtureOrganizationName)
+	require.NoError(t, err)
+	assert.Equal(t, mockserver.FixtureOrganizationID, org.ID)
+
+	invoices, err := client.GetOrganizationFilesByTags(org.ID, []string{"invoice", "q1"}, 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, invoices, 2)
+
+	expired, err := client.ListExpiredOrganizationFiles(org.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, expired, 1)
+	assert.Equal(t, "old-export.zip", expired[0].Filename)
+
+	src := filepath.Join(t.TempDir(), "scan.pdf")
+	content := writeRandomFile(t, src, 4096)
+	fileID, err := client.UploadOrganizationFileE2E(org.ID, src, []string{"scan"})
+	require.NoError(t, err)
+
+	updated, err := client.UpdateFileTags(fileID, []string{"scan", "done"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"scan", "done"}, updated.Tags)
+
+	tags, err := client.GetPopularTags(org.ID, 1)
+	require.NoError(t, err)
+	require.Len(t, tags, 1)
+	assert.Equal(t, "invoice", tags[0].Tag)
+
+	out := filepath.Join(t.TempDir(), "scan-downloaded.pdf")
+	require.NoError(t, client.DownloadOrganizationFile(fileID, out))
+	downloaded, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Equal(t, content, downloaded)
+
+	stats, err := client.GetOrganizationStats(org.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), stats.FileCount)
+	assert.Equal(t, int64(1), stats.ExpiredFiles)
+
+	require.NoError(t, client.DeleteOrganizationFile(fileID))
+	files, err := client.ListOrganizationFiles(org.ID, 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, files, 4)
+}
+
+func TestPersistence(t *testing.T) {
+	t.Parallel()
+
+	dataDir := t.TempDir()
+	client := newTestClient(t, mockserver.Options{DataDir: dataDir})
+	src := filepath.Join(t.TempDir(), "persisted.txt")
+	content := writeRandomFile(t, src, 1024)
+	_, err := client.UploadE2E(src)
+	require.NoError(t, err)
+
+	// A new server on the same directory sees the file
+	client = newTestClient(t, mockserver.Options{DataDir: dataDir, Seed: true})
+	files, err := client.Fetch()
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	out := filepath.Join(t.TempDir(), "out.txt")
+	require.NoError(t, client.DownloadE2E(files[0].FileID, out))
+	downloaded, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Equal(t, content, downloaded)
+
+	// Seed is ignored when the state is not empty
+	orgs, err := client.ListOrganizations()
+	require.NoError(t, err)
+	assert.Empty(t, orgs)
+}
+
+func TestAuthentication(t *testing.T) {
+	t.Parallel()
+
+	anonymous := ephcli.NewClient("")
+	anonymous.SetEndpoint(newTestServer(t, mockserver.Options{}))
+
+	_, err := anonymous.Fetch()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "401")
+}
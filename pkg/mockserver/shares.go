@@ -0,0 +1,234 @@
+package mockserver
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ephemeralfiles/eph/pkg/dto"
+)
+
+// handleCreateShare creates a share link to a file.
+func (s *Server) handleCreateShare(w http.ResponseWriter, r *http.Request, u user) {
+	var payload struct {
+		Password       string `json:"password"`
+		MaxDownloads   int    `json:"max_downloads"`
+		ExpirationDate string `json:"expiration_date"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || payload.MaxDownloads < 0 {
+		writeError(w, http.StatusBadRequest, "invalid payload")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expiresAt, err := parseRequestedExpiration(payload.ExpirationDate, s.now())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	f := s.findFileLocked(r.PathValue("id"))
+	if f == nil || !f.Complete {
+		writeError(w, http.StatusNotFound, "file not found")
+		return
+	}
+	if f.isExpired(s.now()) {
+		writeError(w, http.StatusGone, "file expired")
+		return
+	}
+	// A link never outlives its file
+	if expiresAt.IsZero() || expiresAt.After(f.ExpirationDate) {
+		expiresAt = f.ExpirationDate
+	}
+
+	share := &StoredShare{
+		ID:             newID(),
+		Token:          strings.ReplaceAll(newID(), "-", ""),
+		FileID:         f.ID,
+		OwnerEmail:     u.email,
+		MaxDownloads:   payload.MaxDownloads,
+		CreatedAt:      s.now().UTC().Truncate(time.Second),
+		ExpirationDate: expiresAt,
+	}
+	if payload.Password != "" {
+		share.PasswordHash = hashPassword(payload.Password)
+	}
+	s.state.Shares = append(s.state.Shares, share)
+	s.persistLocked()
+	writeJSON(w, http.StatusOK, s.shareLinkLocked(r, share))
+}
+
+// handleListShares returns the share links created by the caller.
+func (s *Server) handleListShares(w http.ResponseWriter, r *http.Request, u user) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	links := []dto.ShareLink{}
+	for _, share := range s.state.Shares {
+		if share.OwnerEmail == u.email {
+			links = append(links, s.shareLinkLocked(r, share))
+		}
+	}
+	writeJSON(w, http.StatusOK, links)
+}
+
+// handleOrganizationShares returns the share links to the files of an organization.
+func (s *Server) handleOrganizationShares(w http.ResponseWriter, r *http.Request, _ user) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	org := s.organizationFromRequest(w, r)
+	if org == nil {
+		return
+	}
+	links := []dto.ShareLink{}
+	for _, share := range s.state.Shares {
+		if f := s.findFileLocked(share.FileID); f != nil && f.OrganizationID == org.ID {
+			links = append(links, s.shareLinkLocked(r, share))
+		}
+	}
+	writeJSON(w, http.StatusOK, links)
+}
+
+// handleRevokeShare deletes a share link.
+func (s *Server) handleRevokeShare(w http.ResponseWriter, r *http.Request, _ user) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	shareID := r.PathValue("id")
+	idx := slices.IndexFunc(s.state.Shares, func(share *StoredShare) bool { return share.ID == shareID })
+	if idx < 0 {
+		writeError(w, http.StatusNotFound, "share not found")
+		return
+	}
+	s.state.Shares = slices.Delete(s.state.Shares, idx, idx+1)
+	s.persistLocked()
+	writeJSON(w, http.StatusOK, map[string]string{"message": "share revoked"})
+}
+
+// handleSharedFile returns the public information of a shared file.
+func (s *Server) handleSharedFile(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	share, f := s.findUsableShareLocked(w, r.PathValue("token"))
+	if share == nil {
+		return
+	}
+	writeJSON(w, http.StatusOK, dto.SharedFile{
+		Filename:         f.Filename,
+		Size:             f.Size,
+		NbParts:          len(f.Parts),
+		PasswordRequired: share.PasswordHash != "",
+		ExpirationDate:   share.ExpirationDate,
+	})
+}
+
+// handleSharedDownloadInit starts an E2E download of a shared file, checking
+// the password of the link and counting the download.
+func (s *Server) handleSharedDownloadInit(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	share, f := s.findUsableShareLocked(w, r.PathValue("token"))
+	if share == nil {
+		return
+	}
+	if share.PasswordHash != "" && hashPassword(r.Header.Get("X-Share-Password")) != share.PasswordHash {
+		writeError(w, http.StatusUnauthorized, "invalid password")
+		return
+	}
+	share.DownloadCount++
+	s.persistLocked()
+
+	transactionID := newID()
+	s.downloads[transactionID] = &transaction{fileID: f.ID, shared: true}
+	w.Header().Set("X-Transaction-Id", transactionID)
+	w.Header().Set("X-File-Public-Key", s.publicKey)
+	w.WriteHeader(http.StatusOK)
+}
+
+// findUsableShareLocked returns the share link with the given token and its
+// file, or writes an error and returns nil if the link cannot be used.
+// The caller must hold s.mu.
+func (s *Server) findUsableShareLocked(w http.ResponseWriter, token string) (*StoredShare, *StoredFile) {
+	idx := slices.IndexFunc(s.state.Shares, func(share *StoredShare) bool { return share.Token == token })
+	if idx < 0 {
+		writeError(w, http.StatusNotFound, "share not found")
+		return nil, nil
+	}
+	share := s.state.Shares[idx]
+	f := s.findFileLocked(share.FileID)
+	switch {
+	case f == nil || !f.Complete:
+		writeError(w, http.StatusNotFound, "file not found")
+	case share.ExpirationDate.Before(s.now()) || f.isExpired(s.now()):
+		writeError(w, http.StatusGone, "share link expired")
+	case share.MaxDownloads > 0 && share.DownloadCount >= share.MaxDownloads:
+		writeError(w, http.StatusGone, "download limit reached")
+	default:
+		return share, f
+	}
+	return nil, nil
+}
+
+// authOrSharedDownload is like auth, but also accepts requests without a token
+// for the download transactions started from a share link.
+func (s *Server) authOrSharedDownload(next userHandler) http.HandlerFunc {
+	authenticated := s.auth(next)
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			s.mu.Lock()
+			tx, ok := s.downloads[r.PathValue("tx")]
+			shared := ok && tx.shared
+			s.mu.Unlock()
+			if shared {
+				next(w, r, user{})
+				return
+			}
+		}
+		authenticated(w, r)
+	}
+}
+
+// shareLinkLocked converts a stored share to the share link DTO, with a URL on
+// the host the request was sent to.
+// The caller must hold s.mu.
+func (s *Server) shareLinkLocked(r *http.Request, share *StoredShare) dto.ShareLink {
+	link := dto.ShareLink{
+		ID:               share.ID,
+		FileID:           share.FileID,
+		URL:              shareURL(r, share.Token),
+		PasswordRequired: share.PasswordHash != "",
+		MaxDownloads:     share.MaxDownloads,
+		DownloadCount:    share.DownloadCount,
+		CreatedAt:        share.CreatedAt,
+		ExpirationDate:   share.ExpirationDate,
+	}
+	if f := s.findFileLocked(share.FileID); f != nil {
+		link.Filename = f.Filename
+		link.OrganizationID = f.OrganizationID
+	}
+	return link
+}
+
+// shareURL returns the public URL of a share link.
+func shareURL(r *http.Request, token string) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s/s/%s", scheme, r.Host, token)
+}
+
+// hashPassword hashes the password of a share link.
+func hashPassword(password string) string {
+	sum := sha256.Sum256([]byte(password))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,212 @@
+package mockserver
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/ephemeralfiles/eph/pkg/dto"
+)
+
+const (
+	stateFileName = "state.json"
+	blobsDirName  = "blobs"
+	dataDirPerm   = 0700
+	dataFilePerm  = 0600
+)
+
+// State is the persistent part of the mock server: organizations and file metadata.
+// File contents are stored separately (in memory and, optionally, as blobs on disk).
+type State struct {
+	Organizations []dto.Organization `json:"organizations"`
+	Files         []*StoredFile      `json:"files"`
+	Shares        []*StoredShare     `json:"shares,omitempty"`
+}
+
+// StoredFile is the server-side representation of an uploaded file.
+type StoredFile struct {
+	ID             string    `json:"id"`
+	Filename       string    `json:"filename"`
+	Size           int64     `json:"size"`
+	OwnerID        string    `json:"owner_id"`
+	OwnerEmail     string    `json:"owner_email"`
+	OrganizationID string    `json:"organization_id,omitempty"`
+	Tags           []string  `json:"tags,omitempty"`
+	Parts          []int64   `json:"parts"`
+	Encrypted      bool      `json:"encrypted"`
+	Complete       bool      `json:"complete"`
+	UploadDate     time.Time `json:"upload_date"`
+	ExpirationDate time.Time `json:"expiration_date"`
+}
+
+// StoredShare is the server-side representation of a share link.
+type StoredShare struct {
+	ID             string    `json:"id"`
+	Token          string    `json:"token"`
+	FileID         string    `json:"file_id"`
+	OwnerEmail     string    `json:"owner_email"`
+	PasswordHash   string    `json:"password_hash,omitempty"`
+	MaxDownloads   int       `json:"max_downloads,omitempty"`
+	DownloadCount  int       `json:"download_count"`
+	CreatedAt      time.Time `json:"created_at"`
+	ExpirationDate time.Time `json:"expiration_date"`
+}
+
+// toFile converts the stored file to the personal file DTO.
+func (f *StoredFile) toFile() dto.File {
+	return dto.File{
+		FileID:          f.ID,
+		OwnerID:         f.OwnerID,
+		FileName:        f.Filename,
+		Size:            f.Size,
+		UpdateDateBegin: f.UploadDate,
+		UpdateDateEnd:   f.UploadDate,
+		ExpirationDate:  f.ExpirationDate,
+	}
+}
+
+// toOrganizationFile converts the stored file to the organization file DTO.
+func (f *StoredFile) toOrganizationFile() dto.OrganizationFile {
+	return dto.OrganizationFile{
+		ID:              f.ID,
+		Filename:        f.Filename,
+		Size:            f.Size,
+		OrganizationID:  f.OrganizationID,
+		Tags:            slices.Clone(f.Tags),
+		UploadDateBegin: f.UploadDate.Format(time.RFC3339),
+		UploadDateEnd:   f.UploadDate.Format(time.RFC3339),
+		ExpirationDate:  f.ExpirationDate.Format(time.RFC3339),
+		OwnerID:         f.OwnerID,
+		OwnerEmail:      f.OwnerEmail,
+	}
+}
+
+// toInfoFile converts the stored file to the file information DTO, with the
+// checksum of its content.
+func (f *StoredFile) toInfoFile(content []byte) dto.InfoFile {
+	mode := dto.EncryptionModeClear
+	if f.Encrypted {
+		mode = dto.EncryptionModeE2E
+	}
+	sum := sha256.Sum256(content)
+	return dto.InfoFile{
+		Filename:       f.Filename,
+		Size:           f.Size,
+		NbParts:        len(f.Parts),
+		FileID:         f.ID,
+		OwnerID:        f.OwnerID,
+		OwnerEmail:     f.OwnerEmail,
+		OrganizationID: f.OrganizationID,
+		Tags:           slices.Clone(f.Tags),
+		UploadDate:     f.UploadDate,
+		ExpirationDate: f.ExpirationDate,
+		EncryptionMode: mode,
+		Checksum:       "sha256:" + hex.EncodeToString(sum[:]),
+	}
+}
+
+// hasTags reports whether the file carries every tag of the list.
+func (f *StoredFile) hasTags(tags []string) bool {
+	for _, tag := range tags {
+		if !slices.Contains(f.Tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// isExpired reports whether the file is expired at the given time.
+func (f *StoredFile) isExpired(now time.Time) bool {
+	return !f.ExpirationDate.IsZero() && f.ExpirationDate.Before(now)
+}
+
+// splitParts splits a size into parts of at most partSize bytes.
+func splitParts(size, partSize int64) []int64 {
+	if size == 0 {
+		return []int64{0}
+	}
+	var parts []int64
+	for size > 0 {
+		n := min(size, partSize)
+		parts = append(parts, n)
+		size -= n
+	}
+	return parts
+}
+
+// loadState reads the state and the blobs from the data directory.
+// A missing state file is not an error: the server simply starts empty.
+func loadState(dataDir string) (*State, map[string][]byte, error) {
+	st := &State{}
+	blobs := make(map[string][]byte)
+
+	// #nosec G304 -- dataDir is provided by the developer running the mock server
+	data, err := os.ReadFile(filepath.Join(dataDir, stateFileName))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return st, blobs, nil
+		}
+		return nil, nil, fmt.Errorf("error reading state file: %w", err)
+	}
+	if err := json.Unmarshal(data, st); err != nil {
+		return nil, nil, fmt.Errorf("error parsing state file: %w", err)
+	}
+
+	for _, f := range st.Files {
+		if !f.Complete {
+			continue
+		}
+		// #nosec G304 -- blob names are file IDs generated by the server
+		content, err := os.ReadFile(filepath.Join(dataDir, blobsDirName, f.ID))
+		if err != nil {
+			return nil, nil, fmt.Errorf("error reading blob %s: %w", f.ID, err)
+		}
+		blobs[f.ID] = content
+	}
+	return st, blobs, nil
+}
+
+// saveState writes the state file into the data directory.
+func saveState(dataDir string, st *State) error {
+	if err := os.MkdirAll(filepath.Join(dataDir, blobsDirName), dataDirPerm); err != nil {
+		return fmt.Errorf("error creating data directory: %w", err)
+	}
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshalling state: %w", err)
+	}
+	tmp := filepath.Join(dataDir, stateFileName+".tmp")
+	if err := os.WriteFile(tmp, data, dataFilePerm); err != nil {
+		return fmt.Errorf("error writing state file: %w", err)
+	}
+	if err := os.Rename(tmp, filepath.Join(dataDir, stateFileName)); err != nil {
+		return fmt.Errorf("error writing state file: %w", err)
+	}
+	return nil
+}
+
+// saveBlob writes the content of a file into the data directory.
+func saveBlob(dataDir, fileID string, content []byte) error {
+	if err := os.MkdirAll(filepath.Join(dataDir, blobsDirName), dataDirPerm); err != nil {
+		return fmt.Errorf("error creating data directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dataDir, blobsDirName, fileID), content, dataFilePerm); err != nil {
+		return fmt.Errorf("error writing blob: %w", err)
+	}
+	return nil
+}
+
+// removeBlob deletes the content of a file from the data directory.
+func removeBlob(dataDir, fileID string) error {
+	err := os.Remove(filepath.Join(dataDir, blobsDirName, fileID))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("error removing blob: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+package mockserver
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+)
+
+// DevToken returns an unsigned token for the given email, valid for the given duration.
+// The mock server accepts any bearer token; this one also carries the email and the
+// expiration date read by `eph check`.
+func DevToken(email string, validity time.Duration) string {
+	header, _ := json.Marshal(map[string]string{"alg": "none", "typ": "JWT"})
+	payload, _ := json.Marshal(map[string]any{
+		"email": email,
+		"exp":   time.Now().Add(validity).Unix(),
+	})
+	return base64.RawStdEncoding.EncodeToString(header) + "." +
+		base64.RawStdEncoding.EncodeToString(payload) + ".mock"
+}
@@ -0,0 +1,277 @@
+package mockserver
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ephemeralfiles/eph/pkg/dto"
+	"github.com/ephemeralfiles/eph/pkg/ephcli"
+)
+
+var (
+	errInvalidContentRange = errors.New("invalid Content-Range header")
+	errInvalidAESKey       = errors.New("invalid AES key")
+)
+
+// contentRange is a parsed "bytes start-end/total" header.
+type contentRange struct {
+	start int64
+	end   int64
+	total int64
+}
+
+// parseContentRange parses the Content-Range header sent with upload chunks.
+func parseContentRange(header string) (contentRange, error) {
+	var cr contentRange
+	spec, ok := strings.CutPrefix(header, "bytes ")
+	if !ok {
+		return cr, errInvalidContentRange
+	}
+	rng, total, ok := strings.Cut(spec, "/")
+	if !ok {
+		return cr, errInvalidContentRange
+	}
+	start, end, ok := strings.Cut(rng, "-")
+	if !ok {
+		return cr, errInvalidContentRange
+	}
+	var err error
+	if cr.start, err = strconv.ParseInt(start, 10, 64); err != nil {
+		return cr, errInvalidContentRange
+	}
+	if cr.end, err = strconv.ParseInt(end, 10, 64); err != nil {
+		return cr, errInvalidContentRange
+	}
+	if cr.total, err = strconv.ParseInt(total, 10, 64); err != nil {
+		return cr, errInvalidContentRange
+	}
+	return cr, nil
+}
+
+// decryptAESKey decrypts the AES key sent by the client for a transaction.
+func (s *Server) decryptAESKey(r *http.Request) ([]byte, error) {
+	var payload dto.RequestAESKey
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("invalid payload: %w", err)
+	}
+	encrypted, err := base64.StdEncoding.DecodeString(payload.AESKey)
+	if err != nil {
+		return nil, errInvalidAESKey
+	}
+	hexKey, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, s.key, encrypted, nil)
+	if err != nil {
+		return nil, errInvalidAESKey
+	}
+	key, err := hex.DecodeString(string(hexKey))
+	if err != nil || len(key) != ephcli.AESKeySize32 {
+		return nil, errInvalidAESKey
+	}
+	return key, nil
+}
+
+// handleUploadInit starts an E2E upload and returns the transaction headers.
+func (s *Server) handleUploadInit(w http.ResponseWriter, r *http.Request, u user) {
+	orgID := r.Header.Get("X-Organization-Id")
+	var tags []string
+	if header := r.Header.Get("X-File-Tags"); header != "" {
+		tags = strings.Split(header, ",")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if orgID != "" && s.findOrganizationLocked(orgID) == nil {
+		writeError(w, http.StatusNotFound, "organization not found")
+		return
+	}
+	expiresAt, err := parseRequestedExpiration(r.Header.Get("X-Expiration-Date"), s.now())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	f := s.newFileLocked(u, "", orgID, tags)
+	setExpiration(f, expiresAt)
+	f.Encrypted = true
+	uploadID := newID()
+	s.uploads[uploadID] = &transaction{fileID: f.ID}
+
+	w.Header().Set("X-File-Id", f.ID)
+	w.Header().Set("X-Upload-Id", uploadID)
+	w.Header().Set("X-File-Public-Key", s.publicKey)
+	if s.maxChunk > 0 {
+		w.Header().Set("X-Max-Chunk-Size", strconv.FormatInt(s.maxChunk, 10))
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleUploadKey receives the AES key of an E2E upload.
+func (s *Server) handleUploadKey(w http.ResponseWriter, r *http.Request, _ user) {
+	key, err := s.decryptAESKey(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, ok := s.uploads[r.PathValue("tx")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "upload not found")
+		return
+	}
+	tx.aesKey = key
+	writeJSON(w, http.StatusOK, map[string]string{"message": "key received"})
+}
+
+// handleUploadChunk decrypts and appends an uploaded chunk.
+// The upload is complete when the chunk reaches the total announced in Content-Range.
+func (s *Server) handleUploadChunk(w http.ResponseWriter, r *http.Request, _ user) {
+	cr, err := parseContentRange(r.Header.Get("Content-Range"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	form, err := readUploadForm(r, "uploadfile")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	uploadID := r.PathValue("tx")
+	tx, ok := s.uploads[uploadID]
+	if !ok || tx.aesKey == nil {
+		writeError(w, http.StatusNotFound, "upload not found")
+		return
+	}
+	f := s.findFileLocked(tx.fileID)
+	if f == nil {
+		writeError(w, http.StatusNotFound, "file not found")
+		return
+	}
+
+	chunk, err := ephcli.DecryptAES(tx.aesKey, form.content)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if s.maxChunk > 0 && int64(len(chunk)) > s.maxChunk {
+		writeError(w, http.StatusRequestEntityTooLarge, "chunk larger than the maximum chunk size")
+		return
+	}
+	received := s.blobs[f.ID]
+	if cr.start != int64(len(received)) || cr.end-cr.start+1 != int64(len(chunk)) {
+		writeError(w, http.StatusBadRequest, "chunk does not match Content-Range")
+		return
+	}
+
+	s.blobs[f.ID] = append(received, chunk...)
+	f.Parts = append(f.Parts, int64(len(chunk)))
+	if f.Filename == "" {
+		f.Filename = form.filename
+	}
+	if cr.end+1 == cr.total {
+		f.Size = cr.total
+		f.Complete = true
+		delete(s.uploads, uploadID)
+		s.storeBlobLocked(f.ID, s.blobs[f.ID])
+		s.persistLocked()
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"message": "chunk received"})
+}
+
+// handleDownloadInit starts an E2E download and returns the transaction headers.
+func (s *Server) handleDownloadInit(w http.ResponseWriter, r *http.Request, _ user) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f := s.findFileLocked(r.PathValue("id"))
+	if f == nil || !f.Complete {
+		writeError(w, http.StatusNotFound, "file not found")
+		return
+	}
+	transactionID := newID()
+	s.downloads[transactionID] = &transaction{fileID: f.ID}
+
+	w.Header().Set("X-Transaction-Id", transactionID)
+	w.Header().Set("X-File-Public-Key", s.publicKey)
+	if s.maxChunk > 0 {
+		w.Header().Set("X-Max-Chunk-Size", strconv.FormatInt(s.maxChunk, 10))
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleDownloadKey receives the AES key of an E2E download.
+func (s *Server) handleDownloadKey(w http.ResponseWriter, r *http.Request, _ user) {
+	key, err := s.decryptAESKey(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, ok := s.downloads[r.PathValue("tx")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "transaction not found")
+		return
+	}
+	tx.aesKey = key
+	writeJSON(w, http.StatusOK, map[string]string{"message": "key received"})
+}
+
+// handleDownloadChunk encrypts and returns one part of a file.
+func (s *Server) handleDownloadChunk(w http.ResponseWriter, r *http.Request, _ user) {
+	part, err := strconv.Atoi(r.PathValue("part"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid part")
+		return
+	}
+
+	s.mu.Lock()
+	tx, ok := s.downloads[r.PathValue("tx")]
+	var f *StoredFile
+	if ok {
+		f = s.findFileLocked(tx.fileID)
+	}
+	if !ok || tx.aesKey == nil || f == nil {
+		s.mu.Unlock()
+		writeError(w, http.StatusNotFound, "transaction not found")
+		return
+	}
+	if part < 0 || part >= len(f.Parts) {
+		s.mu.Unlock()
+		writeError(w, http.StatusNotFound, "part not found")
+		return
+	}
+	var offset int64
+	for _, size := range f.Parts[:part] {
+		offset += size
+	}
+	plaintext := s.blobs[f.ID][offset : offset+f.Parts[part]]
+	aesKey := tx.aesKey
+	s.mu.Unlock()
+
+	encrypted, err := ephcli.EncryptAES(aesKey, plaintext)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Length", strconv.Itoa(len(encrypted)))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(encrypted)
+}
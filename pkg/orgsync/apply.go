@@ -0,0 +1,183 @@
+package orgsync
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/ephemeralfiles/eph/pkg/ephcli"
+)
+
+const (
+	// DefaultConcurrency is the number of parallel transfers of a sync.
+	DefaultConcurrency = 4
+	// dirPerm is the permission of the directories created when pulling.
+	dirPerm = 0750
+)
+
+// Syncer synchronizes a directory with the files of an organization having
+// all of Tags.
+type Syncer struct {
+	Client         *ephcli.ClientEphemeralfiles
+	OrganizationID string
+	Tags           []string
+	Dir            string
+	// Pull mirrors the organization to the directory, instead of the directory
+	// to the organization.
+	Pull bool
+	// Delete deletes the files of the destination missing from the source.
+	Delete bool
+	// Clear transfers the files without encryption.
+	Clear bool
+}
+
+// Plan compares the directory with the files of the organization and returns
+// the actions synchronizing them. When pulling, a missing directory is empty.
+func (s *Syncer) Plan() (*Plan, error) {
+	local, err := s.scan()
+	if err != nil {
+		return nil, err
+	}
+	remote, err := s.Client.SelectOrganizationFiles(s.OrganizationID, ephcli.OrganizationFileFilter{Tags: s.Tags})
+	if err != nil {
+		return nil, err
+	}
+	if s.Pull {
+		synced, err := readManifest(s.Dir, s.OrganizationID, s.Tags)
+		if err != nil {
+			return nil, err
+		}
+		return PlanPull(s.Dir, local, remote, synced, s.Delete)
+	}
+	return PlanPush(s.Dir, local, remote, s.Delete)
+}
+
+// scan returns the files of the directory.
+func (s *Syncer) scan() ([]LocalFile, error) {
+	info, err := os.Stat(s.Dir)
+	switch {
+	case s.Pull && errors.Is(err, fs.ErrNotExist):
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("error reading directory: %w", err)
+	case !info.IsDir():
+		return nil, fmt.Errorf("%w: %s", ErrNotDirectory, s.Dir)
+	}
+	return ScanDir(s.Dir)
+}
+
+// Apply runs the actions of plan with at most concurrency transfers in
+// progress, each with its own copy of the client and without progress bar.
+// done, when set, is called as each action ends; calls are serialized. It
+// returns the number of failed actions. When pulling, the files downloaded are
+// then recorded in the manifest of the directory.
+func (s *Syncer) Apply(plan *Plan, concurrency int, done func(Action, error)) (int, error) {
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		failed  int
+		indexes = make(chan int)
+	)
+	for range min(concurrency, len(plan.Actions)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				action := plan.Actions[i]
+				err := s.apply(action)
+
+				mu.Lock()
+				if err != nil {
+					failed++
+				}
+				s.recordSynced(plan, action, err)
+				if done != nil {
+					done(action, err)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for i := range plan.Actions {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	if !s.Pull {
+		return failed, nil
+	}
+	return failed, writeManifest(s.Dir, s.OrganizationID, s.Tags, plan.Synced)
+}
+
+// recordSynced updates the paths downloaded by pulls after action: removed
+// files and files that could not be created are no longer synchronized.
+func (s *Syncer) recordSynced(plan *Plan, action Action, err error) {
+	if !s.Pull || plan.Synced == nil {
+		return
+	}
+	if (action.Op == OpDelete && err == nil) || (action.Op == OpCreate && err != nil) {
+		delete(plan.Synced, action.Path)
+	}
+}
+
+// apply runs an action.
+func (s *Syncer) apply(action Action) error {
+	client := s.Client.Clone()
+	client.DisableProgressBar()
+
+	if s.Pull {
+		target := filepath.Join(s.Dir, filepath.FromSlash(action.Path))
+		if action.Op == OpDelete {
+			if err := os.Remove(target); err != nil {
+				return fmt.Errorf("error removing file: %w", err)
+			}
+			return nil
+		}
+		if err := os.MkdirAll(filepath.Dir(target), dirPerm); err != nil {
+			return fmt.Errorf("error creating directory: %w", err)
+		}
+		client.SetOutputDir("")
+		client.SetExistPolicy(ephcli.ExistOverwrite)
+		if s.Clear {
+			return client.DownloadOrganizationFile(action.Remote.ID, target)
+		}
+		return client.DownloadE2E(action.Remote.ID, target)
+	}
+
+	if action.Op != OpDelete {
+		if err := s.upload(client, action.Local); err != nil {
+			return err
+		}
+	}
+	// Updated files are deleted once their new version is uploaded
+	if action.Remote != nil {
+		return client.DeleteOrganizationFile(action.Remote.ID)
+	}
+	return nil
+}
+
+// upload uploads a local file, tagged with its path and checksum.
+func (s *Syncer) upload(client *ephcli.ClientEphemeralfiles, f *LocalFile) error {
+	opts := ephcli.UploadOptions{
+		OrganizationID: s.OrganizationID,
+		Tags:           append(slices.Clone(s.Tags), PathTag(f.Path), ChecksumTag(f.Checksum)),
+		Name:           path.Base(f.Path),
+	}
+	localPath := filepath.Join(s.Dir, filepath.FromSlash(f.Path))
+	if s.Clear {
+		_, err := client.UploadOrganizationFileWithOptions(localPath, opts)
+		return err
+	}
+	_, err := client.UploadE2EWithOptions(localPath, opts)
+	return err
+}
@@ -0,0 +1,89 @@
+package orgsync
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+const (
+	// ManifestName is the name of the file, at the root of a pulled directory,
+	// recording the paths of the files downloaded by previous pulls.
+	ManifestName = ".eph-sync.json"
+	// manifestPerm is the permission of the manifest.
+	manifestPerm = 0600
+)
+
+// manifest lists the files of a directory downloaded by pulls of an
+// organization with a set of tags. Only these files are deleted by a pull.
+type manifest struct {
+	OrganizationID string   `json:"organizationId"`
+	Tags           []string `json:"tags"`
+	Paths          []string `json:"paths"`
+}
+
+// readManifest returns the paths recorded by the previous pulls of the
+// organization files having tags to dir. Paths recorded for another
+// organization or other tags are ignored.
+func readManifest(dir, orgID string, tags []string) (map[string]bool, error) {
+	// #nosec G304 -- dir is the synchronized directory given by the user
+	data, err := os.ReadFile(filepath.Join(dir, ManifestName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return map[string]bool{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading sync manifest: %w", err)
+	}
+	var m manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("error decoding sync manifest %s: %w", filepath.Join(dir, ManifestName), err)
+	}
+	synced := map[string]bool{}
+	if m.OrganizationID != orgID || !slices.Equal(sortedTags(m.Tags), sortedTags(tags)) {
+		return synced, nil
+	}
+	for _, path := range m.Paths {
+		synced[path] = true
+	}
+	return synced, nil
+}
+
+// writeManifest records synced as the paths pulled to dir, removing the
+// manifest when there is none.
+func writeManifest(dir, orgID string, tags []string, synced map[string]bool) error {
+	path := filepath.Join(dir, ManifestName)
+	if len(synced) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("error removing sync manifest: %w", err)
+		}
+		return nil
+	}
+
+	m := manifest{OrganizationID: orgID, Tags: sortedTags(tags), Paths: make([]string, 0, len(synced))}
+	for p := range synced {
+		m.Paths = append(m.Paths, p)
+	}
+	slices.Sort(m.Paths)
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding sync manifest: %w", err)
+	}
+	if err := os.MkdirAll(dir, dirPerm); err != nil {
+		return fmt.Errorf("error creating directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, manifestPerm); err != nil {
+		return fmt.Errorf("error writing sync manifest: %w", err)
+	}
+	return nil
+}
+
+// sortedTags returns a sorted copy of tags.
+func sortedTags(tags []string) []string {
+	sorted := slices.Clone(tags)
+	slices.Sort(sorted)
+	return sorted
+}
@@ -0,0 +1,166 @@
+package orgsync_test
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ephemeralfiles/eph/pkg/dto"
+	"github.com/ephemeralfiles/eph/pkg/ephcli"
+	"github.com/ephemeralfiles/eph/pkg/mockserver"
+	"github.com/ephemeralfiles/eph/pkg/orgsync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeFiles creates the given files, relative to dir.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0750))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	}
+}
+
+// summary returns the operation and path of the actions of plan.
+func summary(plan *orgsync.Plan) []string {
+	var result []string
+	for _, action := range plan.Actions {
+		result = append(result, string(action.Op)+" "+action.Path)
+	}
+	return result
+}
+
+// applyPlan returns the plan of s, after applying it.
+func applyPlan(t *testing.T, s *orgsync.Syncer) *orgsync.Plan {
+	t.Helper()
+	p, err := s.Plan()
+	require.NoError(t, err)
+	failed, err := s.Apply(p, 2, nil)
+	require.NoError(t, err)
+	require.Zero(t, failed)
+	return p
+}
+
+func TestTags(t *testing.T) {
+	t.Parallel()
+
+	f := dto.OrganizationFile{Tags: []string{
+		"reports", orgsync.PathTag("sub dir/a, b.txt"), orgsync.ChecksumTag("sha256:abc"),
+	}}
+	assert.NotContains(t, f.Tags[1], ",")
+	path, ok := orgsync.RemotePath(f)
+	assert.True(t, ok)
+	assert.Equal(t, "sub dir/a, b.txt", path)
+	assert.Equal(t, "sha256:abc", orgsync.RemoteChecksum(f))
+
+	_, ok = orgsync.RemotePath(dto.OrganizationFile{Tags: []string{"reports"}})
+	assert.False(t, ok)
+}
+
+func TestScanDir(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"b.txt":                            "bb",
+		"sub/a.txt":                        "a",
+		"sub/c.txt" + ephcli.PartialSuffix: "partial",
+	})
+	files, err := orgsync.ScanDir(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []orgsync.LocalFile{{Path: "b.txt", Size: 2}, {Path: "sub/a.txt", Size: 1}}, files)
+}
+
+func TestSync(t *testing.T) {
+	t.Parallel()
+
+	srv, err := mockserver.New(mockserver.Options{Seed: true})
+	require.NoError(t, err)
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+	client := ephcli.NewClient(mockserver.DevToken(mockserver.DefaultEmail, time.Hour))
+	client.SetEndpoint(ts.URL)
+	client.DisableProgressBar()
+
+	src := t.TempDir()
+	writeFiles(t, src, map[string]string{"a.txt": "a", "sub/b.txt": "b"})
+	push := &orgsync.Syncer{
+		Client:         client,
+		OrganizationID: mockserver.FixtureOrganizationID,
+		Tags:           []string{"reports"},
+		Dir:            src,
+	}
+	assert.Equal(t, []string{"create a.txt", "create sub/b.txt"}, summary(applyPlan(t, push)))
+
+	p := applyPlan(t, push)
+	assert.Empty(t, p.Actions)
+	assert.Equal(t, 2, p.Unchanged)
+
+	// Changed and removed files
+	writeFiles(t, src, map[string]string{"a.txt": "a2"})
+	require.NoError(t, os.Remove(filepath.Join(src, "sub", "b.txt")))
+	p, err = push.Plan()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"update a.txt"}, summary(p), "remote files are kept without Delete")
+	push.Delete = true
+	assert.Equal(t, []string{"update a.txt", "delete sub/b.txt"}, summary(applyPlan(t, push)))
+	files, err := client.SelectOrganizationFiles(mockserver.FixtureOrganizationID,
+		ephcli.OrganizationFileFilter{Tags: []string{"reports"}})
+	require.NoError(t, err)
+	require.Len(t, files, 2, "a.txt and the fixture report.csv")
+
+	// Pull to a new directory, then remove the files missing remotely
+	out := filepath.Join(t.TempDir(), "out")
+	pull := &orgsync.Syncer{
+		Client:         client,
+		OrganizationID: mockserver.FixtureOrganizationID,
+		Tags:           []string{"reports"},
+		Dir:            out,
+		Pull:           true,
+	}
+	assert.Equal(t, []string{"create a.txt", "create report.csv"}, summary(applyPlan(t, pull)))
+	content, err := os.ReadFile(filepath.Join(out, "a.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "a2", string(content))
+
+	// Local files that were not pulled are kept
+	writeFiles(t, out, map[string]string{"a.txt": "local", "extra.txt": "x"})
+	pull.Delete = true
+	assert.Equal(t, []string{"update a.txt"}, summary(applyPlan(t, pull)))
+	assert.FileExists(t, filepath.Join(out, "extra.txt"))
+	content, err = os.ReadFile(filepath.Join(out, "a.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "a2", string(content))
+
+	// Pulled files deleted remotely are removed
+	require.NoError(t, os.Remove(filepath.Join(src, "a.txt")))
+	writeFiles(t, src, map[string]string{"c.txt": "c"})
+	assert.Equal(t, []string{"create c.txt", "delete a.txt"}, summary(applyPlan(t, push)))
+	assert.Equal(t, []string{"create c.txt", "delete a.txt"}, summary(applyPlan(t, pull)))
+	assert.NoFileExists(t, filepath.Join(out, "a.txt"))
+	local, err := orgsync.ScanDir(out)
+	require.NoError(t, err)
+	require.Len(t, local, 3)
+	assert.Equal(t, []orgsync.LocalFile{
+		{Path: "c.txt", Size: 1}, {Path: "extra.txt", Size: 1}, {Path: "report.csv", Size: local[2].Size},
+	}, local, "the manifest is not synchronized")
+	assert.FileExists(t, filepath.Join(out, orgsync.ManifestName))
+
+	// Deletion is refused when no remote file has the tags
+	pull.Tags = []string{"no-such-tag"}
+	_, err = pull.Plan()
+	require.ErrorIs(t, err, orgsync.ErrNoRemoteFiles)
+
+	// or when the pushed directory is empty
+	push.Dir = t.TempDir()
+	_, err = push.Plan()
+	require.ErrorIs(t, err, orgsync.ErrNoLocalFiles)
+	push.Delete = false
+	p, err = push.Plan()
+	require.NoError(t, err)
+	assert.Empty(t, p.Actions)
+}
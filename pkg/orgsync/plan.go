@@ -0,0 +1,302 @@
+// Package orgsync synchronizes a local directory with the files of an
+// organization having a set of tags. The relative path and checksum of each
+// synchronized file are stored in tags of the remote file, so that new and
+// changed files can be detected on both sides.
+package orgsync
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/ephemeralfiles/eph/pkg/dto"
+	"github.com/ephemeralfiles/eph/pkg/ephcli"
+)
+
+const (
+	// PathTagPrefix prefixes the tag holding the relative path of a synchronized file.
+	PathTagPrefix = "eph-path:"
+	// ChecksumTagPrefix prefixes the tag holding the checksum of a synchronized file.
+	ChecksumTagPrefix = "eph-checksum:"
+)
+
+var (
+	// ErrNotDirectory is returned when the synchronized path is not a directory.
+	ErrNotDirectory = errors.New("not a directory")
+	// ErrNoRemoteFiles is returned when a pull deleting local files finds no
+	// remote file, which would empty the directory.
+	ErrNoRemoteFiles = errors.New("no organization file has the tags, refusing to delete local files")
+	// ErrNoLocalFiles is returned when a push deleting organization files finds
+	// no local file, e.g. in a wrong or unmounted directory.
+	ErrNoLocalFiles = errors.New("the directory has no file, refusing to delete organization files")
+)
+
+// Operation is the change an action makes to the destination of a sync.
+type Operation string
+
+// Operations of actions. The destination is the organization when pushing and
+// the local directory when pulling.
+const (
+	// OpCreate copies a file missing from the destination.
+	OpCreate Operation = "create"
+	// OpUpdate replaces a file of the destination whose content differs.
+	OpUpdate Operation = "update"
+	// OpDelete deletes a file of the destination missing from the source.
+	OpDelete Operation = "delete"
+)
+
+// LocalFile is a regular file of the synchronized directory.
+type LocalFile struct {
+	// Path is the path of the file relative to the directory, with forward slashes.
+	Path string
+	Size int64
+	// Checksum is "sha256:<hex>", computed only when needed when pulling.
+	Checksum string
+}
+
+// Action is a change of a sync plan.
+type Action struct {
+	Op   Operation
+	Path string
+	// Local is the local file: the source of pushes, the deleted file of pulls.
+	Local *LocalFile
+	// Remote is the remote file: the replaced or deleted file of pushes, the
+	// source of pulls.
+	Remote *dto.OrganizationFile
+}
+
+// Plan is the list of actions synchronizing a directory, and the number of
+// files already in sync.
+type Plan struct {
+	Actions   []Action
+	Unchanged int
+	// Synced are the paths of the local files downloaded by pulls once the
+	// plan is applied, recorded in the manifest of the directory.
+	Synced map[string]bool
+}
+
+// PathTag returns the tag storing the relative path of a file. The path is
+// escaped so that the tag holds no comma.
+func PathTag(path string) string {
+	return PathTagPrefix + url.PathEscape(path)
+}
+
+// ChecksumTag returns the tag storing the checksum of a file.
+func ChecksumTag(checksum string) string {
+	return ChecksumTagPrefix + checksum
+}
+
+// RemotePath returns the relative path stored in the tags of a remote file, or
+// false when the file was not uploaded by a sync.
+func RemotePath(f dto.OrganizationFile) (string, bool) {
+	for _, tag := range f.Tags {
+		if escaped, ok := strings.CutPrefix(tag, PathTagPrefix); ok {
+			path, err := url.PathUnescape(escaped)
+			return path, err == nil && path != ""
+		}
+	}
+	return "", false
+}
+
+// RemoteChecksum returns the checksum stored in the tags of a remote file, or
+// an empty string.
+func RemoteChecksum(f dto.OrganizationFile) string {
+	for _, tag := range f.Tags {
+		if checksum, ok := strings.CutPrefix(tag, ChecksumTagPrefix); ok {
+			return checksum
+		}
+	}
+	return ""
+}
+
+// ScanDir returns the regular files of dir and its subdirectories, sorted by
+// path. Symbolic links, partial downloads and the manifest of pulls are ignored.
+func ScanDir(dir string) ([]LocalFile, error) {
+	var files []LocalFile
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() || isPartialDownload(path) {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if rel == ManifestName {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		files = append(files, LocalFile{Path: filepath.ToSlash(rel), Size: info.Size()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error reading directory: %w", err)
+	}
+	return files, nil
+}
+
+// isPartialDownload reports whether path is a download in progress or its journal.
+func isPartialDownload(path string) bool {
+	return strings.HasSuffix(path, ephcli.PartialSuffix) || strings.HasSuffix(path, ephcli.PartialJournalSuffix)
+}
+
+// PlanPush returns the actions uploading the new and changed local files of
+// dir, and deleting the remote files missing locally when del is set. remote
+// are the files of the organization in the scope of the sync, most recent
+// first; files not uploaded by a sync are ignored. Checksums of local are
+// computed. Deletion is refused when there is no local file.
+func PlanPush(dir string, local []LocalFile, remote []dto.OrganizationFile, del bool) (*Plan, error) {
+	if del && len(local) == 0 {
+		return nil, ErrNoLocalFiles
+	}
+	current, older := remoteByPath(remote, false)
+	plan := &Plan{}
+	for i := range local {
+		f := &local[i]
+		checksum, err := ephcli.FileChecksum(filepath.Join(dir, filepath.FromSlash(f.Path)))
+		if err != nil {
+			return nil, err
+		}
+		f.Checksum = checksum
+
+		r, ok := current[f.Path]
+		switch {
+		case !ok:
+			plan.Actions = append(plan.Actions, Action{Op: OpCreate, Path: f.Path, Local: f})
+		case RemoteChecksum(*r) != checksum:
+			plan.Actions = append(plan.Actions, Action{Op: OpUpdate, Path: f.Path, Local: f, Remote: r})
+		default:
+			plan.Unchanged++
+		}
+	}
+	if !del {
+		return plan, nil
+	}
+
+	// Remote files missing locally, and previous versions left by interrupted updates
+	isLocal := make(map[string]bool, len(local))
+	for _, f := range local {
+		isLocal[f.Path] = true
+	}
+	for _, path := range sortedKeys(current) {
+		if !isLocal[path] {
+			plan.Actions = append(plan.Actions, Action{Op: OpDelete, Path: path, Remote: current[path]})
+		}
+	}
+	for _, r := range older {
+		path, _ := RemotePath(*r)
+		plan.Actions = append(plan.Actions, Action{Op: OpDelete, Path: path, Remote: r})
+	}
+	return plan, nil
+}
+
+// PlanPull returns the actions downloading the new and changed remote files to
+// dir, and deleting the local files missing remotely when del is set. remote
+// are the files of the organization in the scope of the sync, most recent
+// first; files not uploaded by a sync are stored under their sanitized name.
+// Files are compared by checksum when the remote file has one, by size otherwise.
+// Only the local files in synced, downloaded by previous pulls, are deleted, and
+// deletion is refused when there is no remote file.
+func PlanPull(
+	dir string, local []LocalFile, remote []dto.OrganizationFile, synced map[string]bool, del bool,
+) (*Plan, error) {
+	if del && len(remote) == 0 {
+		return nil, ErrNoRemoteFiles
+	}
+	current, _ := remoteByPath(remote, true)
+	byPath := make(map[string]*LocalFile, len(local))
+	for i := range local {
+		byPath[local[i].Path] = &local[i]
+	}
+
+	// Files of previous pulls that were removed locally are forgotten
+	plan := &Plan{Synced: make(map[string]bool, len(current))}
+	for path := range synced {
+		if _, ok := byPath[path]; ok {
+			plan.Synced[path] = true
+		}
+	}
+	for _, path := range sortedKeys(current) {
+		plan.Synced[path] = true
+		r := current[path]
+		f, ok := byPath[path]
+		if !ok {
+			plan.Actions = append(plan.Actions, Action{Op: OpCreate, Path: path, Remote: r})
+			continue
+		}
+		changed := f.Size != r.Size
+		if checksum := RemoteChecksum(*r); checksum != "" && !changed {
+			localChecksum, err := ephcli.FileChecksum(filepath.Join(dir, filepath.FromSlash(path)))
+			if err != nil {
+				return nil, err
+			}
+			f.Checksum = localChecksum
+			changed = localChecksum != checksum
+		}
+		if changed {
+			plan.Actions = append(plan.Actions, Action{Op: OpUpdate, Path: path, Local: f, Remote: r})
+		} else {
+			plan.Unchanged++
+		}
+	}
+
+	if del {
+		for i := range local {
+			if _, ok := current[local[i].Path]; !ok && synced[local[i].Path] {
+				plan.Actions = append(plan.Actions, Action{Op: OpDelete, Path: local[i].Path, Local: &local[i]})
+			}
+		}
+	}
+	return plan, nil
+}
+
+// remoteByPath returns the most recent remote file of each relative path, and
+// the older files sharing a path. Files not uploaded by a sync are ignored,
+// unless byName is set: they are then stored under their sanitized name, or
+// their ID. Stored paths escaping the directory are replaced the same way.
+func remoteByPath(remote []dto.OrganizationFile, byName bool) (map[string]*dto.OrganizationFile, []*dto.OrganizationFile) {
+	current := make(map[string]*dto.OrganizationFile, len(remote))
+	var older []*dto.OrganizationFile
+	for i := range remote {
+		r := &remote[i]
+		path, ok := RemotePath(*r)
+		if ok && !filepath.IsLocal(filepath.FromSlash(path)) {
+			ok = false
+		}
+		if !ok {
+			if !byName {
+				continue
+			}
+			name, err := ephcli.SanitizeFilename(r.Filename)
+			if err != nil {
+				name = r.ID
+			}
+			path = name
+		}
+		if _, seen := current[path]; seen {
+			older = append(older, r)
+			continue
+		}
+		current[path] = r
+	}
+	return current, older
+}
+
+// sortedKeys returns the keys of m in increasing order.
+func sortedKeys(m map[string]*dto.OrganizationFile) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,151 @@
+// Package ratelimit provides a token bucket limiting the bandwidth of transfers.
+// A single Limiter can be shared by any number of readers, including readers used
+// by parallel transfers: they all draw from the same bucket.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/ephemeralfiles/eph/pkg/units"
+)
+
+// maxReadSize bounds the size of a single read so that limited transfers stay smooth.
+const maxReadSize = 32 * 1024
+
+// Limiter is a token bucket where one token is one byte.
+// The bucket holds at most one second worth of tokens.
+type Limiter struct {
+	mu       sync.Mutex
+	rate     int64
+	schedule Schedule
+	tokens   float64
+	last     time.Time
+	now      func() time.Time
+}
+
+// New returns a limiter allowing bytesPerSecond bytes per second.
+// A rate of zero or less means unlimited.
+func New(bytesPerSecond int64) *Limiter {
+	return NewScheduled(bytesPerSecond, nil)
+}
+
+// NewScheduled returns a limiter following schedule, using defaultRate outside of
+// the windows of the schedule. A rate of zero or less means unlimited.
+func NewScheduled(defaultRate int64, schedule Schedule) *Limiter {
+	return &Limiter{
+		rate:     defaultRate,
+		schedule: schedule,
+		now:      time.Now,
+	}
+}
+
+// Parse builds a limiter from a rate such as "5M" and optional schedule entries
+// (see ParseSchedule). It returns a nil limiter, meaning unlimited, when rate is
+// empty or zero and there is no schedule.
+func Parse(rate string, schedule []string) (*Limiter, error) {
+	var (
+		bytesPerSecond int64
+		err            error
+	)
+	if rate != "" {
+		if bytesPerSecond, err = units.ParseSize(rate); err != nil {
+			return nil, fmt.Errorf("invalid rate limit: %w", err)
+		}
+	}
+	windows, err := ParseSchedule(schedule)
+	if err != nil {
+		return nil, err
+	}
+	if bytesPerSecond == 0 && len(windows) == 0 {
+		return nil, nil //nolint:nilnil // a nil limiter means unlimited
+	}
+	return NewScheduled(bytesPerSecond, windows), nil
+}
+
+// Rate returns the rate in bytes per second currently applied. Zero means unlimited.
+func (l *Limiter) Rate() int64 {
+	return max(l.schedule.RateAt(l.now(), l.rate), 0)
+}
+
+// WaitN blocks until n bytes can be transferred or ctx is done.
+// Requests larger than the bucket are allowed and paid back by the following ones.
+func (l *Limiter) WaitN(ctx context.Context, n int) error {
+	delay := l.reserve(n)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("rate limiter: %w", ctx.Err())
+	}
+}
+
+// reserve takes n tokens from the bucket and returns how long to wait for them.
+func (l *Limiter) reserve(n int) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	rate := l.schedule.RateAt(now, l.rate)
+	if rate <= 0 {
+		l.tokens, l.last = 0, now
+		return 0
+	}
+
+	burst := float64(rate)
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * float64(rate)
+	} else {
+		l.tokens = burst
+	}
+	l.tokens = min(l.tokens, burst)
+	l.last = now
+
+	l.tokens -= float64(n)
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / float64(rate) * float64(time.Second))
+}
+
+// Reader returns a reader limited by l. A nil limiter returns r unchanged.
+func (l *Limiter) Reader(ctx context.Context, r io.Reader) io.Reader {
+	if l == nil {
+		return r
+	}
+	return &reader{ctx: ctx, reader: r, limiter: l}
+}
+
+// reader is an io.Reader drawing its bandwidth from a Limiter.
+type reader struct {
+	ctx     context.Context //nolint:containedctx // the context of the transfer the reader belongs to
+	reader  io.Reader
+	limiter *Limiter
+}
+
+// Read implements io.Reader, waiting for the limiter after each read.
+func (r *reader) Read(p []byte) (int, error) {
+	if len(p) > maxReadSize {
+		p = p[:maxReadSize]
+	}
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		if waitErr := r.limiter.WaitN(r.ctx, n); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	// io.EOF must be returned unwrapped per io.Reader contract
+	if err != nil && !errors.Is(err, io.EOF) {
+		return n, fmt.Errorf("rate limited reader: %w", err)
+	}
+	return n, err //nolint:wrapcheck // io.EOF must be returned unwrapped per io.Reader contract
+}
@@ -0,0 +1,120 @@
+package ratelimit_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ephemeralfiles/eph/pkg/ratelimit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiterReader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("unlimited", func(t *testing.T) {
+		t.Parallel()
+		l := ratelimit.New(0)
+		start := time.Now()
+		n, err := io.Copy(io.Discard, l.Reader(context.Background(), bytes.NewReader(make([]byte, 1<<20))))
+		require.NoError(t, err)
+		assert.Equal(t, int64(1<<20), n)
+		assert.Less(t, time.Since(start), 100*time.Millisecond)
+	})
+
+	t.Run("limited", func(t *testing.T) {
+		t.Parallel()
+		// The first second worth of data is the burst, the rest is throttled
+		l := ratelimit.New(100 * 1024)
+		start := time.Now()
+		n, err := io.Copy(io.Discard, l.Reader(context.Background(), bytes.NewReader(make([]byte, 150*1024))))
+		require.NoError(t, err)
+		assert.Equal(t, int64(150*1024), n)
+		assert.GreaterOrEqual(t, time.Since(start), 400*time.Millisecond)
+	})
+
+	t.Run("shared between parallel readers", func(t *testing.T) {
+		t.Parallel()
+		l := ratelimit.New(100 * 1024)
+		start := time.Now()
+		var wg sync.WaitGroup
+		for range 3 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, _ = io.Copy(io.Discard, l.Reader(context.Background(), bytes.NewReader(make([]byte, 50*1024))))
+			}()
+		}
+		wg.Wait()
+		assert.GreaterOrEqual(t, time.Since(start), 400*time.Millisecond)
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		t.Parallel()
+		l := ratelimit.New(1024)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := io.Copy(io.Discard, l.Reader(ctx, bytes.NewReader(make([]byte, 64*1024))))
+		require.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("nil limiter", func(t *testing.T) {
+		t.Parallel()
+		var l *ratelimit.Limiter
+		r := bytes.NewReader(nil)
+		assert.Same(t, r, l.Reader(context.Background(), r))
+	})
+}
+
+func TestSchedule(t *testing.T) {
+	t.Parallel()
+
+	schedule, err := ratelimit.ParseSchedule([]string{"09:00-18:00=1M", "22:00-06:00=0"})
+	require.NoError(t, err)
+
+	at := func(hour, minute int) time.Time {
+		return time.Date(2026, 1, 5, hour, minute, 0, 0, time.Local)
+	}
+	assert.Equal(t, int64(1024*1024), schedule.RateAt(at(9, 0), 512))
+	assert.Equal(t, int64(1024*1024), schedule.RateAt(at(17, 59), 512))
+	assert.Equal(t, int64(512), schedule.RateAt(at(18, 0), 512))
+	assert.Equal(t, int64(0), schedule.RateAt(at(23, 30), 512))
+	assert.Equal(t, int64(0), schedule.RateAt(at(5, 0), 512))
+	assert.Equal(t, int64(512), schedule.RateAt(at(7, 0), 512))
+
+	for _, invalid := range []string{"09:00-18:00", "0900=1M", "09:00-25:00=1M", "09:00-18:00=fast"} {
+		_, err := ratelimit.ParseSchedule([]string{invalid})
+		require.ErrorIs(t, err, ratelimit.ErrInvalidSchedule, invalid)
+	}
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	l, err := ratelimit.Parse("", nil)
+	require.NoError(t, err)
+	assert.Nil(t, l)
+
+	l, err = ratelimit.Parse("0", nil)
+	require.NoError(t, err)
+	assert.Nil(t, l)
+
+	l, err = ratelimit.Parse("5M", nil)
+	require.NoError(t, err)
+	require.NotNil(t, l)
+	assert.Equal(t, int64(5*1024*1024), l.Rate())
+
+	l, err = ratelimit.Parse("", []string{"00:00-24:00=1K"})
+	require.NoError(t, err)
+	require.NotNil(t, l)
+	assert.Equal(t, int64(1024), l.Rate())
+
+	_, err = ratelimit.Parse("fast", nil)
+	require.Error(t, err)
+	_, err = ratelimit.Parse("5M", []string{"bad"})
+	require.ErrorIs(t, err, ratelimit.ErrInvalidSchedule)
+}
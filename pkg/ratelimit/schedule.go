@@ -0,0 +1,102 @@
+package ratelimit
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ephemeralfiles/eph/pkg/units"
+)
+
+const minutesPerDay = 24 * 60
+
+// ErrInvalidSchedule is returned when a schedule entry cannot be parsed.
+var ErrInvalidSchedule = errors.New("invalid rate schedule")
+
+// Window applies a rate during a time-of-day range, in local time.
+// A window where From is after To spans midnight.
+type Window struct {
+	// From is the start of the window, in minutes after midnight (inclusive).
+	From int
+	// To is the end of the window, in minutes after midnight (exclusive).
+	To int
+	// Rate is the rate in bytes per second during the window. Zero means unlimited.
+	Rate int64
+}
+
+// Schedule is a list of windows. The first window containing a time wins.
+type Schedule []Window
+
+// RateAt returns the rate applying at t, or defaultRate if no window contains t.
+func (s Schedule) RateAt(t time.Time, defaultRate int64) int64 {
+	minute := t.Hour()*60 + t.Minute()
+	for _, w := range s {
+		if w.contains(minute) {
+			return w.Rate
+		}
+	}
+	return defaultRate
+}
+
+// contains reports whether minute (after midnight) is inside the window.
+func (w Window) contains(minute int) bool {
+	if w.From <= w.To {
+		return minute >= w.From && minute < w.To
+	}
+	return minute >= w.From || minute < w.To
+}
+
+// ParseSchedule parses schedule entries of the form "HH:MM-HH:MM=RATE",
+// e.g. "09:00-18:00=2M". A rate of 0 means unlimited during the window.
+func ParseSchedule(entries []string) (Schedule, error) {
+	schedule := make(Schedule, 0, len(entries))
+	for _, entry := range entries {
+		w, err := parseWindow(entry)
+		if err != nil {
+			return nil, err
+		}
+		schedule = append(schedule, w)
+	}
+	return schedule, nil
+}
+
+// parseWindow parses a single "HH:MM-HH:MM=RATE" entry.
+func parseWindow(entry string) (Window, error) {
+	span, rate, ok := strings.Cut(entry, "=")
+	if !ok {
+		return Window{}, fmt.Errorf("%w: %q: missing rate", ErrInvalidSchedule, entry)
+	}
+	from, to, ok := strings.Cut(span, "-")
+	if !ok {
+		return Window{}, fmt.Errorf("%w: %q: missing time range", ErrInvalidSchedule, entry)
+	}
+
+	var (
+		w   Window
+		err error
+	)
+	if w.From, err = parseTimeOfDay(from); err != nil {
+		return Window{}, fmt.Errorf("%w: %q: %w", ErrInvalidSchedule, entry, err)
+	}
+	if w.To, err = parseTimeOfDay(to); err != nil {
+		return Window{}, fmt.Errorf("%w: %q: %w", ErrInvalidSchedule, entry, err)
+	}
+	if w.Rate, err = units.ParseSize(rate); err != nil {
+		return Window{}, fmt.Errorf("%w: %q: %w", ErrInvalidSchedule, entry, err)
+	}
+	return w, nil
+}
+
+// parseTimeOfDay parses "HH:MM" into minutes after midnight. "24:00" is accepted as the end of the day.
+func parseTimeOfDay(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	if s == "24:00" {
+		return minutesPerDay, nil
+	}
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q: %w", s, err)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
@@ -0,0 +1,657 @@
+package render
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// errJSONPath is returned for a JSONPath expression that cannot be parsed.
+var errJSONPath = errors.New("jsonpath")
+
+// ErrMissingKey is returned by a jsonpath output selecting a field or an index
+// the data does not have, unless Options.AllowMissing is set.
+var ErrMissingKey = errors.New("missing key")
+
+// jsonPath is a parsed JSONPath template, in the syntax of kubectl: text with
+// expressions between braces, e.g. {.[*].file_id} or
+// {range .[*]}{.filename}{"\t"}{.size}{"\n"}{end}.
+//
+// Expressions are a path from the root ($) or the current item (. or @) made of
+// fields (.name, ['name'] or ["name"]), wildcards (.* or [*]), indexes ([0], [-1]),
+// slices ([1:3]), unions ([0,2], ['file_id','size']), recursive descents
+// (..name) and filters ([?(@.size > 1024)], [?(@.filename == 'a.txt')]). The values of an expression
+// are separated by spaces; objects and arrays are printed as JSON.
+//
+// As with kubectl, a field or an index that selects nothing is an error, e.g.
+// {.[0].owner}, or {.tags} in a range over files without tags. Wildcards,
+// slices, unions, recursive descents and filters may select nothing.
+type jsonPath struct {
+	nodes []jsonPathNode
+}
+
+// jsonPathNode is a text, an expression, or a range over an expression.
+type jsonPathNode struct {
+	text     string
+	literal  bool
+	path     *pathExpr
+	isRange  bool
+	children []jsonPathNode
+}
+
+// pathExpr is a path expression.
+type pathExpr struct {
+	text     string
+	absolute bool
+	segments []pathSegment
+}
+
+// Kinds of path segments.
+const (
+	segmentField = iota
+	segmentWildcard
+	segmentIndex
+	segmentSlice
+	segmentRecursive
+	segmentFilter
+	segmentUnion
+)
+
+// pathSegment is a step of a path expression.
+type pathSegment struct {
+	kind       int
+	name       string
+	index      int
+	start, end *int
+	filter     *filterExpr
+	union      []pathSegment
+}
+
+// filterExpr is the condition of a filter: a path relative to the element, and
+// an optional comparison with a literal.
+type filterExpr struct {
+	path     *pathExpr
+	operator string
+	value    any
+}
+
+// parseJSONPath parses a JSONPath template.
+func parseJSONPath(text string) (*jsonPath, error) {
+	nodes, rest, err := parseJSONPathNodes(text, false)
+	if err != nil {
+		return nil, err
+	}
+	if rest != "" {
+		return nil, fmt.Errorf("%w: unexpected {end}", errJSONPath)
+	}
+	return &jsonPath{nodes: nodes}, nil
+}
+
+// parseJSONPathNodes parses text up to its end, or up to an {end} when inRange,
+// and returns the text following it.
+func parseJSONPathNodes(text string, inRange bool) ([]jsonPathNode, string, error) {
+	var nodes []jsonPathNode
+	for text != "" {
+		open := strings.IndexByte(text, '{')
+		if open < 0 {
+			nodes = append(nodes, jsonPathNode{text: text, literal: true})
+			text = ""
+			break
+		}
+		if open > 0 {
+			nodes = append(nodes, jsonPathNode{text: text[:open], literal: true})
+		}
+		closing := matchingBrace(text[open:])
+		if closing < 0 {
+			return nil, "", fmt.Errorf("%w: unclosed brace in %q", errJSONPath, text[open:])
+		}
+		expr := strings.TrimSpace(text[open+1 : open+closing])
+		text = text[open+closing+1:]
+
+		switch {
+		case expr == "end":
+			if !inRange {
+				return nil, "", fmt.Errorf("%w: {end} without {range}", errJSONPath)
+			}
+			return nodes, text, nil
+		case strings.HasPrefix(expr, "range "):
+			path, err := parsePathExpr(strings.TrimSpace(strings.TrimPrefix(expr, "range ")))
+			if err != nil {
+				return nil, "", err
+			}
+			children, rest, err := parseJSONPathNodes(text, true)
+			if err != nil {
+				return nil, "", err
+			}
+			nodes = append(nodes, jsonPathNode{path: path, isRange: true, children: children})
+			text = rest
+		case strings.HasPrefix(expr, `"`) || strings.HasPrefix(expr, "'"):
+			value, err := unquote(expr)
+			if err != nil {
+				return nil, "", err
+			}
+			nodes = append(nodes, jsonPathNode{text: value, literal: true})
+		default:
+			path, err := parsePathExpr(expr)
+			if err != nil {
+				return nil, "", err
+			}
+			nodes = append(nodes, jsonPathNode{path: path})
+		}
+	}
+	if inRange {
+		return nil, "", fmt.Errorf("%w: {range} without {end}", errJSONPath)
+	}
+	return nodes, "", nil
+}
+
+// matchingBrace returns the index of the brace closing the one text starts
+// with, ignoring braces in quoted strings, or -1.
+func matchingBrace(text string) int {
+	var quote byte
+	for i := 1; i < len(text); i++ {
+		switch c := text[i]; {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '}':
+			return i
+		}
+	}
+	return -1
+}
+
+// unquote returns the value of a string literal in double or single quotes.
+func unquote(literal string) (string, error) {
+	if strings.HasPrefix(literal, "'") {
+		if len(literal) < 2 || !strings.HasSuffix(literal, "'") {
+			return "", fmt.Errorf("%w: invalid string %s", errJSONPath, literal)
+		}
+		return literal[1 : len(literal)-1], nil
+	}
+	value, err := strconv.Unquote(literal)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid string %s", errJSONPath, literal)
+	}
+	return value, nil
+}
+
+// parsePathExpr parses a path expression.
+func parsePathExpr(expr string) (*pathExpr, error) {
+	path := &pathExpr{text: expr}
+	rest := expr
+	switch {
+	case strings.HasPrefix(rest, "$"):
+		path.absolute = true
+		rest = rest[1:]
+	case strings.HasPrefix(rest, "@"):
+		rest = rest[1:]
+	case !strings.HasPrefix(rest, ".") && !strings.HasPrefix(rest, "["):
+		return nil, fmt.Errorf("%w: invalid expression %q", errJSONPath, expr)
+	}
+
+	for rest != "" {
+		var segment pathSegment
+		var err error
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			segment.kind = segmentRecursive
+			segment.name, rest = cutName(rest[2:])
+			if segment.name == "" {
+				return nil, fmt.Errorf("%w: missing field after .. in %q", errJSONPath, expr)
+			}
+		case strings.HasPrefix(rest, ".*"):
+			segment.kind = segmentWildcard
+			rest = rest[2:]
+		case strings.HasPrefix(rest, "."):
+			segment.name, rest = cutName(rest[1:])
+			if segment.name == "" {
+				// A lone dot is the current item
+				continue
+			}
+		case strings.HasPrefix(rest, "["):
+			segment, rest, err = parseBracket(rest, expr)
+			if err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("%w: unexpected %q in %q", errJSONPath, rest, expr)
+		}
+		path.segments = append(path.segments, segment)
+	}
+	return path, nil
+}
+
+// cutName returns the field name text starts with, and the text following it.
+func cutName(text string) (string, string) {
+	end := strings.IndexFunc(text, func(r rune) bool {
+		return r == '.' || r == '[' || r == ' ' || r == '=' || r == '!' || r == '<' || r == '>' || r == ')'
+	})
+	if end < 0 {
+		return text, ""
+	}
+	return text[:end], text[end:]
+}
+
+// parseBracket parses the bracket segment text starts with, and returns the
+// text following it.
+func parseBracket(text, expr string) (pathSegment, string, error) {
+	closing := matchingBracket(text)
+	if closing < 0 {
+		return pathSegment{}, "", fmt.Errorf("%w: unclosed bracket in %q", errJSONPath, expr)
+	}
+	content := strings.TrimSpace(text[1:closing])
+	rest := text[closing+1:]
+
+	if parts := splitUnion(content); len(parts) > 1 && !strings.HasPrefix(content, "?(") {
+		union := make([]pathSegment, 0, len(parts))
+		for _, part := range parts {
+			segment, _, err := parseBracket("["+part+"]", expr)
+			if err != nil {
+				return pathSegment{}, "", err
+			}
+			union = append(union, segment)
+		}
+		return pathSegment{kind: segmentUnion, union: union}, rest, nil
+	}
+
+	switch {
+	case content == "*":
+		return pathSegment{kind: segmentWildcard}, rest, nil
+	case strings.HasPrefix(content, "'") || strings.HasPrefix(content, `"`):
+		name, err := unquote(content)
+		return pathSegment{kind: segmentField, name: name}, rest, err
+	case strings.HasPrefix(content, "?(") && strings.HasSuffix(content, ")"):
+		filter, err := parseFilter(strings.TrimSpace(content[2:len(content)-1]), expr)
+		return pathSegment{kind: segmentFilter, filter: filter}, rest, err
+	case strings.Contains(content, ":"):
+		first, second, _ := strings.Cut(content, ":")
+		start, err := parseBound(first)
+		if err != nil {
+			return pathSegment{}, "", fmt.Errorf("%w: invalid slice [%s] in %q", errJSONPath, content, expr)
+		}
+		end, err := parseBound(second)
+		if err != nil {
+			return pathSegment{}, "", fmt.Errorf("%w: invalid slice [%s] in %q", errJSONPath, content, expr)
+		}
+		return pathSegment{kind: segmentSlice, start: start, end: end}, rest, nil
+	default:
+		n, err := strconv.Atoi(content)
+		if err != nil {
+			return pathSegment{}, "", fmt.Errorf("%w: invalid index [%s] in %q", errJSONPath, content, expr)
+		}
+		return pathSegment{kind: segmentIndex, index: n}, rest, nil
+	}
+}
+
+// splitUnion splits the content of a bracket at the commas outside quoted
+// strings.
+func splitUnion(content string) []string {
+	var parts []string
+	var quote byte
+	start := 0
+	for i := 0; i < len(content); i++ {
+		switch c := content[i]; {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ',':
+			parts = append(parts, strings.TrimSpace(content[start:i]))
+			start = i + 1
+		}
+	}
+	return append(parts, strings.TrimSpace(content[start:]))
+}
+
+// parseBound parses a bound of a slice, nil when omitted.
+func parseBound(bound string) (*int, error) {
+	bound = strings.TrimSpace(bound)
+	if bound == "" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(bound)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bound: %w", err)
+	}
+	return &n, nil
+}
+
+// matchingBracket returns the index of the bracket closing the one text starts
+// with, ignoring brackets in quoted strings and nested brackets, or -1.
+func matchingBracket(text string) int {
+	var quote byte
+	depth := 0
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// filterOperators are the comparison operators of filters, longest first.
+var filterOperators = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+// parseFilter parses the condition of a filter, e.g. @.size > 1024.
+func parseFilter(condition, expr string) (*filterExpr, error) {
+	filter := &filterExpr{}
+	left := condition
+	for _, operator := range filterOperators {
+		if before, after, found := strings.Cut(condition, operator); found {
+			left = strings.TrimSpace(before)
+			filter.operator = operator
+			literal := strings.TrimSpace(after)
+			switch {
+			case strings.HasPrefix(literal, "'") || strings.HasPrefix(literal, `"`):
+				value, err := unquote(literal)
+				if err != nil {
+					return nil, err
+				}
+				filter.value = value
+			case literal == "true" || literal == "false":
+				filter.value = literal == "true"
+			default:
+				n, err := strconv.ParseFloat(literal, 64)
+				if err != nil {
+					return nil, fmt.Errorf("%w: invalid value %q in %q", errJSONPath, literal, expr)
+				}
+				filter.value = n
+			}
+			break
+		}
+	}
+	if !strings.HasPrefix(left, "@") {
+		return nil, fmt.Errorf("%w: filter %q must start with @", errJSONPath, condition)
+	}
+	path, err := parsePathExpr(left)
+	if err != nil {
+		return nil, err
+	}
+	filter.path = path
+	return filter, nil
+}
+
+// execute writes the template evaluated on the JSON value root. When strict, a
+// field or an index that selects nothing fails with ErrMissingKey.
+func (p *jsonPath) execute(w io.Writer, root any, strict bool) error {
+	return executeNodes(w, p.nodes, root, root, strict)
+}
+
+// executeNodes writes nodes evaluated with current as the current item.
+func executeNodes(w io.Writer, nodes []jsonPathNode, root, current any, strict bool) error {
+	for _, node := range nodes {
+		if node.literal {
+			if _, err := io.WriteString(w, node.text); err != nil {
+				return fmt.Errorf("error writing output: %w", err)
+			}
+			continue
+		}
+		values, err := node.path.evaluate(root, current, strict)
+		if err != nil {
+			return err
+		}
+		if node.isRange {
+			for _, value := range values {
+				if err := executeNodes(w, node.children, root, value, strict); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		texts := make([]string, 0, len(values))
+		for _, value := range values {
+			text, err := formatJSONValue(value)
+			if err != nil {
+				return err
+			}
+			texts = append(texts, text)
+		}
+		if _, err := io.WriteString(w, strings.Join(texts, " ")); err != nil {
+			return fmt.Errorf("error writing output: %w", err)
+		}
+	}
+	return nil
+}
+
+// formatJSONValue formats a value of an expression: strings and numbers as is,
+// objects and arrays as JSON.
+func formatJSONValue(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return "", fmt.Errorf("error encoding JSON: %w", err)
+		}
+		return string(encoded), nil
+	}
+}
+
+// evaluate returns the values of the path from root or current. When strict, a
+// field or an index selecting nothing in all the values it applies to fails
+// with ErrMissingKey.
+func (p *pathExpr) evaluate(root, current any, strict bool) ([]any, error) {
+	values := []any{current}
+	if p.absolute {
+		values = []any{root}
+	}
+	for _, segment := range p.segments {
+		var next []any
+		for _, value := range values {
+			next = append(next, segment.apply(value)...)
+		}
+		if strict && len(values) > 0 && len(next) == 0 {
+			switch segment.kind {
+			case segmentField:
+				return nil, fmt.Errorf("%w: field %q not found in %s", ErrMissingKey, segment.name, p.text)
+			case segmentIndex:
+				return nil, fmt.Errorf("%w: index [%d] out of range in %s", ErrMissingKey, segment.index, p.text)
+			}
+		}
+		values = next
+	}
+	return values, nil
+}
+
+// apply returns the values the segment selects in value.
+func (s pathSegment) apply(value any) []any {
+	switch s.kind {
+	case segmentField:
+		if object, ok := value.(map[string]any); ok {
+			if field, ok := object[s.name]; ok {
+				return []any{field}
+			}
+		}
+	case segmentWildcard:
+		switch v := value.(type) {
+		case []any:
+			return v
+		case map[string]any:
+			keys := make([]string, 0, len(v))
+			for key := range v {
+				keys = append(keys, key)
+			}
+			slices.Sort(keys)
+			values := make([]any, len(keys))
+			for i, key := range keys {
+				values[i] = v[key]
+			}
+			return values
+		}
+	case segmentIndex:
+		if array, ok := value.([]any); ok {
+			index := s.index
+			if index < 0 {
+				index += len(array)
+			}
+			if index >= 0 && index < len(array) {
+				return []any{array[index]}
+			}
+		}
+	case segmentSlice:
+		if array, ok := value.([]any); ok {
+			start, end := 0, len(array)
+			if s.start != nil {
+				start = clampIndex(*s.start, len(array))
+			}
+			if s.end != nil {
+				end = clampIndex(*s.end, len(array))
+			}
+			if start < end {
+				return array[start:end]
+			}
+		}
+	case segmentRecursive:
+		return descendants(value, s.name)
+	case segmentUnion:
+		var values []any
+		for _, segment := range s.union {
+			values = append(values, segment.apply(value)...)
+		}
+		return values
+	case segmentFilter:
+		if array, ok := value.([]any); ok {
+			var values []any
+			for _, element := range array {
+				if s.filter.match(element) {
+					values = append(values, element)
+				}
+			}
+			return values
+		}
+	}
+	return nil
+}
+
+// clampIndex returns a slice bound of an array of length n, counting negative
+// bounds from the end.
+func clampIndex(bound, n int) int {
+	if bound < 0 {
+		bound += n
+	}
+	return max(0, min(bound, n))
+}
+
+// descendants returns the values of the fields named name in value and in all
+// its descendants.
+func descendants(value any, name string) []any {
+	var values []any
+	switch v := value.(type) {
+	case map[string]any:
+		if field, ok := v[name]; ok {
+			values = append(values, field)
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			values = append(values, descendants(v[key], name)...)
+		}
+	case []any:
+		for _, element := range v {
+			values = append(values, descendants(element, name)...)
+		}
+	}
+	return values
+}
+
+// match reports whether element satisfies the filter.
+func (f *filterExpr) match(element any) bool {
+	// A missing field does not match rather than failing
+	values, _ := f.path.evaluate(element, element, false)
+	if f.operator == "" {
+		return len(values) > 0 && values[0] != nil && values[0] != false
+	}
+	if len(values) == 0 {
+		return f.operator == "!="
+	}
+	comparison, ok := compareJSON(values[0], f.value)
+	if !ok {
+		return f.operator == "!="
+	}
+	switch f.operator {
+	case "==":
+		return comparison == 0
+	case "!=":
+		return comparison != 0
+	case "<":
+		return comparison < 0
+	case "<=":
+		return comparison <= 0
+	case ">":
+		return comparison > 0
+	default:
+		return comparison >= 0
+	}
+}
+
+// compareJSON compares a JSON value with a literal of a filter, and reports
+// whether they are comparable.
+func compareJSON(value, literal any) (int, bool) {
+	switch l := literal.(type) {
+	case string:
+		if v, ok := value.(string); ok {
+			return strings.Compare(v, l), true
+		}
+	case bool:
+		if v, ok := value.(bool); ok {
+			if v == l {
+				return 0, true
+			}
+			return 1, true
+		}
+	case float64:
+		if v, ok := value.(json.Number); ok {
+			n, err := v.Float64()
+			if err != nil {
+				return 0, false
+			}
+			switch {
+			case n < l:
+				return -1, true
+			case n > l:
+				return 1, true
+			default:
+				return 0, true
+			}
+		}
+	}
+	return 0, false
+}
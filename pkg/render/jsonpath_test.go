@@ -0,0 +1,296 @@
+package render_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ephemeralfiles/eph/pkg/render"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// folder is a nested DTO, for recursive descents.
+type folder struct {
+	Name    string       `json:"name"`
+	Shared  bool         `json:"shared"`
+	Files   []taggedFile `json:"files,omitempty"`
+	Folders []folder     `json:"folders,omitempty"`
+}
+
+var folderColumns = []render.Column[folder]{
+	{Name: "Name", Value: func(f folder) string { return f.Name }},
+}
+
+func TestJSONPath(t *testing.T) {
+	t.Parallel()
+
+	items := []taggedFile{
+		{FileID: "id-1", Filename: "report.pdf", Size: 1258291, Tags: []string{"invoice", "q1"}},
+		{FileID: "id-2", Filename: "notes.md", Size: 1600},
+		{FileID: "id-3", Filename: "a.txt", Size: 6, Tags: []string{"misc"}},
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		// Text and literals
+		{name: "text only", path: "files", expected: "files"},
+		{name: "text around an expression", path: "first: {.[0].file_id}.", expected: "first: id-1."},
+		{name: "double-quoted literal", path: `{"a\tb\n"}`, expected: "a\tb\n"},
+		{name: "single-quoted literal", path: "{'a b'}", expected: "a b"},
+		{name: "brace in a literal", path: `{"{"}{.[0].size}{"}"}`, expected: "{1258291}"},
+		{name: "spaces in braces", path: "{ .[0].file_id }", expected: "id-1"},
+
+		// Roots
+		{name: "current", path: "{.[0].file_id}", expected: "id-1"},
+		{name: "current with @", path: "{@[0].file_id}", expected: "id-1"},
+		{name: "root", path: "{$[*].size}", expected: "1258291 1600 6"},
+		{name: "whole list as JSON", path: "{.[*].tags}", expected: `["invoice","q1"] ["misc"]`},
+
+		// Fields
+		{name: "bracket field", path: "{.[0]['filename']}", expected: "report.pdf"},
+		{name: "double-quoted bracket field", path: `{.[0]["file_id"]}`, expected: "id-1"},
+		{name: "double-quoted bracket field of each item", path: `{.[*]["file_id"]}`, expected: "id-1 id-2 id-3"},
+		{name: "nested", path: "{.[0].tags[1]}", expected: "q1"},
+		{name: "array as JSON", path: "{.[0].tags}", expected: `["invoice","q1"]`},
+
+		// Wildcards
+		{name: "wildcard", path: "{.[*].file_id}", expected: "id-1 id-2 id-3"},
+		{name: "dot wildcard", path: "{.[0].tags.*}", expected: "invoice q1"},
+		{
+			name:     "object wildcard sorted by key",
+			path:     "{.[1].*}",
+			expected: "0001-01-01T00:00:00Z id-2 notes.md 1600",
+		},
+
+		// Indexes and slices
+		{name: "index", path: "{.[0].filename} and {.[-1].filename}", expected: "report.pdf and a.txt"},
+		{name: "slice", path: "{.[1:].file_id}", expected: "id-2 id-3"},
+		{name: "slice with an end", path: "{.[:1].file_id}", expected: "id-1"},
+		{name: "slice with both bounds", path: "{.[1:2].file_id}", expected: "id-2"},
+		{name: "slice from the end", path: "{.[-2:].file_id}", expected: "id-2 id-3"},
+		{name: "slice to the end", path: "{.[0:-1].file_id}", expected: "id-1 id-2"},
+		{name: "slice out of range", path: "{.[5:].file_id}", expected: ""},
+		{name: "empty slice", path: "{.[2:1].file_id}", expected: ""},
+
+		// Unions
+		{name: "index union", path: "{.[0,2].file_id}", expected: "id-1 id-3"},
+		{name: "field union", path: "{.[0]['file_id','size']}", expected: "id-1 1258291"},
+		{name: "field union with a comma in a name", path: `{.[0]["file_id", "a,b"]}`, expected: "id-1"},
+
+		// Recursive descents
+		{name: "recursive", path: "{..tags[0]}", expected: "invoice misc"},
+		{name: "recursive without match", path: "{..owner}", expected: ""},
+
+		// Filters
+		{name: "string filter", path: "{.[?(@.filename == 'notes.md')].file_id}", expected: "id-2"},
+		{name: "double-quoted string filter", path: `{.[?(@.filename != "notes.md")].file_id}`, expected: "id-1 id-3"},
+		{name: "greater than filter", path: "{.[?(@.size > 1024)].file_id}", expected: "id-1 id-2"},
+		{name: "greater or equal filter", path: "{.[?(@.size >= 1600)].file_id}", expected: "id-1 id-2"},
+		{name: "less than filter", path: "{.[?(@.size < 1600)].file_id}", expected: "id-3"},
+		{name: "less or equal filter", path: "{.[?(@.size <= 1600)].file_id}", expected: "id-2 id-3"},
+		{name: "string order filter", path: "{.[?(@.filename < 'b')].file_id}", expected: "id-3"},
+		{name: "existence filter", path: "{.[?(@.tags)].file_id}", expected: "id-1 id-3"},
+		{name: "nested filter", path: "{.[?(@.tags[0] == 'misc')].file_id}", expected: "id-3"},
+		{name: "filter without match", path: "{.[?(@.size > 1e9)].file_id}", expected: ""},
+		{name: "filter on a missing field", path: "{.[?(@.owner == 'me')].file_id}", expected: ""},
+		{name: "not equal filter on a missing field", path: "{.[?(@.owner != 'me')].file_id}", expected: "id-1 id-2 id-3"},
+		{name: "filter on a mismatched type", path: "{.[?(@.size == 'big')].file_id}", expected: ""},
+
+		// Ranges
+		{
+			name:     "range",
+			path:     `{range .[*]}{.file_id}{"\t"}{.size}{"\n"}{end}`,
+			expected: "id-1\t1258291\nid-2\t1600\nid-3\t6\n",
+		},
+		{name: "range over a filter", path: `{range .[?(@.tags)]}{.filename};{end}`, expected: "report.pdf;a.txt;"},
+		{name: "nested range", path: `{range .[?(@.tags)]}{range .tags[*]}<{.}>{end}{end}`, expected: "<invoice><q1><misc>"},
+		{name: "root in a range", path: "{range .[1:]}{$[0].file_id}{end}", expected: "id-1id-1"},
+		{name: "empty range", path: "{range .[?(@.size > 1e9)]}{.file_id}{end}", expected: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var buf bytes.Buffer
+			require.NoError(t, render.List(&buf, items, taggedColumns, render.Options{Format: "jsonpath=" + tt.path}))
+			assert.Equal(t, tt.expected, buf.String())
+		})
+	}
+}
+
+func TestJSONPathItem(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	item := taggedFile{FileID: "id-1", Tags: []string{"a", "b"}}
+	require.NoError(t, render.Item(&buf, item, taggedColumns, render.Options{Format: "jsonpath={.file_id}: {.tags[*]}"}))
+	assert.Equal(t, "id-1: a b", buf.String())
+
+	buf.Reset()
+	require.NoError(t, render.Item(&buf, item, taggedColumns, render.Options{Format: `jsonpath={.["file_id"]}`}))
+	assert.Equal(t, "id-1", buf.String())
+}
+
+func TestJSONPathRecursive(t *testing.T) {
+	t.Parallel()
+
+	root := folder{
+		Name:   "root",
+		Shared: true,
+		Files:  []taggedFile{{Filename: "a.txt"}},
+		Folders: []folder{
+			{Name: "docs", Files: []taggedFile{{Filename: "b.md"}, {Filename: "c.md"}}},
+			{Name: "empty", Shared: true, Folders: []folder{{Name: "deep", Files: []taggedFile{{Filename: "d.txt"}}}}},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{name: "all descendants", path: "{..filename}", expected: "a.txt b.md c.md d.txt"},
+		{name: "descendants of a field", path: "{.folders..name}", expected: "docs empty deep"},
+		{name: "descendants then index", path: "{..files[0].filename}", expected: "a.txt b.md d.txt"},
+		{name: "boolean filter", path: "{.folders[?(@.shared == true)].name}", expected: "empty"},
+		{name: "false filter", path: "{.folders[?(@.shared == false)].name}", expected: "docs"},
+		{name: "existence filter on a boolean", path: "{.folders[?(@.shared)].name}", expected: "empty"},
+		{name: "nested wildcards", path: "{.folders[*].files[*].filename}", expected: "b.md c.md"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var buf bytes.Buffer
+			require.NoError(t, render.Item(&buf, root, folderColumns, render.Options{Format: "jsonpath=" + tt.path}))
+			assert.Equal(t, tt.expected, buf.String())
+		})
+	}
+}
+
+func TestJSONPathMissing(t *testing.T) {
+	t.Parallel()
+
+	items := []taggedFile{
+		{FileID: "id-1", Tags: []string{"invoice"}},
+		{FileID: "id-2"},
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		message  string
+		expected string
+	}{
+		{
+			name:    "field",
+			path:    "{.[0].owner}",
+			message: `field "owner" not found in .[0].owner`,
+		},
+		{
+			name:    "field of every item",
+			path:    "{.[*].owner}",
+			message: `field "owner" not found in .[*].owner`,
+		},
+		{
+			name:    "field of a list",
+			path:    "{.file_id}",
+			message: `field "file_id" not found in .file_id`,
+		},
+		{
+			name:    "bracket field",
+			path:    "{.[0]['owner']}",
+			message: `field "owner" not found in .[0]['owner']`,
+		},
+		{
+			name:     "field in a range",
+			path:     `{range .[*]}{.file_id}:{.tags};{end}`,
+			message:  `field "tags" not found in .tags`,
+			expected: `id-1:["invoice"];id-2:;`,
+		},
+		{
+			name:    "index",
+			path:    "{.[2].file_id}",
+			message: "index [2] out of range in .[2].file_id",
+		},
+		{
+			name:     "negative index",
+			path:     "before {.[-3].file_id} after",
+			message:  "index [-3] out of range in .[-3].file_id",
+			expected: "before  after",
+		},
+		{
+			name:    "index of a field",
+			path:    "{.[0].tags[1]}",
+			message: "index [1] out of range in .[0].tags[1]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var buf bytes.Buffer
+			err := render.List(&buf, items, taggedColumns, render.Options{Format: "jsonpath=" + tt.path})
+			require.ErrorIs(t, err, render.ErrMissingKey)
+			require.ErrorIs(t, err, render.ErrInvalidTemplate)
+			assert.Contains(t, err.Error(), tt.message)
+			assert.Empty(t, buf.String(), "nothing is written on an error")
+
+			buf.Reset()
+			opts := render.Options{Format: "jsonpath=" + tt.path, AllowMissing: true}
+			require.NoError(t, render.List(&buf, items, taggedColumns, opts))
+			assert.Equal(t, tt.expected, buf.String())
+		})
+	}
+
+	t.Run("empty list", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		require.NoError(t, render.List(&buf, []taggedFile(nil), taggedColumns, render.Options{Format: "jsonpath={.[*].file_id}"}))
+		assert.Empty(t, buf.String())
+	})
+}
+
+func TestJSONPathMalformed(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		path    string
+		message string
+	}{
+		{name: "unclosed brace", path: "{.[*].file_id", message: `unclosed brace in "{.[*].file_id"`},
+		{name: "unclosed brace after text", path: "id: {.file_id", message: `unclosed brace in "{.file_id"`},
+		{name: "unclosed bracket", path: "{.[0.file_id}", message: `unclosed bracket in ".[0.file_id"`},
+		{name: "unclosed filter", path: "{.[?(@.size > 1)}", message: `unclosed bracket in ".[?(@.size > 1)"`},
+		{name: "end without range", path: "{.file_id}{end}", message: "{end} without {range}"},
+		{name: "range without end", path: "{range .[*]}{.file_id}", message: "{range} without {end}"},
+		{name: "invalid range path", path: "{range file_id}{end}", message: `invalid expression "file_id"`},
+		{name: "error in a range", path: "{range .[*]}{.[x]}{end}", message: `invalid index [x] in ".[x]"`},
+		{name: "no root", path: "{file_id}", message: `invalid expression "file_id"`},
+		{name: "unexpected text", path: "{.file_id size}", message: `unexpected " size" in ".file_id size"`},
+		{name: "invalid index", path: "{.[abc]}", message: `invalid index [abc] in ".[abc]"`},
+		{name: "empty index", path: "{.[]}", message: `invalid index [] in ".[]"`},
+		{name: "invalid slice start", path: "{.[a:2]}", message: `invalid slice [a:2] in ".[a:2]"`},
+		{name: "invalid slice end", path: "{.[1:b]}", message: `invalid slice [1:b] in ".[1:b]"`},
+		{name: "invalid union", path: "{.[0,x]}", message: `invalid index [x] in ".[0,x]"`},
+		{name: "empty union part", path: "{.[0,]}", message: `invalid index [] in ".[0,]"`},
+		{name: "recursive descent without field", path: "{..}", message: `missing field after .. in ".."`},
+		{name: "filter without @", path: "{.[?(size > 1)]}", message: `filter "size > 1" must start with @`},
+		{name: "invalid filter value", path: "{.[?(@.size > big)]}", message: `invalid value "big" in ".[?(@.size > big)]"`},
+		{name: "unclosed filter string", path: "{.[?(@.filename == 'a)]}", message: "unclosed brace"},
+		{name: "invalid filter string", path: `{.[?(@.filename == "a\q")]}`, message: `invalid string "a\q"`},
+		{name: "unclosed literal", path: `{"abc}`, message: `unclosed brace in "{\"abc}"`},
+		{name: "invalid literal", path: `{"a\q"}`, message: `invalid string "a\q"`},
+		{name: "unclosed bracket string", path: "{.['a]}", message: `unclosed brace in "{.['a]}"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			opts := render.Options{Format: "jsonpath=" + tt.path}
+			err := opts.Validate()
+			require.ErrorIs(t, err, render.ErrInvalidTemplate)
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+}
@@ -0,0 +1,323 @@
+// Package render prints lists and single items of DTOs in the output formats
+// shared by the commands: table, JSON, NDJSON, CSV, YAML and markdown. Tabular
+// formats are built from columns, which can be selected by name. The output can
+// also be formatted with a Go template or a JSONPath template.
+package render
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pterm/pterm"
+	"gopkg.in/yaml.v2"
+)
+
+// Output formats.
+const (
+	FormatTable    = "table"
+	FormatJSON     = "json"
+	FormatNDJSON   = "ndjson"
+	FormatCSV      = "csv"
+	FormatYAML     = "yaml"
+	FormatMarkdown = "markdown"
+)
+
+// Formats lists the output formats.
+var Formats = []string{FormatTable, FormatJSON, FormatNDJSON, FormatCSV, FormatYAML, FormatMarkdown}
+
+var (
+	// ErrUnknownFormat is returned for an output format that is not supported.
+	ErrUnknownFormat = errors.New("unknown output format")
+	// ErrUnknownColumn is returned when a selected column does not exist.
+	ErrUnknownColumn = errors.New("unknown column")
+)
+
+// Column is a column of the tabular formats.
+type Column[T any] struct {
+	// Name is the name of the column, upper-cased in table headers.
+	Name string
+	// Value returns the cell of an item.
+	Value func(T) string
+	// Extra columns are only printed when selected.
+	Extra bool
+}
+
+// Options selects the format and the columns of an output.
+type Options struct {
+	// Format is one of Formats, table by default, or a template format followed
+	// by "=" and the template, e.g. jsonpath={.[*].file_id}.
+	Format string
+	// Columns are the names of the columns printed by the tabular formats
+	// (table, CSV, markdown), in order. Names are matched ignoring case, spaces,
+	// dashes and underscores. The columns that are not Extra are printed by default.
+	Columns []string
+	// AllowMissing prints nothing for a field or an index a jsonpath output
+	// does not find, instead of failing with ErrMissingKey.
+	AllowMissing bool
+}
+
+// Validate checks the format of the options, and parses its template.
+func (o Options) Validate() error {
+	_, err := o.template()
+	return err
+}
+
+// template returns the executor of a template format, or nil for the other
+// formats.
+func (o Options) template() (templateExecutor, error) {
+	if o.Format == "" {
+		return nil, nil
+	}
+	for _, format := range Formats {
+		if o.Format == format {
+			return nil, nil
+		}
+	}
+	executor, err := parseTemplateFormat(o.Format, o.AllowMissing)
+	if err != nil {
+		return nil, err
+	}
+	if executor == nil {
+		return nil, fmt.Errorf("%w %q, expected one of %s, %s=TEMPLATE or %s=TEMPLATE", ErrUnknownFormat,
+			o.Format, strings.Join(Formats, ", "), FormatGoTemplate, FormatJSONPath)
+	}
+	return executor, nil
+}
+
+// IsTable reports whether the output is a table, meant for humans.
+func (o Options) IsTable() bool {
+	return o.Format == "" || o.Format == FormatTable
+}
+
+// List prints items to w.
+func List[T any](w io.Writer, items []T, columns []Column[T], opts Options) error {
+	executor, err := opts.template()
+	if err != nil {
+		return err
+	}
+	selected, err := selectColumns(columns, opts.Columns)
+	if err != nil {
+		return err
+	}
+	if items == nil {
+		// Encoded as an empty list rather than null
+		items = []T{}
+	}
+	if executor != nil {
+		return executor(w, items)
+	}
+
+	switch opts.Format {
+	case FormatJSON:
+		return writeJSON(w, items)
+	case FormatNDJSON:
+		for _, item := range items {
+			if err := writeNDJSON(w, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	case FormatYAML:
+		return writeYAML(w, items)
+	case FormatCSV:
+		return writeCSV(w, selected, items)
+	case FormatMarkdown:
+		return writeMarkdown(w, selected, items)
+	default:
+		data := pterm.TableData{headers(selected, strings.ToUpper)}
+		for _, item := range items {
+			data = append(data, cells(selected, item))
+		}
+		if err := pterm.DefaultTable.WithHasHeader().WithData(data).WithWriter(w).Render(); err != nil {
+			return fmt.Errorf("error rendering table: %w", err)
+		}
+		return nil
+	}
+}
+
+// Item prints a single item to w. The table format prints one line per column,
+// empty values omitted; JSON and YAML print the item as an object.
+func Item[T any](w io.Writer, item T, columns []Column[T], opts Options) error {
+	executor, err := opts.template()
+	if err != nil {
+		return err
+	}
+	selected, err := selectColumns(columns, opts.Columns)
+	if err != nil {
+		return err
+	}
+	if executor != nil {
+		return executor(w, item)
+	}
+
+	switch opts.Format {
+	case FormatJSON:
+		return writeJSON(w, item)
+	case FormatNDJSON:
+		return writeNDJSON(w, item)
+	case FormatYAML:
+		return writeYAML(w, item)
+	case FormatCSV:
+		return writeCSV(w, selected, []T{item})
+	case FormatMarkdown:
+		return writeMarkdown(w, selected, []T{item})
+	default:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0) //nolint:mnd // column padding
+		for _, column := range selected {
+			if value := column.Value(item); value != "" {
+				fmt.Fprintf(tw, "%s:\t%s\n", column.Name, value)
+			}
+		}
+		if err := tw.Flush(); err != nil {
+			return fmt.Errorf("error writing table: %w", err)
+		}
+		return nil
+	}
+}
+
+// selectColumns returns the columns named by names, or the default columns
+// when names is empty.
+func selectColumns[T any](columns []Column[T], names []string) ([]Column[T], error) {
+	if len(names) == 0 {
+		var selected []Column[T]
+		for _, column := range columns {
+			if !column.Extra {
+				selected = append(selected, column)
+			}
+		}
+		return selected, nil
+	}
+
+	selected := make([]Column[T], 0, len(names))
+	for _, name := range names {
+		found := false
+		for _, column := range columns {
+			if columnKey(column.Name) == columnKey(name) {
+				selected = append(selected, column)
+				found = true
+				break
+			}
+		}
+		if !found {
+			available := make([]string, 0, len(columns))
+			for _, column := range columns {
+				available = append(available, strings.ReplaceAll(strings.ToLower(column.Name), " ", "-"))
+			}
+			return nil, fmt.Errorf("%w %q, expected one of %s", ErrUnknownColumn, name, strings.Join(available, ", "))
+		}
+	}
+	return selected, nil
+}
+
+// columnKey returns the normalized name of a column: "File ID", "file-id" and
+// "FILE_ID" are all "fileid".
+func columnKey(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '_':
+			return -1
+		}
+		return r
+	}, strings.ToLower(strings.TrimSpace(name)))
+}
+
+// headers returns the names of columns, transformed by format.
+func headers[T any](columns []Column[T], format func(string) string) []string {
+	names := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = format(column.Name)
+	}
+	return names
+}
+
+// cells returns the values of the columns for item.
+func cells[T any](columns []Column[T], item T) []string {
+	values := make([]string, len(columns))
+	for i, column := range columns {
+		values[i] = column.Value(item)
+	}
+	return values
+}
+
+// writeJSON writes v as indented JSON.
+func writeJSON(w io.Writer, v any) error {
+	output, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding JSON: %w", err)
+	}
+	if _, err := fmt.Fprintln(w, string(output)); err != nil {
+		return fmt.Errorf("error writing JSON: %w", err)
+	}
+	return nil
+}
+
+// writeNDJSON writes v as JSON on a single line.
+func writeNDJSON(w io.Writer, v any) error {
+	output, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("error encoding JSON: %w", err)
+	}
+	if _, err := fmt.Fprintln(w, string(output)); err != nil {
+		return fmt.Errorf("error writing JSON: %w", err)
+	}
+	return nil
+}
+
+// writeYAML writes v as YAML.
+func writeYAML(w io.Writer, v any) error {
+	output, err := yaml.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("error encoding YAML: %w", err)
+	}
+	if _, err := w.Write(output); err != nil {
+		return fmt.Errorf("error writing YAML: %w", err)
+	}
+	return nil
+}
+
+// writeCSV writes the columns of items as CSV, with a header line.
+func writeCSV[T any](w io.Writer, columns []Column[T], items []T) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(headers(columns, strings.ToUpper)); err != nil {
+		return fmt.Errorf("error writing CSV: %w", err)
+	}
+	for _, item := range items {
+		if err := writer.Write(cells(columns, item)); err != nil {
+			return fmt.Errorf("error writing CSV: %w", err)
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("error writing CSV: %w", err)
+	}
+	return nil
+}
+
+// writeMarkdown writes the columns of items as a markdown table.
+func writeMarkdown[T any](w io.Writer, columns []Column[T], items []T) error {
+	var b strings.Builder
+	writeRow := func(values []string) {
+		b.WriteString("|")
+		for _, value := range values {
+			b.WriteString(" " + markdownEscaper.Replace(value) + " |")
+		}
+		b.WriteString("\n")
+	}
+	writeRow(headers(columns, func(name string) string { return name }))
+	b.WriteString("|" + strings.Repeat(" --- |", len(columns)) + "\n")
+	for _, item := range items {
+		writeRow(cells(columns, item))
+	}
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("error writing markdown: %w", err)
+	}
+	return nil
+}
+
+// markdownEscaper escapes the characters breaking a markdown table cell.
+var markdownEscaper = strings.NewReplacer("|", `\|`, "\n", " ", "\r", "")
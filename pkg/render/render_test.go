@@ -0,0 +1,132 @@
+package render_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ephemeralfiles/eph/pkg/render"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type file struct {
+	ID   string `json:"id"   yaml:"id"`
+	Name string `json:"name" yaml:"name"`
+	Size int64  `json:"size" yaml:"size"`
+}
+
+var fileColumns = []render.Column[file]{
+	{Name: "ID", Value: func(f file) string { return f.ID }},
+	{Name: "File Name", Value: func(f file) string { return f.Name }},
+	{Name: "Size", Value: func(f file) string { return strconv.FormatInt(f.Size, 10) }, Extra: true},
+}
+
+var files = []file{
+	{ID: "id-1", Name: "report.pdf", Size: 42},
+	{ID: "id-2", Name: "a | b", Size: 7},
+}
+
+func TestList(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		opts     render.Options
+		expected string
+	}{
+		{
+			name:     "json",
+			opts:     render.Options{Format: render.FormatJSON},
+			expected: "[\n  {\n    \"id\": \"id-1\",\n    \"name\": \"report.pdf\",\n    \"size\": 42\n  },\n  {\n    \"id\": \"id-2\",\n    \"name\": \"a | b\",\n    \"size\": 7\n  }\n]\n",
+		},
+		{
+			name:     "ndjson",
+			opts:     render.Options{Format: render.FormatNDJSON},
+			expected: "{\"id\":\"id-1\",\"name\":\"report.pdf\",\"size\":42}\n{\"id\":\"id-2\",\"name\":\"a | b\",\"size\":7}\n",
+		},
+		{
+			name:     "yaml",
+			opts:     render.Options{Format: render.FormatYAML},
+			expected: "- id: id-1\n  name: report.pdf\n  size: 42\n- id: id-2\n  name: a | b\n  size: 7\n",
+		},
+		{
+			name:     "csv with default columns",
+			opts:     render.Options{Format: render.FormatCSV},
+			expected: "ID,FILE NAME\nid-1,report.pdf\nid-2,a | b\n",
+		},
+		{
+			name:     "csv with selected columns",
+			opts:     render.Options{Format: render.FormatCSV, Columns: []string{"size", "file-name"}},
+			expected: "SIZE,FILE NAME\n42,report.pdf\n7,a | b\n",
+		},
+		{
+			name:     "markdown",
+			opts:     render.Options{Format: render.FormatMarkdown, Columns: []string{"ID", "FILE_NAME"}},
+			expected: "| ID | File Name |\n| --- | --- |\n| id-1 | report.pdf |\n| id-2 | a \\| b |\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var buf bytes.Buffer
+			require.NoError(t, render.List(&buf, files, fileColumns, tt.opts))
+			assert.Equal(t, tt.expected, buf.String())
+		})
+	}
+}
+
+func TestListTable(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	require.NoError(t, render.List(&buf, files, fileColumns, render.Options{}))
+	output := buf.String()
+	assert.Contains(t, output, "FILE NAME")
+	assert.Contains(t, output, "report.pdf")
+	assert.NotContains(t, output, "SIZE")
+}
+
+func TestListEmpty(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	require.NoError(t, render.List(&buf, nil, fileColumns, render.Options{Format: render.FormatJSON}))
+	assert.Equal(t, "[]\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, render.List(&buf, nil, fileColumns, render.Options{Format: render.FormatNDJSON}))
+	assert.Empty(t, buf.String())
+}
+
+func TestItem(t *testing.T) {
+	t.Parallel()
+
+	item := file{ID: "id-1", Size: 42}
+
+	var buf bytes.Buffer
+	require.NoError(t, render.Item(&buf, item, fileColumns, render.Options{Columns: []string{"id", "file name", "size"}}))
+	// Empty values are omitted from the table
+	assert.Equal(t, "ID:    id-1\nSize:  42\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, render.Item(&buf, item, fileColumns, render.Options{Format: render.FormatJSON}))
+	var decoded file
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, item, decoded)
+}
+
+func TestErrors(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	err := render.List(&buf, files, fileColumns, render.Options{Format: "xml"})
+	require.ErrorIs(t, err, render.ErrUnknownFormat)
+
+	err = render.List(&buf, files, fileColumns, render.Options{Columns: []string{"owner"}})
+	require.ErrorIs(t, err, render.ErrUnknownColumn)
+	assert.True(t, strings.Contains(err.Error(), "id, file-name, size"), err.Error())
+	assert.Empty(t, buf.String())
+}
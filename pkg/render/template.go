@@ -0,0 +1,197 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/ephemeralfiles/eph/pkg/units"
+)
+
+// Template formats, followed by "=" and the template or the path of its file,
+// e.g. go-template={{range .}}{{.FileID}}{{"\n"}}{{end}} or jsonpath={.[*].file_id}.
+const (
+	FormatGoTemplate     = "go-template"
+	FormatGoTemplateFile = "go-template-file"
+	FormatJSONPath       = "jsonpath"
+	FormatJSONPathFile   = "jsonpath-file"
+)
+
+// ErrInvalidTemplate is returned for a go-template or jsonpath output that
+// cannot be parsed or executed.
+var ErrInvalidTemplate = errors.New("invalid template")
+
+// errUnsupportedType is returned by a template function called with an argument
+// of the wrong type.
+var errUnsupportedType = errors.New("unsupported type")
+
+// TemplateFuncs are the functions available to go-template outputs, besides the
+// builtin functions of text/template:
+//
+//	humanSize 1258291                 → 1.2 MB
+//	relativeTime .ExpirationDate      → in 6 days, 3 hours ago
+//	join ", " .Tags                   → invoice, q1
+var TemplateFuncs = template.FuncMap{
+	"humanSize":    humanSize,
+	"relativeTime": relativeTime,
+	"join":         join,
+}
+
+// templateExecutor writes data, an item or a slice of items, with a template.
+type templateExecutor func(w io.Writer, data any) error
+
+// parseTemplateFormat returns the executor of a template format, or nil when
+// format is not a template format. Unless allowMissing, a jsonpath output fails
+// on a field or an index it does not find.
+func parseTemplateFormat(format string, allowMissing bool) (templateExecutor, error) {
+	name, text, ok := strings.Cut(format, "=")
+	if !ok {
+		return nil, nil
+	}
+	switch name {
+	case FormatGoTemplateFile, FormatJSONPathFile:
+		content, err := os.ReadFile(text)
+		if err != nil {
+			return nil, fmt.Errorf("error reading template: %w", err)
+		}
+		text = string(content)
+	case FormatGoTemplate, FormatJSONPath:
+	default:
+		return nil, nil
+	}
+
+	if name == FormatGoTemplate || name == FormatGoTemplateFile {
+		tmpl, err := template.New("output").Funcs(TemplateFuncs).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidTemplate, err)
+		}
+		return func(w io.Writer, data any) error {
+			if err := tmpl.Execute(w, data); err != nil {
+				return fmt.Errorf("%w: %w", ErrInvalidTemplate, err)
+			}
+			return nil
+		}, nil
+	}
+
+	path, err := parseJSONPath(text)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidTemplate, err)
+	}
+	return func(w io.Writer, data any) error {
+		// JSONPath works on the JSON form of the data, with its field names
+		encoded, err := json.Marshal(data)
+		if err != nil {
+			return fmt.Errorf("error encoding JSON: %w", err)
+		}
+		decoder := json.NewDecoder(strings.NewReader(string(encoded)))
+		decoder.UseNumber()
+		var value any
+		if err := decoder.Decode(&value); err != nil {
+			return fmt.Errorf("error decoding JSON: %w", err)
+		}
+		// Nothing is written when the template fails halfway
+		var buf bytes.Buffer
+		if err := path.execute(&buf, value, !allowMissing); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidTemplate, err)
+		}
+		if _, err := buf.WriteTo(w); err != nil {
+			return fmt.Errorf("error writing output: %w", err)
+		}
+		return nil
+	}, nil
+}
+
+// humanSize formats a number of bytes with units.FormatSize.
+func humanSize(size any) (string, error) {
+	v := reflect.ValueOf(size)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return units.FormatSize(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return units.FormatSize(int64(v.Uint())), nil //nolint:gosec // sizes fit in int64
+	case reflect.Float32, reflect.Float64:
+		return units.FormatSize(int64(v.Float())), nil
+	default:
+		return "", fmt.Errorf("humanSize: %w %T", errUnsupportedType, size)
+	}
+}
+
+// relativeTime describes a time relative to now, e.g. "3 hours ago" or
+// "in 6 days". It accepts a time.Time or an RFC 3339 string, and returns an
+// empty string for the zero time.
+func relativeTime(value any) (string, error) {
+	var t time.Time
+	switch v := value.(type) {
+	case time.Time:
+		t = v
+	case *time.Time:
+		if v != nil {
+			t = *v
+		}
+	case string:
+		if v == "" {
+			return "", nil
+		}
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return "", fmt.Errorf("relativeTime: %w", err)
+		}
+		t = parsed
+	default:
+		return "", fmt.Errorf("relativeTime: %w %T", errUnsupportedType, value)
+	}
+	if t.IsZero() {
+		return "", nil
+	}
+	return formatRelative(time.Until(t)), nil
+}
+
+// formatRelative describes the duration d from now in its largest unit.
+func formatRelative(d time.Duration) string {
+	future := d > 0
+	if !future {
+		d = -d
+	}
+	var count int64
+	var unit string
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		count, unit = int64(d/time.Minute), "minute"
+	case d < 24*time.Hour:
+		count, unit = int64(d/time.Hour), "hour"
+	default:
+		count, unit = int64(d/(24*time.Hour)), "day"
+	}
+	if count > 1 {
+		unit += "s"
+	}
+	if future {
+		return fmt.Sprintf("in %d %s", count, unit)
+	}
+	return fmt.Sprintf("%d %s ago", count, unit)
+}
+
+// join joins the elements of a list, such as the tags of a file, with sep.
+func join(sep string, list any) (string, error) {
+	v := reflect.ValueOf(list)
+	if !v.IsValid() {
+		return "", nil
+	}
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return "", fmt.Errorf("join: %w %T", errUnsupportedType, list)
+	}
+	values := make([]string, v.Len())
+	for i := range v.Len() {
+		values[i] = fmt.Sprint(v.Index(i).Interface())
+	}
+	return strings.Join(values, sep), nil
+}
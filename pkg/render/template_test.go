@@ -0,0 +1,105 @@
+package render_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ephemeralfiles/eph/pkg/render"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type taggedFile struct {
+	FileID         string    `json:"file_id"`
+	Filename       string    `json:"filename"`
+	Size           int64     `json:"size"`
+	Tags           []string  `json:"tags,omitempty"`
+	ExpirationDate time.Time `json:"expiration_date"`
+}
+
+var taggedColumns = []render.Column[taggedFile]{
+	{Name: "ID", Value: func(f taggedFile) string { return f.FileID }},
+}
+
+func TestGoTemplate(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	items := []taggedFile{
+		{FileID: "id-1", Filename: "report.pdf", Size: 1258291, Tags: []string{"invoice", "q1"},
+			ExpirationDate: now.Add(6*24*time.Hour + time.Hour)},
+		{FileID: "id-2", Filename: "notes.md", Size: 1600, ExpirationDate: now.Add(-3*time.Hour - time.Minute)},
+	}
+
+	tests := []struct {
+		name     string
+		format   string
+		expected string
+	}{
+		{
+			name:     "fields",
+			format:   `go-template={{range .}}{{.FileID}}{{"\n"}}{{end}}`,
+			expected: "id-1\nid-2\n",
+		},
+		{
+			name:     "human size",
+			format:   `go-template={{range .}}{{humanSize .Size}};{{end}}`,
+			expected: "1.2 MB;1.6 KB;",
+		},
+		{
+			name:     "relative time",
+			format:   `go-template={{range .}}{{relativeTime .ExpirationDate}};{{end}}`,
+			expected: "in 6 days;3 hours ago;",
+		},
+		{
+			name:     "join tags",
+			format:   `go-template={{range .}}{{.Filename}}=[{{.Tags | join ", "}}] {{end}}`,
+			expected: "report.pdf=[invoice, q1] notes.md=[] ",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var buf bytes.Buffer
+			require.NoError(t, render.List(&buf, items, taggedColumns, render.Options{Format: tt.format}))
+			assert.Equal(t, tt.expected, buf.String())
+		})
+	}
+}
+
+func TestGoTemplateItem(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "item.tmpl")
+	require.NoError(t, os.WriteFile(path, []byte("{{.Filename}} ({{humanSize .Size}})\n"), 0600))
+
+	var buf bytes.Buffer
+	item := taggedFile{Filename: "notes.md", Size: 1600}
+	require.NoError(t, render.Item(&buf, item, taggedColumns, render.Options{Format: "go-template-file=" + path}))
+	assert.Equal(t, "notes.md (1.6 KB)\n", buf.String())
+}
+
+func TestTemplateErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, format := range []string{
+		"go-template={{.FileID",
+		"go-template={{humanSize .Filename}}",
+		"jsonpath={.[*].file_id",
+		"jsonpath={range .[*]}{.file_id}",
+		"jsonpath={.[abc]}",
+	} {
+		var buf bytes.Buffer
+		err := render.List(&buf, []taggedFile{{Filename: "a"}}, taggedColumns, render.Options{Format: format})
+		require.ErrorIs(t, err, render.ErrInvalidTemplate, format)
+	}
+
+	err := render.Options{Format: "go-template-file=/nonexistent/template"}.Validate()
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	err = render.Options{Format: "mustache={{.}}"}.Validate()
+	require.ErrorIs(t, err, render.ErrUnknownFormat)
+}
@@ -0,0 +1,158 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ephemeralfiles/eph/pkg/dto"
+	"github.com/ephemeralfiles/eph/pkg/ephcli"
+)
+
+// File is a personal or organization file listed by the interface.
+type File struct {
+	ID             string
+	Name           string
+	Size           int64
+	Tags           []string
+	Owner          string
+	ExpiresAt      time.Time
+	OrganizationID string
+}
+
+// Backend runs the operations of the interface.
+type Backend interface {
+	// Organizations returns the organizations of the user.
+	Organizations() ([]dto.Organization, error)
+	// Files returns the files of an organization, or the personal files when
+	// orgID is empty.
+	Files(orgID string) ([]File, error)
+	// Info returns the complete metadata of a file.
+	Info(f File) (*dto.InfoFile, error)
+	// Download downloads a file, reporting its progress to progress.
+	Download(f File, progress func(current, total int64)) error
+	// Delete deletes a file.
+	Delete(f File) error
+	// SetTags replaces the tags of an organization file.
+	SetTags(f File, tags []string) error
+	// Share creates a share link of a file and returns its URL.
+	Share(f File) (string, error)
+}
+
+// clientBackend is the Backend of an ephemeralfiles client.
+type clientBackend struct {
+	client *ephcli.ClientEphemeralfiles
+}
+
+// NewClientBackend returns the Backend running the operations with client.
+// Files are downloaded to the output directory of client.
+func NewClientBackend(client *ephcli.ClientEphemeralfiles) Backend {
+	return &clientBackend{client: client}
+}
+
+// Organizations returns the organizations of the user.
+func (b *clientBackend) Organizations() ([]dto.Organization, error) {
+	orgs, err := b.client.ListOrganizations()
+	if err != nil {
+		return nil, fmt.Errorf("error listing organizations: %w", err)
+	}
+	return orgs, nil
+}
+
+// Files returns the files of an organization, or the personal files.
+func (b *clientBackend) Files(orgID string) ([]File, error) {
+	if orgID == "" {
+		list, err := b.client.Fetch()
+		if err != nil {
+			return nil, fmt.Errorf("error listing files: %w", err)
+		}
+		files := make([]File, 0, len(list))
+		for _, f := range list {
+			files = append(files, File{
+				ID: f.FileID, Name: f.FileName, Size: f.Size, Owner: f.OwnerID, ExpiresAt: f.ExpirationDate,
+			})
+		}
+		return files, nil
+	}
+
+	list, err := b.client.ListAllOrganizationFiles(orgID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing organization files: %w", err)
+	}
+	files := make([]File, 0, len(list))
+	for _, f := range list {
+		expiresAt, _ := time.Parse(time.RFC3339, f.ExpirationDate)
+		owner := f.OwnerEmail
+		if owner == "" {
+			owner = f.OwnerID
+		}
+		files = append(files, File{
+			ID: f.ID, Name: f.Filename, Size: f.Size, Tags: f.Tags, Owner: owner,
+			ExpiresAt: expiresAt, OrganizationID: f.OrganizationID,
+		})
+	}
+	return files, nil
+}
+
+// Info returns the complete metadata of a file.
+func (b *clientBackend) Info(f File) (*dto.InfoFile, error) {
+	info, err := b.client.GetFileInfo(f.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting file information: %w", err)
+	}
+	return info, nil
+}
+
+// Download downloads a file in the transfer mode it was uploaded with, with
+// its own copy of the client. Files are downloaded with E2E encryption unless
+// the server reports them as clear, as servers may omit the mode.
+func (b *clientBackend) Download(f File, progress func(current, total int64)) error {
+	info, err := b.Info(f)
+	if err != nil {
+		return err
+	}
+	client := b.client.Clone()
+	client.SetProgressFunc(progress)
+	switch {
+	case info.EncryptionMode != dto.EncryptionModeClear:
+		err = client.DownloadE2E(f.ID, "")
+	case f.OrganizationID != "":
+		err = client.DownloadOrganizationFile(f.ID, "")
+	default:
+		err = client.Download(f.ID, "")
+	}
+	if err != nil {
+		return fmt.Errorf("error downloading %s: %w", f.Name, err)
+	}
+	return nil
+}
+
+// Delete deletes a file.
+func (b *clientBackend) Delete(f File) error {
+	var err error
+	if f.OrganizationID != "" {
+		err = b.client.DeleteOrganizationFile(f.ID)
+	} else {
+		err = b.client.Remove(f.ID)
+	}
+	if err != nil {
+		return fmt.Errorf("error deleting %s: %w", f.Name, err)
+	}
+	return nil
+}
+
+// SetTags replaces the tags of an organization file.
+func (b *clientBackend) SetTags(f File, tags []string) error {
+	if _, err := b.client.UpdateFileTags(f.ID, tags); err != nil {
+		return fmt.Errorf("error updating the tags of %s: %w", f.Name, err)
+	}
+	return nil
+}
+
+// Share creates a share link of a file with the default options.
+func (b *clientBackend) Share(f File) (string, error) {
+	link, err := b.client.CreateShareLink(f.ID, ephcli.ShareOptions{})
+	if err != nil {
+		return "", fmt.Errorf("error sharing %s: %w", f.Name, err)
+	}
+	return link.URL, nil
+}
@@ -0,0 +1,65 @@
+package tui
+
+// Headless drives a program without terminal, with scripted keys. Commands run
+// synchronously: each key returns once the commands it started, downloads
+// included, have ended and their results have updated the program.
+type Headless struct {
+	program       Program
+	width, height int
+}
+
+// NewHeadless returns the driver of program drawn in a screen of width
+// columns and height lines, once the commands of its initialization have run.
+func NewHeadless(program Program, width, height int) *Headless {
+	h := &Headless{program: program, width: width, height: height}
+	h.run(program.Init())
+	return h
+}
+
+// Press sends keys to the program, in order.
+func (h *Headless) Press(keys ...Key) {
+	for _, key := range keys {
+		h.run(h.program.Update(key))
+	}
+}
+
+// Type sends the characters of text to the program, in order.
+func (h *Headless) Type(text string) {
+	for _, r := range text {
+		h.Press(Key(string(r)))
+	}
+}
+
+// View returns the screen drawn by the program.
+func (h *Headless) View() string {
+	return h.program.View(h.width, h.height)
+}
+
+// Quitting reports whether the program has ended.
+func (h *Headless) Quitting() bool {
+	return h.program.Quitting()
+}
+
+// run runs cmd and the commands started by its results, until none is left.
+func (h *Headless) run(cmd Cmd) {
+	var msgs []Msg
+	send := func(msg Msg) { msgs = append(msgs, msg) }
+	cmds := []Cmd{cmd}
+	for len(cmds) > 0 || len(msgs) > 0 {
+		if len(msgs) > 0 {
+			msg := msgs[0]
+			msgs = msgs[1:]
+			if b, ok := msg.(batchMsg); ok {
+				cmds = append(cmds, b...)
+			} else {
+				cmds = append(cmds, h.program.Update(msg))
+			}
+			continue
+		}
+		next := cmds[0]
+		cmds = cmds[1:]
+		if next != nil {
+			next(send)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package tui
+
+import "time"
+
+// waitInput does not wait outside Unix: the next read blocks, so that a key
+// pressed after the program ended may still be read by it.
+func waitInput(_ int, _ time.Duration) (bool, error) {
+	return true, nil
+}
@@ -0,0 +1,25 @@
+//go:build unix
+
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// waitInput waits up to timeout for input to read on fd and reports whether
+// there is some.
+func waitInput(fd int, timeout time.Duration) (bool, error) {
+	fds := []unix.PollFd{{Fd: int32(fd), Events: unix.POLLIN}} //nolint:gosec // file descriptors fit in an int32
+	n, err := unix.Poll(fds, int(timeout.Milliseconds()))
+	if errors.Is(err, unix.EINTR) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error waiting for input: %w", err)
+	}
+	return n > 0, nil
+}
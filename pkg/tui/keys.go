@@ -0,0 +1,107 @@
+package tui
+
+import (
+	"unicode/utf8"
+)
+
+// Key is a key pressed by the user: the name of a special key, or the
+// character typed.
+type Key string
+
+// Special keys.
+const (
+	KeyUp        Key = "up"
+	KeyDown      Key = "down"
+	KeyLeft      Key = "left"
+	KeyRight     Key = "right"
+	KeyPageUp    Key = "pgup"
+	KeyPageDown  Key = "pgdown"
+	KeyHome      Key = "home"
+	KeyEnd       Key = "end"
+	KeyEnter     Key = "enter"
+	KeyEsc       Key = "esc"
+	KeyBackspace Key = "backspace"
+	KeyTab       Key = "tab"
+	KeyCtrlC     Key = "ctrl+c"
+)
+
+// Control characters read from a terminal in raw mode.
+const (
+	asciiCtrlC     = 0x03
+	asciiBackspace = 0x08
+	asciiTab       = 0x09
+	asciiLineFeed  = 0x0a
+	asciiReturn    = 0x0d
+	asciiEsc       = 0x1b
+	asciiDelete    = 0x7f
+)
+
+// csiKeys are the keys of the final byte of the CSI sequences without
+// parameter, e.g. ESC [ A.
+var csiKeys = map[byte]Key{
+	'A': KeyUp, 'B': KeyDown, 'C': KeyRight, 'D': KeyLeft, 'H': KeyHome, 'F': KeyEnd,
+}
+
+// tildeKeys are the keys of the CSI sequences ending with ~, e.g. ESC [ 5 ~.
+var tildeKeys = map[string]Key{
+	"1": KeyHome, "7": KeyHome, "4": KeyEnd, "8": KeyEnd, "5": KeyPageUp, "6": KeyPageDown,
+}
+
+// DecodeKeys decodes the keys of the bytes read from a terminal in raw mode.
+// Unknown escape sequences and control characters are dropped.
+func DecodeKeys(data []byte) []Key {
+	var keys []Key
+	for len(data) > 0 {
+		key, n := decodeKey(data)
+		if key != "" {
+			keys = append(keys, key)
+		}
+		data = data[n:]
+	}
+	return keys
+}
+
+// decodeKey decodes the first key of data and returns it with the number of
+// bytes read, with an empty key for the bytes dropped.
+func decodeKey(data []byte) (Key, int) {
+	switch data[0] {
+	case asciiEsc:
+		return decodeEscape(data)
+	case asciiReturn, asciiLineFeed:
+		return KeyEnter, 1
+	case asciiDelete, asciiBackspace:
+		return KeyBackspace, 1
+	case asciiTab:
+		return KeyTab, 1
+	case asciiCtrlC:
+		return KeyCtrlC, 1
+	}
+	if data[0] < ' ' {
+		return "", 1
+	}
+	r, n := utf8.DecodeRune(data)
+	if r == utf8.RuneError {
+		return "", n
+	}
+	return Key(string(r)), n
+}
+
+// decodeEscape decodes an escape sequence, or a lone escape key.
+func decodeEscape(data []byte) (Key, int) {
+	if len(data) < 2 || (data[1] != '[' && data[1] != 'O') {
+		return KeyEsc, 1
+	}
+	// Parameters are digits and semicolons, up to the final byte
+	i := 2
+	for i < len(data) && (data[i] >= '0' && data[i] <= '9' || data[i] == ';') {
+		i++
+	}
+	if i == len(data) {
+		return "", i
+	}
+	params, final := string(data[2:i]), data[i]
+	if final == '~' {
+		return tildeKeys[params], i + 1
+	}
+	return csiKeys[final], i + 1
+}
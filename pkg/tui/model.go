@@ -0,0 +1,568 @@
+// Package tui is the full-screen terminal interface of eph ui. It lists the
+// personal and organization files of the user, previews their metadata and
+// downloads, deletes, re-tags and shares them with keystrokes, while a queue
+// pane shows the progress of the downloads.
+//
+// The interface is a Program, a Model updated by messages, the keys pressed by
+// the user and the results of the commands it starts, and drawn by its View.
+// Run drives a program in a terminal; Headless drives it with scripted keys,
+// for tests. The Picker program selects items of a list with fuzzy search.
+package tui
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/ephemeralfiles/eph/pkg/dto"
+)
+
+// maxRunningDownloads is the number of downloads of the queue running at once.
+const maxRunningDownloads = 2
+
+// Msg is a message updating a program: a Key, or the result of a command.
+type Msg any
+
+// Cmd is a command started by a program. It runs outside of the program and
+// sends its results to the program with send.
+type Cmd func(send func(Msg))
+
+// Program is an interface driven by Run or Headless.
+type Program interface {
+	// Init returns the command to run first, nil for none.
+	Init() Cmd
+	// Update updates the program with msg and returns the command to run next,
+	// nil for none.
+	Update(msg Msg) Cmd
+	// View draws the program in a screen of width columns and height lines,
+	// separated by newlines.
+	View(width, height int) string
+	// Quitting reports whether the program has ended.
+	Quitting() bool
+}
+
+// batchMsg is sent by the commands running several commands at once.
+type batchMsg []Cmd
+
+// batch returns the command running cmds at once, nil when there is none.
+func batch(cmds ...Cmd) Cmd {
+	cmds = slices.DeleteFunc(cmds, func(cmd Cmd) bool { return cmd == nil })
+	if len(cmds) == 0 {
+		return nil
+	}
+	return func(send func(Msg)) { send(batchMsg(cmds)) }
+}
+
+// Results of the commands.
+type (
+	orgsLoadedMsg struct {
+		orgs []dto.Organization
+		err  error
+	}
+	filesLoadedMsg struct {
+		orgID string
+		files []File
+		err   error
+	}
+	infoLoadedMsg struct {
+		fileID string
+		info   *dto.InfoFile
+		err    error
+	}
+	deletedMsg struct {
+		file File
+		err  error
+	}
+	taggedMsg struct {
+		file File
+		tags []string
+		err  error
+	}
+	sharedMsg struct {
+		file File
+		url  string
+		err  error
+	}
+	progressMsg struct {
+		id             int
+		current, total int64
+	}
+	transferDoneMsg struct {
+		id  int
+		err error
+	}
+)
+
+// mode is what the keys pressed by the user act on.
+type mode int
+
+const (
+	modeList mode = iota
+	modeOrgPicker
+	modeTagFilter
+	modeRetag
+	modeConfirmDelete
+	modeHelp
+)
+
+// States of a transfer of the queue.
+const (
+	transferQueued  = "queued"
+	transferRunning = "running"
+	transferDone    = "done"
+	transferFailed  = "failed"
+)
+
+// transfer is a download of the queue.
+type transfer struct {
+	id             int
+	file           File
+	state          string
+	current, total int64
+	err            error
+}
+
+// Model is the state of the interface.
+type Model struct {
+	backend Backend
+
+	orgs      []dto.Organization
+	orgID     string
+	files     []File
+	visible   []File
+	tagFilter string
+
+	cursor     int
+	offset     int
+	listHeight int
+
+	mode   mode
+	input  string
+	picker int
+	info   *dto.InfoFile
+	status string
+
+	transfers      []*transfer
+	nextTransferID int
+	quitting       bool
+}
+
+// New returns the model of the interface running its operations with backend.
+// It starts on the personal files.
+func New(backend Backend) *Model {
+	return &Model{backend: backend, status: "Loading..."}
+}
+
+// Init returns the command loading the organizations and the personal files.
+func (m *Model) Init() Cmd {
+	return batch(m.loadOrganizations(), m.loadFiles())
+}
+
+// Quitting reports whether the user asked to quit.
+func (m *Model) Quitting() bool {
+	return m.quitting
+}
+
+// Update updates the model with msg and returns the command to run next, nil
+// for none.
+func (m *Model) Update(msg Msg) Cmd {
+	switch msg := msg.(type) {
+	case Key:
+		return m.press(msg)
+	case orgsLoadedMsg:
+		if msg.err != nil {
+			m.status = msg.err.Error()
+			return nil
+		}
+		m.orgs = msg.orgs
+	case filesLoadedMsg:
+		if msg.orgID != m.orgID {
+			return nil // the user switched to another scope meanwhile
+		}
+		if msg.err != nil {
+			m.status = msg.err.Error()
+			return nil
+		}
+		m.files = msg.files
+		m.applyFilter()
+		m.status = pluralize(len(m.visible), "file")
+	case infoLoadedMsg:
+		if msg.err != nil {
+			m.status = msg.err.Error()
+			return nil
+		}
+		if f, ok := m.selected(); ok && f.ID == msg.fileID {
+			m.info = msg.info
+		}
+	case deletedMsg:
+		if msg.err != nil {
+			m.status = msg.err.Error()
+			return nil
+		}
+		m.files = slices.DeleteFunc(m.files, func(f File) bool { return f.ID == msg.file.ID })
+		m.applyFilter()
+		m.status = "Deleted " + msg.file.Name
+	case taggedMsg:
+		if msg.err != nil {
+			m.status = msg.err.Error()
+			return nil
+		}
+		for i := range m.files {
+			if m.files[i].ID == msg.file.ID {
+				m.files[i].Tags = msg.tags
+			}
+		}
+		m.applyFilter()
+		m.status = "Updated the tags of " + msg.file.Name
+	case sharedMsg:
+		if msg.err != nil {
+			m.status = msg.err.Error()
+			return nil
+		}
+		m.status = "Share link of " + msg.file.Name + ": " + msg.url
+	case progressMsg:
+		if t := m.transfer(msg.id); t != nil {
+			t.current, t.total = msg.current, msg.total
+		}
+	case transferDoneMsg:
+		return m.finishTransfer(msg)
+	}
+	return nil
+}
+
+// press updates the model with a key pressed by the user.
+func (m *Model) press(key Key) Cmd {
+	if key == KeyCtrlC {
+		m.quitting = true
+		return nil
+	}
+	switch m.mode {
+	case modeOrgPicker:
+		return m.pressOrgPicker(key)
+	case modeTagFilter, modeRetag:
+		return m.pressInput(key)
+	case modeConfirmDelete:
+		m.mode = modeList
+		f, ok := m.selected()
+		if !ok || (key != "y" && key != "Y") {
+			m.status = "Deletion cancelled"
+			return nil
+		}
+		m.status = "Deleting " + f.Name + "..."
+		return m.delete(f)
+	case modeHelp:
+		m.mode = modeList
+		return nil
+	case modeList:
+	}
+	return m.pressList(key)
+}
+
+// pressList updates the model with a key pressed on the list of files.
+func (m *Model) pressList(key Key) Cmd {
+	switch key {
+	case "q":
+		m.quitting = true
+	case KeyUp, "k":
+		m.move(-1)
+	case KeyDown, "j":
+		m.move(1)
+	case KeyPageUp:
+		m.move(-max(m.listHeight, 1))
+	case KeyPageDown:
+		m.move(max(m.listHeight, 1))
+	case KeyHome, "g":
+		m.move(-len(m.visible))
+	case KeyEnd, "G":
+		m.move(len(m.visible))
+	case "?":
+		m.mode = modeHelp
+	case "o":
+		m.mode = modeOrgPicker
+		m.picker = 0
+		for i, org := range m.orgs {
+			if org.ID == m.orgID {
+				m.picker = i + 1
+			}
+		}
+	case "f":
+		m.mode = modeTagFilter
+		m.input = m.tagFilter
+	case KeyEsc:
+		if m.tagFilter != "" {
+			m.tagFilter = ""
+			m.applyFilter()
+			m.status = pluralize(len(m.visible), "file")
+		}
+	case "r":
+		m.status = "Loading..."
+		return batch(m.loadOrganizations(), m.loadFiles())
+	default:
+		return m.pressFileAction(key)
+	}
+	return nil
+}
+
+// pressFileAction updates the model with a key acting on the selected file.
+func (m *Model) pressFileAction(key Key) Cmd {
+	f, ok := m.selected()
+	if !ok {
+		return nil
+	}
+	switch key {
+	case "i", KeyEnter:
+		return m.loadInfo(f)
+	case "d":
+		m.nextTransferID++
+		m.transfers = append(m.transfers, &transfer{
+			id: m.nextTransferID, file: f, state: transferQueued, total: f.Size,
+		})
+		m.status = "Queued " + f.Name
+		return m.startTransfers()
+	case "x":
+		m.mode = modeConfirmDelete
+	case "t":
+		if f.OrganizationID == "" {
+			m.status = "Only organization files have tags"
+			return nil
+		}
+		m.mode = modeRetag
+		m.input = strings.Join(f.Tags, ",")
+	case "s":
+		m.status = "Sharing " + f.Name + "..."
+		return m.share(f)
+	}
+	return nil
+}
+
+// pressOrgPicker updates the model with a key pressed on the organization
+// picker, listing the personal files then the organizations.
+func (m *Model) pressOrgPicker(key Key) Cmd {
+	switch key {
+	case KeyUp, "k":
+		m.picker = max(m.picker-1, 0)
+	case KeyDown, "j":
+		m.picker = min(m.picker+1, len(m.orgs))
+	case KeyEsc, "q", "o":
+		m.mode = modeList
+	case KeyEnter:
+		m.mode = modeList
+		m.orgID = ""
+		if m.picker > 0 {
+			m.orgID = m.orgs[m.picker-1].ID
+		}
+		m.files, m.visible, m.info = nil, nil, nil
+		m.cursor, m.offset = 0, 0
+		m.status = "Loading..."
+		return m.loadFiles()
+	}
+	return nil
+}
+
+// pressInput updates the model with a key typed in the tag filter or re-tag
+// prompt.
+func (m *Model) pressInput(key Key) Cmd {
+	switch key {
+	case KeyEsc:
+		m.mode = modeList
+	case KeyBackspace:
+		runes := []rune(m.input)
+		if len(runes) > 0 {
+			m.input = string(runes[:len(runes)-1])
+		}
+	case KeyEnter:
+		input := strings.TrimSpace(m.input)
+		if m.mode == modeTagFilter {
+			m.mode = modeList
+			m.tagFilter = input
+			m.applyFilter()
+			m.status = pluralize(len(m.visible), "file")
+			return nil
+		}
+		m.mode = modeList
+		f, ok := m.selected()
+		if !ok {
+			return nil
+		}
+		m.status = "Updating the tags of " + f.Name + "..."
+		return m.setTags(f, parseTags(input))
+	default:
+		if len([]rune(string(key))) == 1 {
+			m.input += string(key)
+		}
+	}
+	return nil
+}
+
+// move moves the cursor by delta files, within the list.
+func (m *Model) move(delta int) {
+	cursor := min(max(m.cursor+delta, 0), max(len(m.visible)-1, 0))
+	if cursor != m.cursor {
+		m.cursor = cursor
+		m.info = nil
+	}
+}
+
+// selected returns the file under the cursor.
+func (m *Model) selected() (File, bool) {
+	if m.cursor >= len(m.visible) {
+		return File{}, false
+	}
+	return m.visible[m.cursor], true
+}
+
+// applyFilter selects the files having the tag filter, keeping the cursor on
+// the selected file when it is still listed.
+func (m *Model) applyFilter() {
+	previous, _ := m.selected()
+	m.visible = m.visible[:0]
+	for _, f := range m.files {
+		if m.tagFilter == "" || slices.ContainsFunc(f.Tags, func(tag string) bool {
+			return strings.EqualFold(tag, m.tagFilter)
+		}) {
+			m.visible = append(m.visible, f)
+		}
+	}
+	m.cursor = min(m.cursor, max(len(m.visible)-1, 0))
+	if i := slices.IndexFunc(m.visible, func(f File) bool { return f.ID == previous.ID }); i >= 0 {
+		m.cursor = i
+	} else {
+		m.info = nil
+	}
+}
+
+// organizationName returns the name of the listed scope.
+func (m *Model) organizationName() string {
+	for _, org := range m.orgs {
+		if org.ID == m.orgID {
+			return org.Name
+		}
+	}
+	if m.orgID != "" {
+		return m.orgID
+	}
+	return "Personal files"
+}
+
+// transfer returns the transfer of the queue with the given id.
+func (m *Model) transfer(id int) *transfer {
+	for _, t := range m.transfers {
+		if t.id == id {
+			return t
+		}
+	}
+	return nil
+}
+
+// startTransfers starts the queued downloads while less than
+// maxRunningDownloads are running.
+func (m *Model) startTransfers() Cmd {
+	running := 0
+	for _, t := range m.transfers {
+		if t.state == transferRunning {
+			running++
+		}
+	}
+	var cmds []Cmd
+	for _, t := range m.transfers {
+		if running >= maxRunningDownloads {
+			break
+		}
+		if t.state == transferQueued {
+			t.state = transferRunning
+			running++
+			cmds = append(cmds, m.download(t.id, t.file))
+		}
+	}
+	return batch(cmds...)
+}
+
+// finishTransfer records the end of a download and starts the next ones.
+func (m *Model) finishTransfer(msg transferDoneMsg) Cmd {
+	t := m.transfer(msg.id)
+	if t == nil {
+		return nil
+	}
+	if msg.err != nil {
+		t.state, t.err = transferFailed, msg.err
+		m.status = msg.err.Error()
+	} else {
+		t.state = transferDone
+		t.current = t.total
+		m.status = "Downloaded " + t.file.Name
+	}
+	return m.startTransfers()
+}
+
+// loadOrganizations returns the command loading the organizations.
+func (m *Model) loadOrganizations() Cmd {
+	backend := m.backend
+	return func(send func(Msg)) {
+		orgs, err := backend.Organizations()
+		send(orgsLoadedMsg{orgs: orgs, err: err})
+	}
+}
+
+// loadFiles returns the command loading the files of the listed scope.
+func (m *Model) loadFiles() Cmd {
+	backend, orgID := m.backend, m.orgID
+	return func(send func(Msg)) {
+		files, err := backend.Files(orgID)
+		send(filesLoadedMsg{orgID: orgID, files: files, err: err})
+	}
+}
+
+// loadInfo returns the command loading the complete metadata of f.
+func (m *Model) loadInfo(f File) Cmd {
+	backend := m.backend
+	return func(send func(Msg)) {
+		info, err := backend.Info(f)
+		send(infoLoadedMsg{fileID: f.ID, info: info, err: err})
+	}
+}
+
+// download returns the command downloading f as the transfer id.
+func (m *Model) download(id int, f File) Cmd {
+	backend := m.backend
+	return func(send func(Msg)) {
+		err := backend.Download(f, func(current, total int64) {
+			send(progressMsg{id: id, current: current, total: total})
+		})
+		send(transferDoneMsg{id: id, err: err})
+	}
+}
+
+// delete returns the command deleting f.
+func (m *Model) delete(f File) Cmd {
+	backend := m.backend
+	return func(send func(Msg)) {
+		send(deletedMsg{file: f, err: backend.Delete(f)})
+	}
+}
+
+// setTags returns the command replacing the tags of f.
+func (m *Model) setTags(f File, tags []string) Cmd {
+	backend := m.backend
+	return func(send func(Msg)) {
+		send(taggedMsg{file: f, tags: tags, err: backend.SetTags(f, tags)})
+	}
+}
+
+// share returns the command creating a share link of f.
+func (m *Model) share(f File) Cmd {
+	backend := m.backend
+	return func(send func(Msg)) {
+		url, err := backend.Share(f)
+		send(sharedMsg{file: f, url: url, err: err})
+	}
+}
+
+// parseTags splits comma-separated tags, dropping the empty ones.
+func parseTags(value string) []string {
+	tags := []string{}
+	for tag := range strings.SplitSeq(value, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
@@ -0,0 +1,241 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Scores of the fuzzy search, and lines of the picker above the items.
+const (
+	scoreMatch       = 1
+	scoreConsecutive = 5
+	scoreWordStart   = 3
+	pickerHeader     = 2
+)
+
+// ErrPickerCancelled is returned when the user leaves a picker without
+// selecting anything.
+var ErrPickerCancelled = errors.New("selection cancelled")
+
+// Picker is the program selecting items of a list by typing part of their
+// label, e.g. "inv01" for "invoice-2026-01.pdf". With multiple selection, tab
+// toggles the selection of the item under the cursor and enter selects the
+// toggled items, or the item under the cursor when none is.
+type Picker struct {
+	title    string
+	labels   []string
+	multiple bool
+
+	query    string
+	matches  []int
+	cursor   int
+	offset   int
+	toggled  map[int]bool
+	selected []int
+	ended    bool
+}
+
+// NewPicker returns the picker of the items of labels, selecting one item or
+// several items with multiple.
+func NewPicker(title string, labels []string, multiple bool) *Picker {
+	p := &Picker{title: title, labels: labels, multiple: multiple, toggled: map[int]bool{}}
+	p.search()
+	return p
+}
+
+// SetQuery sets the search typed in the picker.
+func (p *Picker) SetQuery(query string) {
+	p.query = query
+	p.search()
+}
+
+// Selection returns the indexes in labels of the selected items, in the order
+// of labels, or ErrPickerCancelled.
+func (p *Picker) Selection() ([]int, error) {
+	if len(p.selected) == 0 {
+		return nil, ErrPickerCancelled
+	}
+	return p.selected, nil
+}
+
+// Init returns no command.
+func (p *Picker) Init() Cmd {
+	return nil
+}
+
+// Quitting reports whether the user selected items or cancelled.
+func (p *Picker) Quitting() bool {
+	return p.ended
+}
+
+// Update updates the picker with a key pressed by the user.
+func (p *Picker) Update(msg Msg) Cmd {
+	key, ok := msg.(Key)
+	if !ok {
+		return nil
+	}
+	switch key {
+	case KeyEsc, KeyCtrlC:
+		p.ended = true
+	case KeyUp:
+		p.cursor = max(p.cursor-1, 0)
+	case KeyDown:
+		p.cursor = min(p.cursor+1, max(len(p.matches)-1, 0))
+	case KeyTab:
+		if p.multiple && p.cursor < len(p.matches) {
+			i := p.matches[p.cursor]
+			p.toggled[i] = !p.toggled[i]
+			p.cursor = min(p.cursor+1, len(p.matches)-1)
+		}
+	case KeyEnter:
+		p.confirm()
+	case KeyBackspace:
+		runes := []rune(p.query)
+		if len(runes) > 0 {
+			p.SetQuery(string(runes[:len(runes)-1]))
+		}
+	default:
+		if len([]rune(string(key))) == 1 {
+			p.SetQuery(p.query + string(key))
+		}
+	}
+	return nil
+}
+
+// confirm selects the toggled items, or the item under the cursor.
+func (p *Picker) confirm() {
+	for i := range p.labels {
+		if p.toggled[i] {
+			p.selected = append(p.selected, i)
+		}
+	}
+	if len(p.selected) == 0 && p.cursor < len(p.matches) {
+		p.selected = []int{p.matches[p.cursor]}
+	}
+	if len(p.selected) > 0 {
+		p.ended = true
+	}
+}
+
+// search lists the items matching the query, best first.
+func (p *Picker) search() {
+	scores := map[int]int{}
+	p.matches = p.matches[:0]
+	for i, label := range p.labels {
+		if score, ok := fuzzyScore(p.query, label); ok {
+			scores[i] = score
+			p.matches = append(p.matches, i)
+		}
+	}
+	slices.SortStableFunc(p.matches, func(a, b int) int { return scores[b] - scores[a] })
+	p.cursor, p.offset = 0, 0
+}
+
+// View draws the search and the page of the matching items holding the cursor.
+func (p *Picker) View(width, height int) string {
+	width, height = max(width, 1), max(height, pickerHeader+1)
+
+	hint := "enter: select, esc: cancel"
+	if p.multiple {
+		hint = "tab: toggle, enter: select, esc: cancel"
+	}
+	lines := []string{
+		fmt.Sprintf("%s (%d/%d, %s)", p.title, len(p.matches), len(p.labels), hint),
+		"> " + p.query + "_",
+	}
+
+	pageHeight := height - pickerHeader
+	p.offset = min(p.offset, p.cursor)
+	if p.cursor >= p.offset+pageHeight {
+		p.offset = p.cursor - pageHeight + 1
+	}
+	for n := p.offset; n < min(p.offset+pageHeight, len(p.matches)); n++ {
+		i := p.matches[n]
+		line := "  "
+		if n == p.cursor {
+			line = "> "
+		}
+		if p.multiple {
+			if p.toggled[i] {
+				line += "[x] "
+			} else {
+				line += "[ ] "
+			}
+		}
+		lines = append(lines, line+p.labels[i])
+	}
+	if len(p.matches) == 0 {
+		lines = append(lines, "  no match")
+	}
+	for i, line := range lines {
+		lines[i] = fit(line, width)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Pick runs a picker in the terminal of stdin and stderr and returns the
+// indexes of the selected items. query is the initial search.
+func Pick(title string, labels []string, multiple bool, query string) ([]int, error) {
+	p := NewPicker(title, labels, multiple)
+	p.SetQuery(query)
+	if err := Run(p, os.Stdin, os.Stderr); err != nil {
+		return nil, err
+	}
+	return p.Selection()
+}
+
+// fuzzyScore reports whether the characters of query appear in label in
+// order, ignoring case, and scores their best match: consecutive characters
+// and characters starting a word score higher.
+func fuzzyScore(query, label string) (int, bool) {
+	pattern := []rune(strings.ToLower(strings.TrimSpace(query)))
+	if len(pattern) == 0 {
+		return 0, true
+	}
+	text := []rune(strings.ToLower(label))
+
+	// best[i] is the best score of the characters of the pattern matched so
+	// far, the last one at text[i], or -1 when they cannot be matched so.
+	best := make([]int, len(text))
+	for i := range best {
+		best[i] = -1
+		if text[i] == pattern[0] {
+			best[i] = matchScore(text, i)
+		}
+	}
+	for _, r := range pattern[1:] {
+		next := make([]int, len(text))
+		bestBefore := -1 // best score ending before text[i-1]
+		for i := range text {
+			next[i] = -1
+			if i > 0 && text[i] == r {
+				score := bestBefore
+				if best[i-1] >= 0 {
+					score = max(score, best[i-1]+scoreConsecutive)
+				}
+				if score >= 0 {
+					next[i] = score + matchScore(text, i)
+				}
+			}
+			if i > 0 {
+				bestBefore = max(bestBefore, best[i-1])
+			}
+		}
+		best = next
+	}
+
+	score := slices.Max(append(best, -1))
+	return score, score >= 0
+}
+
+// matchScore is the score of a character of the query matched at text[i].
+func matchScore(text []rune, i int) int {
+	if i == 0 || !unicode.IsLetter(text[i-1]) && !unicode.IsDigit(text[i-1]) {
+		return scoreMatch + scoreWordStart
+	}
+	return scoreMatch
+}
@@ -0,0 +1,82 @@
+package tui_test
+
+import (
+	"testing"
+
+	"github.com/ephemeralfiles/eph/pkg/tui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var pickerLabels = []string{
+	"invoice-2026-01.pdf",
+	"invoice-2026-02.pdf",
+	"report.csv",
+	"old-export.zip",
+}
+
+func TestPickerSearch(t *testing.T) {
+	t.Parallel()
+
+	p := tui.NewPicker("Download a file", pickerLabels, false)
+	h := tui.NewHeadless(p, 60, 10)
+	assert.Contains(t, h.View(), "Download a file (4/4, enter: select, esc: cancel)")
+
+	h.Type("rep")
+	view := h.View()
+	assert.Contains(t, view, "> rep_")
+	assert.Contains(t, view, "(1/4")
+	assert.Contains(t, view, "> report.csv")
+
+	// Matches starting a word rank first
+	h.Press(tui.KeyBackspace, tui.KeyBackspace, tui.KeyBackspace)
+	h.Type("o")
+	assert.Contains(t, h.View(), "> old-export.zip")
+
+	h.Press(tui.KeyBackspace)
+	h.Type("inv02")
+	assert.Contains(t, h.View(), "> invoice-2026-02.pdf")
+	h.Press(tui.KeyEnter)
+	require.True(t, h.Quitting())
+	selection, err := p.Selection()
+	require.NoError(t, err)
+	assert.Equal(t, []int{1}, selection)
+}
+
+func TestPickerMultiple(t *testing.T) {
+	t.Parallel()
+
+	p := tui.NewPicker("Delete files", pickerLabels, true)
+	h := tui.NewHeadless(p, 60, 10)
+	h.Type("invoice")
+	h.Press(tui.KeyTab, tui.KeyTab)
+	view := h.View()
+	assert.Contains(t, view, "[x] invoice-2026-01.pdf")
+	assert.Contains(t, view, "[x] invoice-2026-02.pdf")
+
+	// Toggled items stay selected when the search changes
+	h.Press(tui.KeyBackspace, tui.KeyBackspace, tui.KeyBackspace, tui.KeyBackspace, tui.KeyBackspace,
+		tui.KeyBackspace, tui.KeyBackspace)
+	h.Type("old")
+	h.Press(tui.KeyTab, tui.KeyEnter)
+	selection, err := p.Selection()
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 3}, selection)
+}
+
+func TestPickerCancel(t *testing.T) {
+	t.Parallel()
+
+	p := tui.NewPicker("Organization", []string{"eph1", "eph2"}, false)
+	p.SetQuery("zzz")
+	h := tui.NewHeadless(p, 60, 10)
+	assert.Contains(t, h.View(), "no match")
+
+	// Enter without match does not end the picker
+	h.Press(tui.KeyEnter)
+	assert.False(t, h.Quitting())
+	h.Press(tui.KeyEsc)
+	assert.True(t, h.Quitting())
+	_, err := p.Selection()
+	require.ErrorIs(t, err, tui.ErrPickerCancelled)
+}
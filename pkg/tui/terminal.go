@@ -0,0 +1,149 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// Escape sequences of the terminal.
+const (
+	enterAltScreen = "\x1b[?1049h\x1b[?25l"
+	exitAltScreen  = "\x1b[?25h\x1b[?1049l"
+	clearScreen    = "\x1b[H\x1b[2J"
+)
+
+// Settings of the terminal loop.
+const (
+	inputBufferSize = 256
+	msgBufferSize   = 64
+	resizeInterval  = 250 * time.Millisecond
+)
+
+// ErrNotTerminal is returned by Run when the input or output is not a terminal.
+var ErrNotTerminal = errors.New("not a terminal")
+
+// resizeMsg is sent periodically to redraw the screen when its size changes.
+type resizeMsg struct{}
+
+// Run drives program in the terminal of in and out, in raw mode on the
+// alternate screen, until it ends. The terminal is restored on return.
+func Run(program Program, in, out *os.File) error {
+	inFd, outFd := int(in.Fd()), int(out.Fd()) //nolint:gosec // file descriptors fit in an int
+	if !term.IsTerminal(inFd) || !term.IsTerminal(outFd) {
+		return ErrNotTerminal
+	}
+	state, err := term.MakeRaw(inFd)
+	if err != nil {
+		return fmt.Errorf("error setting the terminal in raw mode: %w", err)
+	}
+	defer func() {
+		_ = term.Restore(inFd, state)
+	}()
+	_, _ = io.WriteString(out, enterAltScreen)
+	defer func() {
+		_, _ = io.WriteString(out, exitAltScreen)
+	}()
+
+	done := make(chan struct{})
+	msgs := make(chan Msg, msgBufferSize)
+	send := func(msg Msg) {
+		select {
+		case msgs <- msg:
+		case <-done:
+		}
+	}
+	start := func(cmd Cmd) {
+		if cmd != nil {
+			go cmd(send)
+		}
+	}
+
+	// The keys are no longer read once Run returns, so that the next reader of
+	// in, e.g. a confirmation prompt, gets them
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer close(done)
+	wg.Add(2) //nolint:mnd // key reader and resize ticker
+	go func() {
+		defer wg.Done()
+		readKeys(in, done, send)
+	}()
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(resizeInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				send(resizeMsg{})
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	width, height := 0, 0
+	start(program.Init())
+	for !program.Quitting() {
+		w, h, err := term.GetSize(outFd)
+		if err != nil {
+			return fmt.Errorf("error getting the terminal size: %w", err)
+		}
+		if w != width || h != height {
+			width, height = w, h
+			draw(out, program.View(width, height))
+		}
+
+		switch msg := (<-msgs).(type) {
+		case resizeMsg:
+			continue
+		case batchMsg:
+			for _, cmd := range msg {
+				start(cmd)
+			}
+		default:
+			start(program.Update(msg))
+		}
+		draw(out, program.View(width, height))
+	}
+	return nil
+}
+
+// readKeys sends the keys read from in, until done is closed or in is closed.
+func readKeys(in *os.File, done <-chan struct{}, send func(Msg)) {
+	fd := int(in.Fd()) //nolint:gosec // file descriptors fit in an int
+	buf := make([]byte, inputBufferSize)
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		ready, err := waitInput(fd, resizeInterval)
+		if err != nil {
+			return
+		}
+		if !ready {
+			continue
+		}
+		n, err := in.Read(buf)
+		for _, key := range DecodeKeys(buf[:n]) {
+			send(key)
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// draw redraws the screen with view, whose lines are separated by newlines.
+func draw(out io.Writer, view string) {
+	_, _ = io.WriteString(out, clearScreen+strings.ReplaceAll(view, "\n", "\r\n"))
+}
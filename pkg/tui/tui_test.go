@@ -0,0 +1,218 @@
+package tui_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ephemeralfiles/eph/pkg/ephcli"
+	"github.com/ephemeralfiles/eph/pkg/mockserver"
+	"github.com/ephemeralfiles/eph/pkg/tui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newHeadless returns a headless interface on a seeded mock server,
+// downloading files to the returned directory.
+func newHeadless(t *testing.T) (*tui.Headless, string) {
+	t.Helper()
+	client, dir := newClient(t, func(h http.Handler) http.Handler { return h })
+	return tui.NewHeadless(tui.New(tui.NewClientBackend(client)), 120, 30), dir
+}
+
+// newClient returns a client of a seeded mock server whose handler is wrapped
+// by wrap, downloading files to the returned directory.
+func newClient(t *testing.T, wrap func(http.Handler) http.Handler) (*ephcli.ClientEphemeralfiles, string) {
+	t.Helper()
+	srv, err := mockserver.New(mockserver.Options{Seed: true})
+	require.NoError(t, err)
+	ts := httptest.NewServer(wrap(srv.Handler()))
+	t.Cleanup(ts.Close)
+
+	dir := t.TempDir()
+	client := ephcli.NewClient(mockserver.DevToken(mockserver.DefaultEmail, time.Hour))
+	client.SetEndpoint(ts.URL)
+	client.SetOutputDir(dir)
+	client.DisableProgressBar()
+	return client, dir
+}
+
+// withoutEncryptionMode wraps a handler so that file information omits the
+// encryption mode, as servers that do not provide it. Clear downloads fail, so
+// that only the files downloaded with E2E encryption are written.
+func withoutEncryptionMode(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/download/clear/") {
+			http.Error(w, "clear download of an E2E file", http.StatusBadRequest)
+			return
+		}
+		if !strings.Contains(r.URL.Path, "/files/info/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		rec := httptest.NewRecorder()
+		next.ServeHTTP(rec, r)
+		var info map[string]any
+		if err := json.Unmarshal(rec.Body.Bytes(), &info); err != nil {
+			w.WriteHeader(rec.Code)
+			_, _ = w.Write(rec.Body.Bytes())
+			return
+		}
+		delete(info, "encryption_mode")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(rec.Code)
+		_ = json.NewEncoder(w).Encode(info)
+	})
+}
+
+func TestHeadlessBrowse(t *testing.T) {
+	t.Parallel()
+
+	h, _ := newHeadless(t)
+	view := h.View()
+	assert.Contains(t, view, "Personal files │ 2 files")
+	assert.Contains(t, view, "> welcome.txt")
+	assert.Contains(t, view, "notes.md")
+
+	h.Press(tui.KeyDown, "i")
+	view = h.View()
+	assert.Contains(t, view, "> notes.md")
+	assert.Contains(t, view, "Name:      notes.md")
+	assert.Contains(t, view, "Encrypted: clear")
+
+	// Switch to the first organization and filter by tag
+	h.Press("o", tui.KeyDown, tui.KeyEnter)
+	view = h.View()
+	assert.Contains(t, view, mockserver.FixtureOrganizationName+" │ 4 files")
+	assert.Contains(t, view, "invoice-2026-01.pdf")
+	assert.Contains(t, view, "> old-export.zip")
+	assert.Contains(t, view, "Tags:      archive")
+
+	h.Press("f")
+	h.Type("reports")
+	assert.Contains(t, h.View(), "Filter by tag (empty for all): reports_")
+	h.Press(tui.KeyEnter)
+	view = h.View()
+	assert.Contains(t, view, "tag: reports │ 1 file")
+	assert.Contains(t, view, "> report.csv")
+	assert.NotContains(t, view, "invoice-2026-01.pdf")
+
+	h.Press(tui.KeyEsc)
+	assert.Contains(t, h.View(), "4 files")
+
+	h.Press("?")
+	assert.Contains(t, h.View(), "Press any key to go back.")
+	h.Press("x")
+	assert.Contains(t, h.View(), "> report.csv")
+
+	h.Press("q")
+	assert.True(t, h.Quitting())
+}
+
+func TestHeadlessActions(t *testing.T) {
+	t.Parallel()
+
+	h, dir := newHeadless(t)
+
+	// Download the two personal files through the queue
+	h.Press("d", tui.KeyDown, "d")
+	view := h.View()
+	assert.Contains(t, view, "100%")
+	assert.Contains(t, view, "welcome.txt  done")
+	assert.Contains(t, view, "notes.md  done")
+	for _, name := range []string{"welcome.txt", "notes.md"} {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err)
+		assert.Contains(t, string(content), name+" fixture content")
+	}
+
+	h.Press("s")
+	assert.Contains(t, h.View(), "Share link of notes.md: http")
+
+	h.Press("t")
+	assert.Contains(t, h.View(), "Only organization files have tags")
+
+	// Deletion asks for a confirmation
+	h.Press("x", "n")
+	assert.Contains(t, h.View(), "Deletion cancelled")
+	h.Press("x")
+	assert.Contains(t, h.View(), "Delete notes.md? (y/n)")
+	h.Press("y")
+	view = h.View()
+	assert.Contains(t, view, "Deleted notes.md")
+	assert.Contains(t, view, "1 file")
+	assert.Contains(t, view, "> welcome.txt")
+
+	// Re-tag an organization file and find it by its new tag
+	h.Press("o", tui.KeyDown, tui.KeyEnter, "g", "t")
+	assert.Contains(t, h.View(), "Tags (comma-separated): archive_")
+	h.Press(tui.KeyBackspace, tui.KeyBackspace, tui.KeyBackspace, tui.KeyBackspace, tui.KeyBackspace, tui.KeyBackspace,
+		tui.KeyBackspace)
+	h.Type("old, 2025")
+	h.Press(tui.KeyEnter)
+	assert.Contains(t, h.View(), "Updated the tags of old-export.zip")
+
+	h.Press("r", "f")
+	h.Type("2025")
+	h.Press(tui.KeyEnter)
+	view = h.View()
+	assert.Contains(t, view, "> old-export.zip")
+	assert.Contains(t, view, "Tags:      old, 2025")
+}
+
+func TestHeadlessDownloadWithoutEncryptionMode(t *testing.T) {
+	t.Parallel()
+
+	// Upload an E2E file to a server which does not report encryption modes
+	client, dir := newClient(t, withoutEncryptionMode)
+	source := filepath.Join(t.TempDir(), "secret.txt")
+	require.NoError(t, os.WriteFile(source, []byte("end-to-end content"), 0600))
+	_, err := client.UploadE2E(source)
+	require.NoError(t, err)
+
+	h := tui.NewHeadless(tui.New(tui.NewClientBackend(client)), 120, 30)
+	h.Press("G", "i")
+	assert.Contains(t, h.View(), "Name:      secret.txt")
+	assert.Regexp(t, `Encrypted: *\n`, h.View())
+	h.Press("d")
+	assert.Contains(t, h.View(), "secret.txt  done")
+	content, err := os.ReadFile(filepath.Join(dir, "secret.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "end-to-end content", string(content))
+}
+
+func TestDecodeKeys(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		input    string
+		expected []tui.Key
+	}{
+		{name: "characters", input: "jké", expected: []tui.Key{"j", "k", "é"}},
+		{name: "arrows", input: "\x1b[A\x1b[B\x1b[C\x1b[D", expected: []tui.Key{
+			tui.KeyUp, tui.KeyDown, tui.KeyRight, tui.KeyLeft,
+		}},
+		{name: "application arrows", input: "\x1bOA", expected: []tui.Key{tui.KeyUp}},
+		{name: "pages", input: "\x1b[5~\x1b[6~", expected: []tui.Key{tui.KeyPageUp, tui.KeyPageDown}},
+		{name: "home and end", input: "\x1b[H\x1b[4~", expected: []tui.Key{tui.KeyHome, tui.KeyEnd}},
+		{name: "modified arrow", input: "\x1b[1;5A", expected: []tui.Key{tui.KeyUp}},
+		{name: "lone escape", input: "\x1b", expected: []tui.Key{tui.KeyEsc}},
+		{name: "escape then key", input: "\x1bq", expected: []tui.Key{tui.KeyEsc, "q"}},
+		{name: "controls", input: "\r\x7f\t\x03\x01", expected: []tui.Key{
+			tui.KeyEnter, tui.KeyBackspace, tui.KeyTab, tui.KeyCtrlC,
+		}},
+		{name: "unknown sequence", input: "\x1b[99~x", expected: []tui.Key{"x"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.expected, tui.DecodeKeys([]byte(tt.input)))
+		})
+	}
+}
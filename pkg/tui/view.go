@@ -0,0 +1,261 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ephemeralfiles/eph/pkg/units"
+)
+
+// Layout of the view.
+const (
+	headerHeight       = 2
+	footerHeight       = 2
+	transferPaneHeight = 4
+	listWidthPercent   = 60
+	sizeWidth          = 9
+	progressBarWidth   = 20
+	percent            = 100
+	dateLayout         = "2006-01-02 15:04"
+	paneSeparator      = " │ "
+)
+
+// help lists the keys of the interface.
+var help = []string{
+	"Keys",
+	"",
+	"  up/down, j/k      move the cursor",
+	"  pgup/pgdown       move the cursor by a page",
+	"  home/end, g/G     go to the first or last file",
+	"  o                 switch between personal and organization files",
+	"  f                 filter by tag, esc clears the filter",
+	"  i, enter          preview the complete metadata of the file",
+	"  d                 download the file to the transfer queue",
+	"  x                 delete the file, after confirmation",
+	"  t                 replace the tags of an organization file",
+	"  s                 create a share link of the file",
+	"  r                 refresh the files",
+	"  q, ctrl+c         quit",
+	"",
+	"Press any key to go back.",
+}
+
+// keyHints is the reminder of the main keys at the bottom of the view.
+const keyHints = "o org  f tag  i info  d download  x delete  t tags  s share  r refresh  ? help  q quit"
+
+// View draws the model in a screen of width columns and height lines. Lines
+// are separated by newlines, without trailing newline.
+func (m *Model) View(width, height int) string {
+	width, height = max(width, 1), max(height, 1)
+
+	header := m.organizationName()
+	if m.tagFilter != "" {
+		header += " │ tag: " + m.tagFilter
+	}
+	header += " │ " + pluralize(len(m.visible), "file")
+	lines := []string{"eph ui │ " + header, strings.Repeat("─", width)}
+
+	transferLines := m.viewTransfers(width)
+	bodyHeight := max(height-headerHeight-footerHeight-len(transferLines), 1)
+	lines = append(lines, m.viewBody(width, bodyHeight)...)
+	lines = append(lines, transferLines...)
+	lines = append(lines, m.viewStatus(), keyHints)
+
+	for i, line := range lines {
+		lines[i] = fit(line, width)
+	}
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// viewBody draws the list of files and the preview of the selected file, the
+// organization picker or the help.
+func (m *Model) viewBody(width, height int) []string {
+	var left, right []string
+	switch m.mode {
+	case modeHelp:
+		return pad(help, height)
+	case modeOrgPicker:
+		left = m.viewOrgPicker()
+		right = []string{"Select the files to list", "enter: switch, esc: cancel"}
+	case modeList, modeTagFilter, modeRetag, modeConfirmDelete:
+		left = m.viewFiles(width*listWidthPercent/percent, height)
+		right = m.viewPreview()
+	}
+
+	leftWidth := width * listWidthPercent / percent
+	rightWidth := max(width-leftWidth-utf8.RuneCountInString(paneSeparator), 0)
+	left, right = pad(left, height), pad(right, height)
+	lines := make([]string, height)
+	for i := range lines {
+		lines[i] = fit(left[i], leftWidth) + paneSeparator + fit(right[i], rightWidth)
+	}
+	return lines
+}
+
+// viewFiles draws the page of the list of files holding the cursor. It records
+// the height of the page to move by pages.
+func (m *Model) viewFiles(width, height int) []string {
+	m.listHeight = height
+	if len(m.visible) == 0 {
+		return []string{"  no files"}
+	}
+	m.offset = min(m.offset, m.cursor)
+	if m.cursor >= m.offset+height {
+		m.offset = m.cursor - height + 1
+	}
+
+	nameWidth := max(width-2-sizeWidth-1, 1)
+	var lines []string
+	for i := m.offset; i < min(m.offset+height, len(m.visible)); i++ {
+		f := m.visible[i]
+		marker := "  "
+		if i == m.cursor {
+			marker = "> "
+		}
+		lines = append(lines, marker+fit(f.Name, nameWidth)+" "+fmt.Sprintf("%*s", sizeWidth, units.FormatSize(f.Size)))
+	}
+	return lines
+}
+
+// viewPreview draws the metadata of the selected file, completed by the
+// metadata loaded with the info key.
+func (m *Model) viewPreview() []string {
+	f, ok := m.selected()
+	if !ok {
+		return nil
+	}
+	lines := []string{
+		"Name:      " + f.Name,
+		"ID:        " + f.ID,
+		"Size:      " + units.FormatSize(f.Size) + " (" + strconv.FormatInt(f.Size, 10) + " bytes)",
+		"Expires:   " + formatExpiration(f.ExpiresAt),
+		"Owner:     " + f.Owner,
+	}
+	if f.OrganizationID != "" {
+		lines = append(lines, "Tags:      "+strings.Join(f.Tags, ", "))
+	}
+	if m.info == nil {
+		return append(lines, "", "Press i for the complete metadata")
+	}
+	lines = append(lines, "",
+		"Parts:     "+strconv.Itoa(m.info.NbParts),
+		"Encrypted: "+m.info.EncryptionMode)
+	if !m.info.UploadDate.IsZero() {
+		lines = append(lines, "Uploaded:  "+m.info.UploadDate.Local().Format(dateLayout))
+	}
+	if m.info.Checksum != "" {
+		lines = append(lines, "Checksum:  "+m.info.Checksum)
+	}
+	return lines
+}
+
+// viewOrgPicker draws the personal files and the organizations to pick from.
+func (m *Model) viewOrgPicker() []string {
+	names := []string{"Personal files"}
+	for _, org := range m.orgs {
+		names = append(names, org.Name)
+	}
+	lines := make([]string, 0, len(names))
+	for i, name := range names {
+		marker := "  "
+		if i == m.picker {
+			marker = "> "
+		}
+		lines = append(lines, marker+name)
+	}
+	return lines
+}
+
+// viewTransfers draws the pane of the most recent downloads of the queue.
+func (m *Model) viewTransfers(width int) []string {
+	lines := []string{fit("── Transfers "+strings.Repeat("─", width), width)}
+	transfers := m.transfers[max(len(m.transfers)-transferPaneHeight, 0):]
+	for _, t := range transfers {
+		lines = append(lines, viewTransfer(t))
+	}
+	if len(transfers) == 0 {
+		lines = append(lines, "  no transfers, press d to download the selected file")
+	}
+	return pad(lines, transferPaneHeight+1)
+}
+
+// viewTransfer draws a download of the queue with its progress.
+func viewTransfer(t *transfer) string {
+	ratio := 0.0
+	if t.total > 0 {
+		ratio = min(float64(t.current)/float64(t.total), 1)
+	}
+	filled := int(ratio * progressBarWidth)
+	bar := "[" + strings.Repeat("#", filled) + strings.Repeat("-", progressBarWidth-filled) + "]"
+	state := t.state
+	if t.err != nil {
+		state += ": " + t.err.Error()
+	}
+	return fmt.Sprintf("  %s %3d%% %s / %s  %s  %s", bar, int(ratio*percent),
+		units.FormatSize(t.current), units.FormatSize(t.total), t.file.Name, state)
+}
+
+// viewStatus draws the prompt of the mode, or the status of the last operation.
+func (m *Model) viewStatus() string {
+	switch m.mode {
+	case modeTagFilter:
+		return "Filter by tag (empty for all): " + m.input + "_"
+	case modeRetag:
+		return "Tags (comma-separated): " + m.input + "_"
+	case modeConfirmDelete:
+		if f, ok := m.selected(); ok {
+			return "Delete " + f.Name + "? (y/n)"
+		}
+	case modeList, modeOrgPicker, modeHelp:
+	}
+	return m.status
+}
+
+// formatExpiration formats the expiration date of a file in local time.
+func formatExpiration(t time.Time) string {
+	switch {
+	case t.IsZero():
+		return "-"
+	case t.Before(time.Now()):
+		return t.Local().Format(dateLayout) + " (expired)"
+	default:
+		return t.Local().Format(dateLayout)
+	}
+}
+
+// pluralize returns the count of a noun, e.g. "1 file" or "2 files".
+func pluralize(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return strconv.Itoa(n) + " " + noun + "s"
+}
+
+// fit truncates or pads s with spaces to width columns.
+func fit(s string, width int) string {
+	n := utf8.RuneCountInString(s)
+	switch {
+	case n == width:
+		return s
+	case n < width:
+		return s + strings.Repeat(" ", width-n)
+	case width == 0:
+		return ""
+	default:
+		return string([]rune(s)[:width-1]) + "…"
+	}
+}
+
+// pad truncates or completes lines with empty lines to height lines.
+func pad(lines []string, height int) []string {
+	if len(lines) >= height {
+		return lines[:height]
+	}
+	return append(lines, make([]string, height-len(lines))...)
+}
@@ -0,0 +1,70 @@
+package units
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Day is 24 hours.
+	Day = 24 * time.Hour
+	// Week is 7 days.
+	Week = 7 * Day
+)
+
+// ErrInvalidDuration is returned when a duration cannot be parsed.
+var ErrInvalidDuration = errors.New("invalid duration")
+
+// ParseDuration parses a duration like time.ParseDuration, also accepting the
+// "d" (day) and "w" (week) units: "36h", "7d", "1w2d" and "1d12h" are all valid.
+func ParseDuration(s string) (time.Duration, error) {
+	rest := strings.TrimSpace(s)
+	if rest == "" {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidDuration, s)
+	}
+
+	var total time.Duration
+	for rest != "" {
+		numberEnd := strings.IndexFunc(rest, func(r rune) bool { return !isNumberRune(r) })
+		if numberEnd < 0 {
+			numberEnd = len(rest)
+		}
+		unitEnd := strings.IndexFunc(rest[numberEnd:], isNumberRune)
+		if unitEnd < 0 {
+			unitEnd = len(rest)
+		} else {
+			unitEnd += numberEnd
+		}
+		number, unit := rest[:numberEnd], rest[numberEnd:unitEnd]
+		rest = rest[unitEnd:]
+
+		var multiplier time.Duration
+		switch unit {
+		case "d":
+			multiplier = Day
+		case "w":
+			multiplier = Week
+		default:
+			part, err := time.ParseDuration(number + unit)
+			if err != nil {
+				return 0, fmt.Errorf("%w: %q", ErrInvalidDuration, s)
+			}
+			total += part
+			continue
+		}
+		value, err := strconv.ParseFloat(number, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %q", ErrInvalidDuration, s)
+		}
+		total += time.Duration(value * float64(multiplier))
+	}
+	return total, nil
+}
+
+// isNumberRune reports whether r can be part of the number of a duration.
+func isNumberRune(r rune) bool {
+	return r == '.' || (r >= '0' && r <= '9')
+}
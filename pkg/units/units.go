@@ -0,0 +1,78 @@
+// Package units parses and formats byte sizes such as "5M" or "1.5GB", and
+// durations such as "7d". Size multipliers are binary: 1K is 1024 bytes.
+package units
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	// KB is one kibibyte.
+	KB int64 = 1 << (10 * (iota + 1))
+	// MB is one mebibyte.
+	MB
+	// GB is one gibibyte.
+	GB
+	// TB is one tebibyte.
+	TB
+)
+
+// ErrInvalidSize is returned when a size cannot be parsed.
+var ErrInvalidSize = errors.New("invalid size")
+
+// ParseSize parses a size in bytes with an optional K, M, G or T suffix.
+// The suffix is case insensitive and may be followed by "B" or "iB":
+// "512", "64k", "5M", "5MB", "1.5GiB" are all valid.
+func ParseSize(s string) (int64, error) {
+	value := strings.TrimSpace(s)
+	upper := strings.ToUpper(value)
+	upper = strings.TrimSuffix(upper, "IB")
+	upper = strings.TrimSuffix(upper, "B")
+
+	multiplier := int64(1)
+	if upper != "" {
+		switch upper[len(upper)-1] {
+		case 'K':
+			multiplier = KB
+		case 'M':
+			multiplier = MB
+		case 'G':
+			multiplier = GB
+		case 'T':
+			multiplier = TB
+		}
+		if multiplier != 1 {
+			upper = upper[:len(upper)-1]
+		}
+	}
+
+	number, err := strconv.ParseFloat(strings.TrimSpace(upper), 64)
+	if err != nil || number < 0 || math.IsInf(number, 0) || math.IsNaN(number) {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidSize, s)
+	}
+	size := number * float64(multiplier)
+	if size > math.MaxInt64 {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidSize, s)
+	}
+	return int64(size), nil
+}
+
+// FormatSize formats a size in bytes with the largest suitable unit, e.g. "1.5 MB".
+func FormatSize(size int64) string {
+	units := []struct {
+		suffix string
+		value  int64
+	}{
+		{"TB", TB}, {"GB", GB}, {"MB", MB}, {"KB", KB},
+	}
+	for _, u := range units {
+		if size >= u.value {
+			return fmt.Sprintf("%.1f %s", float64(size)/float64(u.value), u.suffix)
+		}
+	}
+	return fmt.Sprintf("%d B", size)
+}
@@ -0,0 +1,88 @@
+package units_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ephemeralfiles/eph/pkg/units"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSize(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input    string
+		expected int64
+		wantErr  bool
+	}{
+		{input: "512", expected: 512},
+		{input: "64k", expected: 64 * 1024},
+		{input: "5M", expected: 5 * 1024 * 1024},
+		{input: "5MB", expected: 5 * 1024 * 1024},
+		{input: "1.5GiB", expected: 1536 * 1024 * 1024},
+		{input: " 2 g ", expected: 2 * 1024 * 1024 * 1024},
+		{input: "0", expected: 0},
+		{input: "", wantErr: true},
+		{input: "M", wantErr: true},
+		{input: "-1M", wantErr: true},
+		{input: "fast", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+			got, err := units.ParseSize(tt.input)
+			if tt.wantErr {
+				require.ErrorIs(t, err, units.ErrInvalidSize)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "512 B", units.FormatSize(512))
+	assert.Equal(t, "1.0 KB", units.FormatSize(1024))
+	assert.Equal(t, "1.5 MB", units.FormatSize(1536*1024))
+	assert.Equal(t, "2.0 GB", units.FormatSize(2*units.GB))
+}
+
+func TestParseDuration(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input    string
+		expected time.Duration
+		wantErr  bool
+	}{
+		{input: "24h", expected: 24 * time.Hour},
+		{input: "90m", expected: 90 * time.Minute},
+		{input: "7d", expected: 7 * units.Day},
+		{input: "1w2d", expected: 9 * units.Day},
+		{input: "1d12h", expected: 36 * time.Hour},
+		{input: "1.5d", expected: 36 * time.Hour},
+		{input: "", wantErr: true},
+		{input: "7", wantErr: true},
+		{input: "d", wantErr: true},
+		{input: "-1d", wantErr: true},
+		{input: "7days", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+			got, err := units.ParseDuration(tt.input)
+			if tt.wantErr {
+				require.ErrorIs(t, err, units.ErrInvalidDuration)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
@@ -0,0 +1,61 @@
+//go:build linux
+
+package watch
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// inotifyBufferSize is the size of the buffer inotify events are read into.
+const inotifyBufferSize = 4096
+
+// inotifyNotifier wakes the watcher up on inotify events of a directory.
+type inotifyNotifier struct {
+	file   *os.File
+	events chan struct{}
+}
+
+// newNotifier watches dir with inotify for files written, created or moved in.
+func newNotifier(dir string) (notifier, error) {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return nil, fmt.Errorf("error initializing inotify: %w", err)
+	}
+	if _, err := unix.InotifyAddWatch(fd, dir, unix.IN_CLOSE_WRITE|unix.IN_CREATE|unix.IN_MOVED_TO); err != nil {
+		_ = unix.Close(fd)
+		return nil, fmt.Errorf("error watching %s: %w", dir, err)
+	}
+
+	// The non-blocking descriptor is handled by the runtime poller: closing the
+	// file interrupts the pending read
+	n := &inotifyNotifier{file: os.NewFile(uintptr(fd), "inotify"), events: make(chan struct{}, 1)}
+	go n.read()
+	return n, nil
+}
+
+// read turns inotify events into wake-ups, coalescing them, until the file is closed.
+func (n *inotifyNotifier) read() {
+	buf := make([]byte, inotifyBufferSize)
+	for {
+		if _, err := n.file.Read(buf); err != nil {
+			return
+		}
+		select {
+		case n.events <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Events returns the channel receiving a value when the directory changes.
+func (n *inotifyNotifier) Events() <-chan struct{} {
+	return n.events
+}
+
+// Close stops watching the directory.
+func (n *inotifyNotifier) Close() error {
+	return n.file.Close()
+}
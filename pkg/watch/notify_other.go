@@ -0,0 +1,8 @@
+//go:build !linux
+
+package watch
+
+// newNotifier is not available outside Linux: directories are polled.
+func newNotifier(_ string) (notifier, error) {
+	return nil, ErrNotifyUnsupported
+}
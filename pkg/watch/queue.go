@@ -0,0 +1,101 @@
+package watch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+)
+
+const (
+	// queueFilePerm is the permission of the retry queue file.
+	queueFilePerm = 0600
+	// maxRetryDelay caps the delay between two attempts of a failed upload.
+	maxRetryDelay = time.Hour
+)
+
+// QueueEntry is a failed upload waiting for a new attempt.
+type QueueEntry struct {
+	Attempts    int       `json:"attempts"`
+	LastError   string    `json:"last_error"`
+	NextAttempt time.Time `json:"next_attempt"`
+}
+
+// retryQueue holds the failed uploads by file name. It is saved to a file after
+// each change, so that the retries survive restarts.
+type retryQueue struct {
+	path    string
+	entries map[string]QueueEntry
+}
+
+// loadQueue reads the retry queue saved at path. A missing file is an empty queue.
+func loadQueue(path string) (*retryQueue, error) {
+	q := &retryQueue{path: path, entries: map[string]QueueEntry{}}
+	// #nosec G304 -- path is the queue file chosen by the user
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return q, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading retry queue: %w", err)
+	}
+	if err := json.Unmarshal(data, &q.entries); err != nil {
+		return nil, fmt.Errorf("error parsing retry queue %s: %w", path, err)
+	}
+	return q, nil
+}
+
+// due reports whether the file named name can be uploaded at now.
+func (q *retryQueue) due(name string, now time.Time) bool {
+	entry, ok := q.entries[name]
+	return !ok || !now.Before(entry.NextAttempt)
+}
+
+// fail records a failed attempt to upload name, delaying the next one by base,
+// doubled on each failure. It returns the entry.
+func (q *retryQueue) fail(name string, err error, now time.Time, base time.Duration) (QueueEntry, error) {
+	entry := q.entries[name]
+	entry.Attempts++
+	entry.LastError = err.Error()
+	delay := base
+	for i := 1; i < entry.Attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	entry.NextAttempt = now.Add(min(delay, maxRetryDelay))
+	q.entries[name] = entry
+	return entry, q.save()
+}
+
+// remove drops name from the queue.
+func (q *retryQueue) remove(name string) error {
+	if _, ok := q.entries[name]; !ok {
+		return nil
+	}
+	delete(q.entries, name)
+	return q.save()
+}
+
+// save writes the queue to its file, or removes the file when the queue is empty.
+func (q *retryQueue) save() error {
+	if len(q.entries) == 0 {
+		if err := os.Remove(q.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("error removing retry queue: %w", err)
+		}
+		return nil
+	}
+	data, err := json.MarshalIndent(q.entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding retry queue: %w", err)
+	}
+	// Write then rename, so that a crash never leaves a truncated queue
+	tmp := q.path + ".tmp"
+	if err := os.WriteFile(tmp, data, queueFilePerm); err != nil {
+		return fmt.Errorf("error writing retry queue: %w", err)
+	}
+	if err := os.Rename(tmp, q.path); err != nil {
+		return fmt.Errorf("error writing retry queue: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,300 @@
+// Package watch uploads the files dropped into a directory. New files are
+// detected with inotify, or by polling, and uploaded once their size and
+// modification time stop changing. Uploaded files are moved to a done directory
+// or deleted; failed uploads are retried with a backoff recorded in a queue
+// file, so that retries survive restarts.
+package watch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultInterval is the delay between two scans of the directory.
+	DefaultInterval = 2 * time.Second
+	// DefaultStableFor is how long a file must stay unchanged before its upload.
+	DefaultStableFor = 5 * time.Second
+	// DefaultRetryDelay is the delay before the first retry of a failed upload.
+	DefaultRetryDelay = 30 * time.Second
+	// DoneDirName is the default directory uploaded files are moved to, in the
+	// watched directory.
+	DoneDirName = ".done"
+	// QueueFileName is the default file of the retry queue, in the watched directory.
+	QueueFileName = ".eph-watch-queue.json"
+
+	// doneDirPerm is the permission of the done directory.
+	doneDirPerm = 0750
+	// maxDoneAttempts is the number of "name (n).ext" variants tried when moving a file.
+	maxDoneAttempts = 1000
+)
+
+var (
+	// ErrNotifyUnsupported is returned by the notifier when inotify is not available.
+	ErrNotifyUnsupported = errors.New("inotify is not supported on this platform")
+	// ErrNotDirectory is returned when the watched path is not a directory.
+	ErrNotDirectory = errors.New("not a directory")
+	// ErrDoneNameTaken is returned when no free name is found in the done directory.
+	ErrDoneNameTaken = errors.New("no free name in done directory")
+)
+
+// UploadFunc uploads a file and returns the ID of the uploaded file.
+type UploadFunc func(path string) (string, error)
+
+// Options configures a Watcher. Zero durations use the defaults.
+type Options struct {
+	// Dir is the watched directory. Only its regular files are uploaded; hidden
+	// files and subdirectories are ignored.
+	Dir string
+	// Poll disables inotify: the directory is only scanned every Interval.
+	Poll bool
+	// Interval is the delay between two scans of the directory.
+	Interval time.Duration
+	// StableFor is how long the size and modification time of a file must stay
+	// unchanged before its upload.
+	StableFor time.Duration
+	// Delete deletes uploaded files instead of moving them to DoneDir.
+	Delete bool
+	// DoneDir is the directory uploaded files are moved to, Dir/.done by default.
+	DoneDir string
+	// QueueFile stores the failed uploads, Dir/.eph-watch-queue.json by default.
+	QueueFile string
+	// RetryDelay is the delay before the first retry of a failed upload, doubled
+	// on each failure up to an hour.
+	RetryDelay time.Duration
+}
+
+// notifier wakes the watcher up when the directory changes.
+type notifier interface {
+	Events() <-chan struct{}
+	Close() error
+}
+
+// fileState is the last observed state of a file of the directory.
+type fileState struct {
+	size    int64
+	modTime time.Time
+	// since is when the file was first observed in this state.
+	since time.Time
+}
+
+// Watcher uploads the files dropped into a directory.
+type Watcher struct {
+	opts   Options
+	upload UploadFunc
+	log    *slog.Logger
+	queue  *retryQueue
+	// files are the files waiting to be stable, by name.
+	files map[string]fileState
+	// uploaded are the files uploaded but neither moved nor deleted, by name:
+	// they are not uploaded again unless they change.
+	uploaded map[string]fileState
+}
+
+// New returns a watcher of opts.Dir uploading files with upload. The retry
+// queue of a previous run is loaded.
+func New(opts Options, upload UploadFunc, log *slog.Logger) (*Watcher, error) {
+	info, err := os.Stat(opts.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%w: %s", ErrNotDirectory, opts.Dir)
+	}
+
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultInterval
+	}
+	if opts.StableFor <= 0 {
+		opts.StableFor = DefaultStableFor
+	}
+	if opts.RetryDelay <= 0 {
+		opts.RetryDelay = DefaultRetryDelay
+	}
+	if opts.DoneDir == "" {
+		opts.DoneDir = filepath.Join(opts.Dir, DoneDirName)
+	}
+	if opts.QueueFile == "" {
+		opts.QueueFile = filepath.Join(opts.Dir, QueueFileName)
+	}
+
+	queue, err := loadQueue(opts.QueueFile)
+	if err != nil {
+		return nil, err
+	}
+	return &Watcher{
+		opts:     opts,
+		upload:   upload,
+		log:      log,
+		queue:    queue,
+		files:    map[string]fileState{},
+		uploaded: map[string]fileState{},
+	}, nil
+}
+
+// Run watches the directory until ctx is done. Uploads run one at a time.
+func (w *Watcher) Run(ctx context.Context) error {
+	var events <-chan struct{}
+	mode := "poll"
+	if !w.opts.Poll {
+		n, err := newNotifier(w.opts.Dir)
+		if err != nil {
+			w.log.Warn("inotify unavailable, polling the directory", slog.String("error", err.Error()))
+		} else {
+			defer func() {
+				_ = n.Close()
+			}()
+			events = n.Events()
+			mode = "inotify"
+		}
+	}
+	w.log.Info("watching directory", slog.String("dir", w.opts.Dir), slog.String("mode", mode),
+		slog.Int("pending_retries", len(w.queue.entries)))
+
+	// Files are only uploaded once stable: the directory is scanned on every
+	// tick, inotify events only trigger earlier scans
+	ticker := time.NewTicker(min(w.opts.Interval, w.opts.StableFor))
+	defer ticker.Stop()
+	for {
+		if err := w.scan(ctx, time.Now()); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			w.log.Info("watch stopped")
+			return nil
+		case <-ticker.C:
+		case <-events:
+		}
+	}
+}
+
+// scan uploads the stable files of the directory.
+func (w *Watcher) scan(ctx context.Context, now time.Time) error {
+	entries, err := os.ReadDir(w.opts.Dir)
+	if err != nil {
+		return fmt.Errorf("error reading directory: %w", err)
+	}
+
+	present := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.Type().IsRegular() || strings.HasPrefix(name, ".") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			// Removed since the directory was read
+			continue
+		}
+		present[name] = true
+
+		state := fileState{size: info.Size(), modTime: info.ModTime(), since: now}
+		if previous, ok := w.files[name]; ok && previous.size == state.size && previous.modTime.Equal(state.modTime) {
+			state.since = previous.since
+		}
+		w.files[name] = state
+		if done, ok := w.uploaded[name]; ok && done.size == state.size && done.modTime.Equal(state.modTime) {
+			continue
+		}
+		if now.Sub(state.since) < w.opts.StableFor || !w.queue.due(name, now) {
+			continue
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+		w.process(name, state, now)
+	}
+
+	// Forget the files that disappeared, and their retries
+	for name := range w.files {
+		if !present[name] {
+			delete(w.files, name)
+			delete(w.uploaded, name)
+		}
+	}
+	for name := range w.queue.entries {
+		if present[name] {
+			continue
+		}
+		w.log.Info("file removed, retry dropped", slog.String("file", name))
+		if err := w.queue.remove(name); err != nil {
+			w.log.Error("cannot update retry queue", slog.String("error", err.Error()))
+		}
+	}
+	return nil
+}
+
+// process uploads a stable file, then moves or deletes it.
+func (w *Watcher) process(name string, state fileState, now time.Time) {
+	path := filepath.Join(w.opts.Dir, name)
+	fileID, err := w.upload(path)
+	if err != nil {
+		entry, queueErr := w.queue.fail(name, err, now, w.opts.RetryDelay)
+		w.log.Warn("upload failed", slog.String("file", name), slog.Int("attempt", entry.Attempts),
+			slog.Time("next_attempt", entry.NextAttempt), slog.String("error", err.Error()))
+		if queueErr != nil {
+			w.log.Error("cannot update retry queue", slog.String("error", queueErr.Error()))
+		}
+		return
+	}
+	if err := w.queue.remove(name); err != nil {
+		w.log.Error("cannot update retry queue", slog.String("error", err.Error()))
+	}
+
+	target, err := w.finish(path)
+	if err != nil {
+		w.uploaded[name] = state
+		w.log.Error("file uploaded but not moved", slog.String("file", name), slog.String("file_id", fileID),
+			slog.String("error", err.Error()))
+		return
+	}
+	delete(w.files, name)
+	w.log.Info("file uploaded", slog.String("file", name), slog.String("file_id", fileID),
+		slog.String("moved_to", target))
+}
+
+// finish deletes an uploaded file, or moves it to the done directory and
+// returns its new path.
+func (w *Watcher) finish(path string) (string, error) {
+	if w.opts.Delete {
+		if err := os.Remove(path); err != nil {
+			return "", fmt.Errorf("error removing file: %w", err)
+		}
+		return "", nil
+	}
+	if err := os.MkdirAll(w.opts.DoneDir, doneDirPerm); err != nil {
+		return "", fmt.Errorf("error creating done directory: %w", err)
+	}
+	target, err := freeName(filepath.Join(w.opts.DoneDir, filepath.Base(path)))
+	if err != nil {
+		return "", err
+	}
+	if err := os.Rename(path, target); err != nil {
+		return "", fmt.Errorf("error moving file: %w", err)
+	}
+	return target, nil
+}
+
+// freeName returns target, or its first "name (n).ext" variant, that does not exist.
+func freeName(target string) (string, error) {
+	if _, err := os.Lstat(target); errors.Is(err, fs.ErrNotExist) {
+		return target, nil
+	}
+	ext := filepath.Ext(target)
+	base := strings.TrimSuffix(target, ext)
+	for i := 1; i <= maxDoneAttempts; i++ {
+		candidate := fmt.Sprintf("%s (%d)%s", base, i, ext)
+		if _, err := os.Lstat(candidate); errors.Is(err, fs.ErrNotExist) {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrDoneNameTaken, target)
+}
@@ -0,0 +1,171 @@
+package watch_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ephemeralfiles/eph/pkg/logger"
+	"github.com/ephemeralfiles/eph/pkg/watch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errUploadFailed = errors.New("upload failed")
+
+// recorder is an UploadFunc recording the uploaded files, failing while fail is set.
+type recorder struct {
+	mu       sync.Mutex
+	fail     bool
+	attempts int
+	uploaded []string
+}
+
+func (r *recorder) upload(path string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.attempts++
+	if r.fail {
+		return "", errUploadFailed
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	r.uploaded = append(r.uploaded, filepath.Base(path)+"="+string(content))
+	return "id-" + filepath.Base(path), nil
+}
+
+func (r *recorder) state() (int, []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.attempts, append([]string(nil), r.uploaded...)
+}
+
+// start runs a watcher of opts, scanning every 10ms, and returns the function
+// stopping it. It is also stopped when the test ends.
+func start(t *testing.T, opts watch.Options, upload watch.UploadFunc) func() {
+	t.Helper()
+	opts.Interval = 10 * time.Millisecond
+	opts.StableFor = 30 * time.Millisecond
+	w, err := watch.New(opts, upload, logger.NoLogger())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- w.Run(ctx)
+	}()
+	var once sync.Once
+	stop := func() {
+		once.Do(func() {
+			cancel()
+			require.NoError(t, <-done)
+		})
+	}
+	t.Cleanup(stop)
+	return stop
+}
+
+func TestWatch(t *testing.T) {
+	t.Parallel()
+
+	for _, poll := range []bool{false, true} {
+		t.Run(map[bool]string{false: "inotify", true: "poll"}[poll], func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, ".hidden"), []byte("x"), 0600))
+			require.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0750))
+			rec := &recorder{}
+			start(t, watch.Options{Dir: dir, Poll: poll}, rec.upload)
+
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "scan.pdf"), []byte("first"), 0600))
+			assert.Eventually(t, func() bool {
+				_, uploaded := rec.state()
+				return len(uploaded) == 1
+			}, 5*time.Second, 10*time.Millisecond)
+			assert.Eventually(t, func() bool {
+				_, err := os.Stat(filepath.Join(dir, ".done", "scan.pdf"))
+				return err == nil
+			}, 5*time.Second, 10*time.Millisecond)
+			assert.NoFileExists(t, filepath.Join(dir, "scan.pdf"))
+
+			// A second file of the same name does not replace the first one
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "scan.pdf"), []byte("second"), 0600))
+			assert.Eventually(t, func() bool {
+				_, err := os.Stat(filepath.Join(dir, ".done", "scan (1).pdf"))
+				return err == nil
+			}, 5*time.Second, 10*time.Millisecond)
+			_, uploaded := rec.state()
+			assert.Equal(t, []string{"scan.pdf=first", "scan.pdf=second"}, uploaded)
+			assert.FileExists(t, filepath.Join(dir, ".hidden"))
+		})
+	}
+}
+
+func TestWatchDelete(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	rec := &recorder{}
+	start(t, watch.Options{Dir: dir, Poll: true, Delete: true}, rec.upload)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "scan.pdf"), []byte("content"), 0600))
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(filepath.Join(dir, "scan.pdf"))
+		return errors.Is(err, os.ErrNotExist)
+	}, 5*time.Second, 10*time.Millisecond)
+	_, uploaded := rec.state()
+	assert.Equal(t, []string{"scan.pdf=content"}, uploaded)
+	assert.NoDirExists(t, filepath.Join(dir, ".done"))
+}
+
+func TestWatchRetryQueue(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	queueFile := filepath.Join(dir, watch.QueueFileName)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "scan.pdf"), []byte("content"), 0600))
+
+	// The first watcher fails and records the file in the queue
+	rec := &recorder{fail: true}
+	opts := watch.Options{Dir: dir, Poll: true, RetryDelay: time.Hour}
+	stop := start(t, opts, rec.upload)
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(queueFile)
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	stop()
+	attempts, _ := rec.state()
+	assert.Equal(t, 1, attempts, "no retry before the retry delay")
+	queue, err := os.ReadFile(queueFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(queue), errUploadFailed.Error())
+
+	// After a restart, the retry still waits for the recorded delay
+	rec = &recorder{}
+	stop = start(t, opts, rec.upload)
+	time.Sleep(100 * time.Millisecond)
+	stop()
+	attempts, _ = rec.state()
+	assert.Zero(t, attempts)
+
+	// Once the delay is over, the file is uploaded and the queue removed
+	require.NoError(t, os.WriteFile(queueFile,
+		[]byte(`{"scan.pdf": {"attempts": 1, "next_attempt": "2020-01-01T00:00:00Z"}}`), 0600))
+	start(t, opts, rec.upload)
+	assert.Eventually(t, func() bool {
+		_, uploaded := rec.state()
+		return len(uploaded) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(queueFile)
+		return errors.Is(err, os.ErrNotExist)
+	}, 5*time.Second, 10*time.Millisecond)
+}